@@ -2,19 +2,17 @@ package alloha
 
 import (
 	"bytes"
-	"compress/flate"
-	"compress/gzip"
 	"context"
-	"encoding/json"
-	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"net/url"
 	"strconv"
-	"strings"
+	"time"
 )
 
+// DefaultMaxResponseSize is the default maximum size of the API response body in bytes
+const DefaultMaxResponseSize int64 = 64 << 20
+
 // HttpClient provides an interface for executing HTTP requests
 type HttpClient interface {
 	Do(req *http.Request) (*http.Response, error)
@@ -22,9 +20,18 @@ type HttpClient interface {
 
 // APIClient is the structure of the client API
 type APIClient struct {
-	apiToken string
-	baseURL  string
-	client   HttpClient
+	apiToken        string
+	baseURL         string
+	client          HttpClient
+	decodeMode      DecodeMode
+	keepRaw         bool
+	maxResponseSize int64
+	contentDecoders []contentDecoderEntry
+	middlewares     []Middleware
+	logger          Logger
+	logLevel        LogLevel
+	metrics         MetricsRecorder
+	tracer          Tracer
 }
 
 //region - Constructor
@@ -37,9 +44,15 @@ func NewAPIClient(httpClient HttpClient, apiToken, baseApiURL string) (*APIClien
 	}
 
 	client := &APIClient{
-		apiToken: apiToken,
-		baseURL:  buildURL,
-		client:   httpClient,
+		apiToken:        apiToken,
+		baseURL:         buildURL,
+		client:          httpClient,
+		maxResponseSize: DefaultMaxResponseSize,
+		contentDecoders: defaultContentDecoders(),
+		logger:          NopLogger{},
+		logLevel:        LogLevelDebug,
+		metrics:         NopMetricsRecorder{},
+		tracer:          NopTracer{},
 	}
 
 	return client, nil
@@ -56,10 +69,7 @@ func (c *APIClient) FindByIMDbId(ctx context.Context, tmdbId string) (*FindOneRe
 	}
 
 	var err error
-	var bodyBytes []byte
 	var parsedBaseURL *url.URL
-	var statusCode int
-	var response *FindOneResponse
 
 	parsedBaseURL, err = url.Parse(c.baseURL)
 	if err != nil {
@@ -70,16 +80,8 @@ func (c *APIClient) FindByIMDbId(ctx context.Context, tmdbId string) (*FindOneRe
 	queryValues.Set("imdb", tmdbId)
 	parsedBaseURL.RawQuery = queryValues.Encode()
 
-	bodyBytes, statusCode, err = c.doApiRequest(ctx, http.MethodGet, parsedBaseURL.String(), nil)
-	if err != nil {
-		return nil, err
-	}
-
-	if statusCode != 200 {
-		return nil, fmt.Errorf("unexpected server response with a status code: %d", statusCode)
-	}
-
-	err = json.Unmarshal(bodyBytes, &response)
+	response := &FindOneResponse{}
+	err = c.fetchResponse(ctx, OperationFindByIMDbId, parsedBaseURL.String(), response)
 	if err != nil {
 		return nil, err
 	}
@@ -94,10 +96,7 @@ func (c *APIClient) FindByKPId(ctx context.Context, kpId int) (*FindOneResponse,
 	}
 
 	var err error
-	var bodyBytes []byte
 	var parsedBaseURL *url.URL
-	var statusCode int
-	var response *FindOneResponse
 
 	parsedBaseURL, err = url.Parse(c.baseURL)
 	if err != nil {
@@ -108,16 +107,8 @@ func (c *APIClient) FindByKPId(ctx context.Context, kpId int) (*FindOneResponse,
 	queryValues.Set("kp", strconv.Itoa(kpId))
 	parsedBaseURL.RawQuery = queryValues.Encode()
 
-	bodyBytes, statusCode, err = c.doApiRequest(ctx, http.MethodGet, parsedBaseURL.String(), nil)
-	if err != nil {
-		return nil, err
-	}
-
-	if statusCode != 200 {
-		return nil, fmt.Errorf("unexpected server response with a status code: %d", statusCode)
-	}
-
-	err = json.Unmarshal(bodyBytes, &response)
+	response := &FindOneResponse{}
+	err = c.fetchResponse(ctx, OperationFindByKPId, parsedBaseURL.String(), response)
 	if err != nil {
 		return nil, err
 	}
@@ -132,10 +123,7 @@ func (c *APIClient) FindByTMDbId(ctx context.Context, tmdbId int) (*FindOneRespo
 	}
 
 	var err error
-	var bodyBytes []byte
 	var parsedBaseURL *url.URL
-	var statusCode int
-	var response *FindOneResponse
 
 	parsedBaseURL, err = url.Parse(c.baseURL)
 	if err != nil {
@@ -146,16 +134,8 @@ func (c *APIClient) FindByTMDbId(ctx context.Context, tmdbId int) (*FindOneRespo
 	queryValues.Set("tmdb", strconv.Itoa(tmdbId))
 	parsedBaseURL.RawQuery = queryValues.Encode()
 
-	bodyBytes, statusCode, err = c.doApiRequest(ctx, http.MethodGet, parsedBaseURL.String(), nil)
-	if err != nil {
-		return nil, err
-	}
-
-	if statusCode != 200 {
-		return nil, fmt.Errorf("unexpected server response with a status code: %d", statusCode)
-	}
-
-	err = json.Unmarshal(bodyBytes, &response)
+	response := &FindOneResponse{}
+	err = c.fetchResponse(ctx, OperationFindByTMDbId, parsedBaseURL.String(), response)
 	if err != nil {
 		return nil, err
 	}
@@ -170,10 +150,7 @@ func (c *APIClient) GetListOfLatestSeries(ctx context.Context, pageNum int) (*Li
 	}
 
 	var err error
-	var bodyBytes []byte
 	var parsedBaseURL *url.URL
-	var statusCode int
-	var response *ListOfLatestSeriesResponse
 
 	parsedBaseURL, err = url.Parse(c.baseURL)
 	if err != nil {
@@ -186,16 +163,8 @@ func (c *APIClient) GetListOfLatestSeries(ctx context.Context, pageNum int) (*Li
 	queryValues.Set("page", strconv.Itoa(pageNum))
 	parsedBaseURL.RawQuery = queryValues.Encode()
 
-	bodyBytes, statusCode, err = c.doApiRequest(ctx, http.MethodGet, parsedBaseURL.String(), nil)
-	if err != nil {
-		return nil, err
-	}
-
-	if statusCode != 200 {
-		return nil, fmt.Errorf("unexpected server response with a status code: %d", statusCode)
-	}
-
-	err = json.Unmarshal(bodyBytes, &response)
+	response := &ListOfLatestSeriesResponse{}
+	err = c.fetchResponse(ctx, OperationGetListOfLatestSeries, parsedBaseURL.String(), response)
 	if err != nil {
 		return nil, err
 	}
@@ -210,10 +179,7 @@ func (c *APIClient) SearchForOneByName(ctx context.Context, movieName string) (*
 	}
 
 	var err error
-	var bodyBytes []byte
 	var parsedBaseURL *url.URL
-	var statusCode int
-	var response *FindOneResponse
 
 	parsedBaseURL, err = url.Parse(c.baseURL)
 	if err != nil {
@@ -224,16 +190,8 @@ func (c *APIClient) SearchForOneByName(ctx context.Context, movieName string) (*
 	queryValues.Set("name", movieName)
 	parsedBaseURL.RawQuery = queryValues.Encode()
 
-	bodyBytes, statusCode, err = c.doApiRequest(ctx, http.MethodGet, parsedBaseURL.String(), nil)
-	if err != nil {
-		return nil, err
-	}
-
-	if statusCode != 200 {
-		return nil, fmt.Errorf("unexpected server response with a status code: %d", statusCode)
-	}
-
-	err = json.Unmarshal(bodyBytes, &response)
+	response := &FindOneResponse{}
+	err = c.fetchResponse(ctx, OperationSearchForOneByName, parsedBaseURL.String(), response)
 	if err != nil {
 		return nil, err
 	}
@@ -248,10 +206,7 @@ func (c *APIClient) SearchListByName(ctx context.Context, movieName string) (*Fi
 	}
 
 	var err error
-	var bodyBytes []byte
 	var parsedBaseURL *url.URL
-	var statusCode int
-	var response *FindListResponse
 
 	parsedBaseURL, err = url.Parse(c.baseURL)
 	if err != nil {
@@ -263,16 +218,8 @@ func (c *APIClient) SearchListByName(ctx context.Context, movieName string) (*Fi
 	queryValues.Set("list", "1")
 	parsedBaseURL.RawQuery = queryValues.Encode()
 
-	bodyBytes, statusCode, err = c.doApiRequest(ctx, http.MethodGet, parsedBaseURL.String(), nil)
-	if err != nil {
-		return nil, err
-	}
-
-	if statusCode != 200 {
-		return nil, fmt.Errorf("unexpected server response with a status code: %d", statusCode)
-	}
-
-	err = json.Unmarshal(bodyBytes, &response)
+	response := &FindListResponse{}
+	err = c.fetchResponse(ctx, OperationSearchListByName, parsedBaseURL.String(), response)
 	if err != nil {
 		return nil, err
 	}
@@ -305,6 +252,35 @@ func (c *APIClient) SetBaseApiUrl(baseApiURL string) error {
 	return nil
 }
 
+// SetDecodeMode sets the mode of checking the API responses against the SDK types
+func (c *APIClient) SetDecodeMode(mode DecodeMode) error {
+	if mode < DecodeModeDefault || mode > DecodeModeStrictError {
+		return InvalidDecodeModeParameterError
+	}
+
+	c.decodeMode = mode
+
+	return nil
+}
+
+// SetKeepRawResponse enables keeping the original JSON body of the responses, which is returned by the Raw method
+// of the response. The body is always kept in the strict decode modes.
+func (c *APIClient) SetKeepRawResponse(keep bool) {
+	c.keepRaw = keep
+}
+
+// SetMaxResponseSize sets the maximum size of the API response body in bytes, which is enforced on both
+// the compressed and the decompressed response streams
+func (c *APIClient) SetMaxResponseSize(maxResponseSize int64) error {
+	if maxResponseSize <= 0 {
+		return InvalidMaxResponseSizeParameterError
+	}
+
+	c.maxResponseSize = maxResponseSize
+
+	return nil
+}
+
 //endregion
 
 //region - Private Methods
@@ -340,12 +316,69 @@ func buildApiURL(apiToken, baseApiUrl string) (string, error) {
 
 // doApiRequest executes the specified HTTP request to the specified URL with the specified request body and returns
 // the response body, the response code, and the error, if any.
-func (c *APIClient) doApiRequest(ctx context.Context, method, endpointApiUrl string, requestBody []byte) ([]byte, int, error) {
-	var bodyBytes []byte
+func (c *APIClient) doApiRequest(ctx context.Context, method, endpointApiUrl string, requestBody []byte) (bodyBytes []byte, statusCode int, err error) {
+	var respReader io.ReadCloser
+
+	ctx, request := c.startApiRequest(ctx, "", endpointApiUrl)
+	defer func() { c.finishApiRequest(ctx, request, err) }()
+
+	respReader, statusCode, err = c.openApiResponse(ctx, request, method, endpointApiUrl, requestBody)
+	if err != nil {
+		return nil, statusCode, err
+	}
+	defer c.closeResponseReader(ctx, respReader)
+
+	bodyBytes, err = io.ReadAll(respReader)
+	if err != nil {
+		return nil, statusCode, err
+	}
+
+	if len(bodyBytes) <= 0 {
+		return nil, statusCode, &EmptyResponseBodyError{StatusCode: statusCode}
+	}
+
+	return bodyBytes, statusCode, nil
+}
+
+// fetchResponse executes the GET request of the specified operation to the specified URL and decodes the JSON
+// response body directly from the response stream into the response structure.
+func (c *APIClient) fetchResponse(ctx context.Context, operation, endpointApiUrl string, response decodableResponse) (err error) {
+	var respReader io.ReadCloser
+	var statusCode int
+
+	ctx, request := c.startApiRequest(ctx, operation, endpointApiUrl)
+	defer func() { c.finishApiRequest(ctx, request, err) }()
+
+	respReader, statusCode, err = c.openApiResponse(ctx, request, http.MethodGet, endpointApiUrl, nil)
+	if err != nil {
+		return err
+	}
+	defer c.closeResponseReader(ctx, respReader)
+
+	if statusCode != 200 {
+		return &UnexpectedStatusCodeError{StatusCode: statusCode}
+	}
+
+	err = c.decodeResponse(respReader, response)
+	if err == io.EOF {
+		return &EmptyResponseBodyError{StatusCode: statusCode}
+	}
+	if err != nil {
+		return err
+	}
+	request.apiStatus = response.responseStatus()
+
+	return nil
+}
+
+// openApiResponse executes the specified HTTP request to the specified URL with the specified request body through
+// the middleware chain and returns the decoded response body stream limited by the maximum response size,
+// the response code, and the error, if any. The returned stream must be closed by the caller.
+func (c *APIClient) openApiResponse(ctx context.Context, request *apiRequest, method, endpointApiUrl string, requestBody []byte) (io.ReadCloser, int, error) {
 	var err error
 	var req *http.Request
 	var resp *http.Response
-	var respReader io.ReadCloser
+	var respReader *responseBody
 	var statusCode int
 
 	if len(method) <= 0 {
@@ -371,47 +404,107 @@ func (c *APIClient) doApiRequest(ctx context.Context, method, endpointApiUrl str
 	}
 
 	req.Header.Add("Accept", "application/json")
-	req.Header.Add("Accept-Encoding", "gzip, deflate")
+	req.Header.Add("Accept-Encoding", c.acceptEncoding())
 	req.Header.Add("Accept-Language", "ru-RU,ru;q=0.9,en-US;q=0.8,en;q=0.7")
 	req.Header.Add("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/89.0.4389.90 Safari/537.36")
 
-	resp, err = c.client.Do(req)
+	c.tracer.Inject(ctx, req.Header)
+	request.call = newCall(request.operation, req)
+
+	resp, err = c.handler().Do(request.call)
 	if err != nil {
+		request.transportErr = true
 		return nil, statusCode, err
 	}
+	if resp == nil {
+		return nil, statusCode, EmptyHttpResponseError
+	}
 
 	statusCode = resp.StatusCode
+	request.statusCode = statusCode
 
-	// Check if the response is encoded in deflate or gzip format
-	respContentEncoding := strings.ToLower(resp.Header.Get("Content-Encoding"))
-	switch respContentEncoding {
-	case "deflate":
-		respReader = flate.NewReader(resp.Body)
-	case "gzip":
-		respReader, err = gzip.NewReader(resp.Body)
-		if err != nil {
-			return nil, statusCode, err
-		}
-	default:
-		respReader = resp.Body
+	respReader, err = c.decodeContent(ctx, resp)
+	if err != nil {
+		return nil, statusCode, err
 	}
+	request.body = respReader
 
-	defer func() {
-		if closeErr := respReader.Close(); closeErr != nil {
-			log.Printf("failed to close response reader: %s", closeErr.Error())
-		}
-	}()
+	return respReader, statusCode, nil
+}
 
-	bodyBytes, err = io.ReadAll(respReader)
+// startApiRequest creates the state of the API request of the specified operation and starts its span. The returned
+// context carries the span and must be used for the request.
+func (c *APIClient) startApiRequest(ctx context.Context, operation, endpointApiUrl string) (context.Context, *apiRequest) {
+	request := &apiRequest{
+		operation: operation,
+		started:   time.Now(),
+	}
+
+	parsedURL, err := url.Parse(endpointApiUrl)
 	if err != nil {
-		return nil, statusCode, err
+		parsedURL = nil
 	}
+	request.url = redactURL(parsedURL)
 
-	if len(bodyBytes) <= 0 {
-		return nil, statusCode, &EmptyResponseBodyError{StatusCode: statusCode}
+	if len(operation) > 0 {
+		ctx = context.WithValue(ctx, operationContextKey{}, operation)
 	}
 
-	return bodyBytes, statusCode, nil
+	return c.startSpan(ctx, request, parsedURL), request
+}
+
+// finishApiRequest reports the result of the API request to the logger, the metrics recorder and the tracer
+func (c *APIClient) finishApiRequest(ctx context.Context, request *apiRequest, err error) {
+	c.logRequest(ctx, request, err)
+	c.recordRequest(request, err)
+	c.endSpan(request, err)
+}
+
+// closeResponseReader closes the response reader and logs the error, if any
+func (c *APIClient) closeResponseReader(ctx context.Context, respReader io.Closer) {
+	if closeErr := respReader.Close(); closeErr != nil {
+		c.log(ctx, LogLevelWarn, "failed to close response reader", "error", closeErr.Error())
+	}
+}
+
+// apiRequest holds the state of a single API request shared by the request pipeline and the observers
+type apiRequest struct {
+	operation    string
+	url          string
+	started      time.Time
+	call         *Call
+	statusCode   int
+	transportErr bool
+	apiStatus    string
+	body         *responseBody
+	span         Span
+}
+
+// attempts returns the number of attempts made to execute the request
+func (r *apiRequest) attempts() int {
+	if r.call == nil {
+		return 0
+	}
+
+	return r.call.Attempt
+}
+
+// bytes returns the number of decoded response body bytes read so far
+func (r *apiRequest) bytes() int64 {
+	if r.body == nil {
+		return 0
+	}
+
+	return r.body.decodedBytes()
+}
+
+// wireBytes returns the number of response body bytes received over the network so far
+func (r *apiRequest) wireBytes() int64 {
+	if r.body == nil {
+		return 0
+	}
+
+	return r.body.wire.read
 }
 
 //endregion
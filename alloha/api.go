@@ -0,0 +1,327 @@
+package alloha
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/url"
+	"time"
+)
+
+// API provides an interface for the Alloha API methods. It is implemented by APIClient and by the decorators, so that
+// the client can be substituted in tests and the decorators can be composed, e.g.
+// NewRetryAPI(NewMetricsAPI(NewCachingAPI(client, cache, time.Hour), recorder), 3, time.Second).
+type API interface {
+	FindByIMDbId(ctx context.Context, imdbId string) (*FindOneResponse, error)
+	FindByKPId(ctx context.Context, kpId int) (*FindOneResponse, error)
+	FindByTMDbId(ctx context.Context, tmdbId int) (*FindOneResponse, error)
+	GetListOfLatestSeries(ctx context.Context, pageNum int) (*ListOfLatestSeriesResponse, error)
+	SearchForOneByName(ctx context.Context, movieName string) (*FindOneResponse, error)
+	SearchListByName(ctx context.Context, movieName string) (*FindListResponse, error)
+	StreamListByName(ctx context.Context, movieName string, fn func(movie *MovieSearchData) error) (*ListPageInfo, error)
+	StreamListOfLatestSeries(ctx context.Context, pageNum int, fn func(series *SeriesData) error) (*ListPageInfo, error)
+}
+
+var _ API = (*APIClient)(nil)
+
+//region - Metrics Decorator
+
+// MetricsAPI is an API decorator that reports every call to the MetricsRecorder. The response size is not known at
+// this level and is reported as zero.
+type MetricsAPI struct {
+	api     API
+	metrics MetricsRecorder
+}
+
+var _ API = (*MetricsAPI)(nil)
+
+// NewMetricsAPI creates a new MetricsAPI instance. Passing a nil recorder disables the metrics.
+func NewMetricsAPI(api API, recorder MetricsRecorder) *MetricsAPI {
+	if recorder == nil {
+		recorder = NopMetricsRecorder{}
+	}
+
+	return &MetricsAPI{api: api, metrics: recorder}
+}
+
+// FindByIMDbId implements the API interface
+func (a *MetricsAPI) FindByIMDbId(ctx context.Context, imdbId string) (*FindOneResponse, error) {
+	started := time.Now()
+	response, err := a.api.FindByIMDbId(ctx, imdbId)
+	a.observe(OperationFindByIMDbId, started, findOneStatus(response), err)
+
+	return response, err
+}
+
+// FindByKPId implements the API interface
+func (a *MetricsAPI) FindByKPId(ctx context.Context, kpId int) (*FindOneResponse, error) {
+	started := time.Now()
+	response, err := a.api.FindByKPId(ctx, kpId)
+	a.observe(OperationFindByKPId, started, findOneStatus(response), err)
+
+	return response, err
+}
+
+// FindByTMDbId implements the API interface
+func (a *MetricsAPI) FindByTMDbId(ctx context.Context, tmdbId int) (*FindOneResponse, error) {
+	started := time.Now()
+	response, err := a.api.FindByTMDbId(ctx, tmdbId)
+	a.observe(OperationFindByTMDbId, started, findOneStatus(response), err)
+
+	return response, err
+}
+
+// GetListOfLatestSeries implements the API interface
+func (a *MetricsAPI) GetListOfLatestSeries(ctx context.Context, pageNum int) (*ListOfLatestSeriesResponse, error) {
+	started := time.Now()
+	response, err := a.api.GetListOfLatestSeries(ctx, pageNum)
+
+	status := ""
+	if response != nil {
+		status = response.Status
+	}
+	a.observe(OperationGetListOfLatestSeries, started, status, err)
+
+	return response, err
+}
+
+// SearchForOneByName implements the API interface
+func (a *MetricsAPI) SearchForOneByName(ctx context.Context, movieName string) (*FindOneResponse, error) {
+	started := time.Now()
+	response, err := a.api.SearchForOneByName(ctx, movieName)
+	a.observe(OperationSearchForOneByName, started, findOneStatus(response), err)
+
+	return response, err
+}
+
+// SearchListByName implements the API interface
+func (a *MetricsAPI) SearchListByName(ctx context.Context, movieName string) (*FindListResponse, error) {
+	started := time.Now()
+	response, err := a.api.SearchListByName(ctx, movieName)
+
+	status := ""
+	if response != nil {
+		status = response.Status
+	}
+	a.observe(OperationSearchListByName, started, status, err)
+
+	return response, err
+}
+
+// StreamListByName implements the API interface
+func (a *MetricsAPI) StreamListByName(ctx context.Context, movieName string, fn func(movie *MovieSearchData) error) (*ListPageInfo, error) {
+	started := time.Now()
+	info, err := a.api.StreamListByName(ctx, movieName, fn)
+	a.observe(OperationStreamListByName, started, pageInfoStatus(info), err)
+
+	return info, err
+}
+
+// StreamListOfLatestSeries implements the API interface
+func (a *MetricsAPI) StreamListOfLatestSeries(ctx context.Context, pageNum int, fn func(series *SeriesData) error) (*ListPageInfo, error) {
+	started := time.Now()
+	info, err := a.api.StreamListOfLatestSeries(ctx, pageNum, fn)
+	a.observe(OperationStreamListOfLatestSeries, started, pageInfoStatus(info), err)
+
+	return info, err
+}
+
+// observe reports the call to the metrics recorder
+func (a *MetricsAPI) observe(operation string, started time.Time, status string, err error) {
+	a.metrics.ObserveRequest(operation, errorOutcome(status, err), time.Since(started), 0)
+}
+
+//endregion
+
+//region - Retry Decorator
+
+// RetryAPI is an API decorator that repeats the whole call on transport errors and on 429 and 5xx responses up to
+// maxAttempts times in total. The delay between the attempts starts from the backoff value and doubles with every
+// attempt. The stream calls are repeated only until the first item is passed to the callback.
+type RetryAPI struct {
+	api         API
+	maxAttempts int
+	backoff     time.Duration
+}
+
+var _ API = (*RetryAPI)(nil)
+
+// NewRetryAPI creates a new RetryAPI instance
+func NewRetryAPI(api API, maxAttempts int, backoff time.Duration) *RetryAPI {
+	return &RetryAPI{api: api, maxAttempts: maxAttempts, backoff: backoff}
+}
+
+// FindByIMDbId implements the API interface
+func (a *RetryAPI) FindByIMDbId(ctx context.Context, imdbId string) (response *FindOneResponse, err error) {
+	err = a.retry(ctx, func() error {
+		response, err = a.api.FindByIMDbId(ctx, imdbId)
+		return err
+	})
+
+	return response, err
+}
+
+// FindByKPId implements the API interface
+func (a *RetryAPI) FindByKPId(ctx context.Context, kpId int) (response *FindOneResponse, err error) {
+	err = a.retry(ctx, func() error {
+		response, err = a.api.FindByKPId(ctx, kpId)
+		return err
+	})
+
+	return response, err
+}
+
+// FindByTMDbId implements the API interface
+func (a *RetryAPI) FindByTMDbId(ctx context.Context, tmdbId int) (response *FindOneResponse, err error) {
+	err = a.retry(ctx, func() error {
+		response, err = a.api.FindByTMDbId(ctx, tmdbId)
+		return err
+	})
+
+	return response, err
+}
+
+// GetListOfLatestSeries implements the API interface
+func (a *RetryAPI) GetListOfLatestSeries(ctx context.Context, pageNum int) (response *ListOfLatestSeriesResponse, err error) {
+	err = a.retry(ctx, func() error {
+		response, err = a.api.GetListOfLatestSeries(ctx, pageNum)
+		return err
+	})
+
+	return response, err
+}
+
+// SearchForOneByName implements the API interface
+func (a *RetryAPI) SearchForOneByName(ctx context.Context, movieName string) (response *FindOneResponse, err error) {
+	err = a.retry(ctx, func() error {
+		response, err = a.api.SearchForOneByName(ctx, movieName)
+		return err
+	})
+
+	return response, err
+}
+
+// SearchListByName implements the API interface
+func (a *RetryAPI) SearchListByName(ctx context.Context, movieName string) (response *FindListResponse, err error) {
+	err = a.retry(ctx, func() error {
+		response, err = a.api.SearchListByName(ctx, movieName)
+		return err
+	})
+
+	return response, err
+}
+
+// StreamListByName implements the API interface
+func (a *RetryAPI) StreamListByName(ctx context.Context, movieName string, fn func(movie *MovieSearchData) error) (info *ListPageInfo, err error) {
+	delivered := false
+	err = a.retryUntil(ctx, &delivered, func() error {
+		info, err = a.api.StreamListByName(ctx, movieName, func(movie *MovieSearchData) error {
+			delivered = true
+			return fn(movie)
+		})
+		return err
+	})
+
+	return info, err
+}
+
+// StreamListOfLatestSeries implements the API interface
+func (a *RetryAPI) StreamListOfLatestSeries(ctx context.Context, pageNum int, fn func(series *SeriesData) error) (info *ListPageInfo, err error) {
+	delivered := false
+	err = a.retryUntil(ctx, &delivered, func() error {
+		info, err = a.api.StreamListOfLatestSeries(ctx, pageNum, func(series *SeriesData) error {
+			delivered = true
+			return fn(series)
+		})
+		return err
+	})
+
+	return info, err
+}
+
+// retry executes the call until it succeeds, fails with a non-retryable error or the attempts are exhausted
+func (a *RetryAPI) retry(ctx context.Context, call func() error) error {
+	delivered := false
+
+	return a.retryUntil(ctx, &delivered, call)
+}
+
+// retryUntil executes the call the same way as retry, but stops as soon as the delivered flag is set
+func (a *RetryAPI) retryUntil(ctx context.Context, delivered *bool, call func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := call()
+		if err == nil || attempt >= a.maxAttempts || *delivered || !isRetryableError(err) {
+			return err
+		}
+
+		delay := time.Duration(float64(a.backoff) * math.Pow(2, float64(attempt-1)))
+		if sleepErr := sleepContext(ctx, delay); sleepErr != nil {
+			return sleepErr
+		}
+	}
+}
+
+//endregion
+
+//region - Private Methods
+
+// findOneStatus returns the API status of the response, if any
+func findOneStatus(response *FindOneResponse) string {
+	if response == nil {
+		return ""
+	}
+
+	return response.Status
+}
+
+// pageInfoStatus returns the API status of the streamed page, if any
+func pageInfoStatus(info *ListPageInfo) string {
+	if info == nil {
+		return ""
+	}
+
+	return info.Status
+}
+
+// errorOutcome classifies the result of the API call by the API status and the returned error
+func errorOutcome(status string, err error) string {
+	var tooLargeErr *ResponseTooLargeError
+	var statusCodeErr *UnexpectedStatusCodeError
+	var urlErr *url.Error
+
+	switch {
+	case err == nil && status == "error":
+		return OutcomeAPIError
+	case err == nil:
+		return OutcomeSuccess
+	case errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded):
+		return OutcomeCanceled
+	case errors.As(err, &tooLargeErr):
+		return OutcomeTooLarge
+	case errors.As(err, &statusCodeErr):
+		return OutcomeHTTPError
+	case errors.As(err, &urlErr):
+		return OutcomeTransportError
+	default:
+		return OutcomeDecodeError
+	}
+}
+
+// isRetryableError reports whether the API call failed with an error worth retrying
+func isRetryableError(err error) bool {
+	var statusCodeErr *UnexpectedStatusCodeError
+	var urlErr *url.Error
+
+	switch {
+	case errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded):
+		return false
+	case errors.As(err, &statusCodeErr):
+		return statusCodeErr.StatusCode == 429 || statusCodeErr.StatusCode >= 500
+	case errors.As(err, &urlErr):
+		return true
+	default:
+		return false
+	}
+}
+
+//endregion
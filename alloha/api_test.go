@@ -0,0 +1,240 @@
+package alloha
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newStatusServer creates a test server that responds with the status codes in turn and then with the body
+func newStatusServer(t *testing.T, body string, statusCodes ...int) (*httptest.Server, *int) {
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests <= len(statusCodes) {
+			w.WriteHeader(statusCodes[requests-1])
+			return
+		}
+
+		// Возвращаем тестовые данные
+		w.WriteHeader(http.StatusOK)
+		_, errWrite := io.WriteString(w, body)
+		if errWrite != nil {
+			t.Errorf("failed to write data to response: %v", errWrite)
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	return ts, &requests
+}
+
+func TestRetryAPI(t *testing.T) {
+	ts, requests := newStatusServer(t, "{\"status\":\"success\",\"data\":{\"name\":\"Бригада\",\"id_kp\":77044}}",
+		http.StatusTooManyRequests, http.StatusBadGateway)
+
+	// Создаем клиент с тестовым сервером
+	client, err := NewAPIClient(ts.Client(), "test-api-key", ts.URL)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	movie, errMovie := NewRetryAPI(client, 3, time.Millisecond).FindByKPId(t.Context(), 77044)
+
+	// Проверяем результат
+	assert.NoError(t, errMovie)
+	assert.Equal(t, "Бригада", movie.Data.Name)
+	assert.Equal(t, 3, *requests)
+}
+
+func TestRetryAPI_NotRetryable(t *testing.T) {
+	ts, requests := newStatusServer(t, "", http.StatusNotFound)
+
+	// Создаем клиент с тестовым сервером
+	client, err := NewAPIClient(ts.Client(), "test-api-key", ts.URL)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	_, errMovie := NewRetryAPI(client, 3, time.Millisecond).FindByKPId(t.Context(), 77044)
+
+	// Проверяем результат
+	var statusCodeErr *UnexpectedStatusCodeError
+	assert.ErrorAs(t, errMovie, &statusCodeErr)
+	assert.Equal(t, http.StatusNotFound, statusCodeErr.StatusCode)
+	assert.Equal(t, 1, *requests)
+}
+
+func TestRetryAPI_StreamDelivered(t *testing.T) {
+	ts, requests := newStatusServer(t, "{\"status\":\"success\",\"data\":[{\"season\":1,\"episode\":1},{\"season\":1,\"episode\":2}]}")
+
+	// Создаем клиент с тестовым сервером
+	client, err := NewAPIClient(ts.Client(), "test-api-key", ts.URL)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	callbackErr := &UnexpectedStatusCodeError{StatusCode: http.StatusServiceUnavailable}
+	_, errStream := NewRetryAPI(client, 3, time.Millisecond).StreamListOfLatestSeries(t.Context(), 1, func(series *SeriesData) error {
+		return callbackErr
+	})
+
+	// Проверяем результат: после передачи элемента вызов не повторяется
+	assert.ErrorIs(t, errStream, callbackErr)
+	assert.Equal(t, 1, *requests)
+}
+
+func TestMetricsAPI(t *testing.T) {
+	ts, _ := newStatusServer(t, "{\"status\":\"error\",\"error_info\":\"not movie\"}", http.StatusInternalServerError)
+
+	// Создаем клиент с тестовым сервером
+	client, err := NewAPIClient(ts.Client(), "test-api-key", ts.URL)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	metrics := &recordingMetrics{}
+	api := NewMetricsAPI(client, metrics)
+
+	_, _ = api.FindByKPId(t.Context(), 77044)
+	_, _ = api.FindByKPId(t.Context(), 77044)
+
+	// Проверяем результат
+	assert.Equal(t, []string{
+		OperationFindByKPId + ":" + OutcomeHTTPError,
+		OperationFindByKPId + ":" + OutcomeAPIError,
+	}, metrics.outcomes)
+}
+
+func TestCachingAPI(t *testing.T) {
+	ts, requests := newStatusServer(t, "{\"status\":\"success\",\"data\":{\"name\":\"Бригада\",\"id_kp\":77044}}")
+
+	// Создаем клиент с тестовым сервером
+	client, err := NewAPIClient(ts.Client(), "test-api-key", ts.URL)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	metrics := &cacheMetrics{}
+	tracer := &recordingTracer{}
+	api := NewCachingAPI(client, NewMemoryCache(), time.Minute)
+	api.SetMetricsRecorder(metrics)
+	api.SetTracer(tracer)
+
+	first, errFirst := api.FindByKPId(t.Context(), 77044)
+	second, errSecond := api.FindByKPId(t.Context(), 77044)
+	_, errOther := api.FindByTMDbId(t.Context(), 77044)
+
+	// Проверяем результат
+	assert.NoError(t, errFirst)
+	assert.NoError(t, errSecond)
+	assert.NoError(t, errOther)
+	assert.Same(t, first, second)
+	assert.Equal(t, 2, *requests)
+	assert.Equal(t, 1, metrics.hits)
+	assert.Equal(t, 2, metrics.misses)
+	if assert.Len(t, tracer.spans, 1) {
+		assert.Equal(t, true, tracer.spans[0].attributes[AttributeCacheHit])
+		assert.True(t, tracer.spans[0].ended)
+	}
+}
+
+func TestCachingAPI_ErrorNotCached(t *testing.T) {
+	ts, requests := newStatusServer(t, "{\"status\":\"error\",\"error_info\":\"not movie\"}")
+
+	// Создаем клиент с тестовым сервером
+	client, err := NewAPIClient(ts.Client(), "test-api-key", ts.URL)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	api := NewCachingAPI(client, NewMemoryCache(), time.Minute)
+	_, _ = api.SearchListByName(t.Context(), "Бригада")
+	_, _ = api.SearchListByName(t.Context(), "Бригада")
+
+	// Проверяем результат
+	assert.Equal(t, 2, *requests)
+}
+
+func TestMemoryCache_Expired(t *testing.T) {
+	cache := NewMemoryCache()
+	cache.Set("key", "value", -time.Second)
+
+	_, found := cache.Get("key")
+
+	// Проверяем результат
+	assert.False(t, found)
+}
+
+func TestMemoryCache_MaxEntries(t *testing.T) {
+	cache := NewMemoryCache()
+	require.NoError(t, cache.SetMaxEntries(2))
+	cache.Set("a", 1, time.Minute)
+	cache.Set("b", 2, time.Minute)
+	_, _ = cache.Get("a")
+	cache.Set("c", 3, time.Minute)
+
+	// Проверяем результат
+	assert.Equal(t, 2, cache.Len())
+	_, found := cache.Get("b")
+	assert.False(t, found)
+	value, found := cache.Get("a")
+	assert.True(t, found)
+	assert.Equal(t, 1, value)
+
+	cache.Set("expired", 4, -time.Second)
+	cache.Set("d", 5, time.Minute)
+	_, found = cache.Get("a")
+	assert.True(t, found)
+	_, found = cache.Get("d")
+	assert.True(t, found)
+
+	require.NoError(t, cache.SetMaxEntries(1))
+	assert.Equal(t, 1, cache.Len())
+	assert.Equal(t, InvalidMaxEntriesParameterError, cache.SetMaxEntries(0))
+}
+
+func Test_isRetryableError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "too many requests", err: &UnexpectedStatusCodeError{StatusCode: 429}, want: true},
+		{name: "server error", err: &UnexpectedStatusCodeError{StatusCode: 503}, want: true},
+		{name: "not found", err: &UnexpectedStatusCodeError{StatusCode: 404}, want: false},
+		{name: "transport", err: &url.Error{Op: "Get", URL: "https://api.alloha.tv", Err: errors.New("connection reset")}, want: true},
+		{name: "canceled", err: &url.Error{Op: "Get", URL: "https://api.alloha.tv", Err: context.Canceled}, want: false},
+		{name: "decode", err: &StrictDecodeError{}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isRetryableError(tt.err))
+		})
+	}
+}
+
+// cacheMetrics is a MetricsRecorder that counts the cache hits and misses
+type cacheMetrics struct {
+	NopMetricsRecorder
+	hits   int
+	misses int
+}
+
+// IncCacheHit implements the MetricsRecorder interface
+func (m *cacheMetrics) IncCacheHit(operation string) {
+	m.hits++
+}
+
+// IncCacheMiss implements the MetricsRecorder interface
+func (m *cacheMetrics) IncCacheMiss(operation string) {
+	m.misses++
+}
@@ -0,0 +1,292 @@
+package alloha
+
+import (
+	"container/list"
+	"context"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Cache provides an interface for storing the API responses. Implementations must be safe for concurrent use.
+type Cache interface {
+	// Get returns the value stored by the key, if it has not expired
+	Get(key string) (interface{}, bool)
+	// Set stores the value by the key for the ttl duration
+	Set(key string, value interface{}, ttl time.Duration)
+}
+
+// DefaultMemoryCacheSize is the default maximum number of the entries in the MemoryCache
+const DefaultMemoryCacheSize = 1000
+
+// MemoryCache is an in-memory Cache bounded by the maximum number of the entries. The expired entries are removed on
+// access and when the cache is full, then the least recently used entries are evicted.
+type MemoryCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[string]*list.Element
+	// order holds the entries from the most to the least recently used
+	order *list.List
+}
+
+// memoryCacheEntry is a value stored in the MemoryCache
+type memoryCacheEntry struct {
+	key     string
+	value   interface{}
+	expires time.Time
+}
+
+var _ Cache = (*MemoryCache)(nil)
+
+// NewMemoryCache creates a new MemoryCache instance with DefaultMemoryCacheSize entries at most
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{
+		maxEntries: DefaultMemoryCacheSize,
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+	}
+}
+
+// SetMaxEntries sets the maximum number of the entries, the excess least recently used entries are evicted
+func (c *MemoryCache) SetMaxEntries(maxEntries int) error {
+	if maxEntries <= 0 {
+		return InvalidMaxEntriesParameterError
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.maxEntries = maxEntries
+	for c.order.Len() > c.maxEntries {
+		c.remove(c.order.Back())
+	}
+
+	return nil
+}
+
+// Len returns the number of the entries including the expired ones that have not been removed yet
+func (c *MemoryCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
+
+// Get implements the Cache interface
+func (c *MemoryCache) Get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, found := c.entries[key]
+	if !found {
+		return nil, false
+	}
+	entry := element.Value.(*memoryCacheEntry)
+	if time.Now().After(entry.expires) {
+		c.remove(element)
+		return nil, false
+	}
+
+	c.order.MoveToFront(element)
+	return entry.value, true
+}
+
+// Set implements the Cache interface
+func (c *MemoryCache) Set(key string, value interface{}, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expires := time.Now().Add(ttl)
+	if element, found := c.entries[key]; found {
+		entry := element.Value.(*memoryCacheEntry)
+		entry.value, entry.expires = value, expires
+		c.order.MoveToFront(element)
+		return
+	}
+
+	if c.order.Len() >= c.maxEntries {
+		c.removeExpired()
+	}
+	for c.order.Len() >= c.maxEntries {
+		c.remove(c.order.Back())
+	}
+
+	c.entries[key] = c.order.PushFront(&memoryCacheEntry{key: key, value: value, expires: expires})
+}
+
+// removeExpired removes all expired entries
+func (c *MemoryCache) removeExpired() {
+	now := time.Now()
+	for element := c.order.Front(); element != nil; {
+		next := element.Next()
+		if now.After(element.Value.(*memoryCacheEntry).expires) {
+			c.remove(element)
+		}
+		element = next
+	}
+}
+
+// remove removes the entry from the cache
+func (c *MemoryCache) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.entries, element.Value.(*memoryCacheEntry).key)
+}
+
+//region - Caching Decorator
+
+// CachingAPI is an API decorator that serves the repeated calls from the Cache. Only the successful responses are
+// cached, the stream calls are never cached. The cached responses are shared, so they must not be modified.
+type CachingAPI struct {
+	api     API
+	cache   Cache
+	ttl     time.Duration
+	metrics MetricsRecorder
+	tracer  Tracer
+}
+
+var _ API = (*CachingAPI)(nil)
+
+// NewCachingAPI creates a new CachingAPI instance that keeps the responses in the cache for the ttl duration
+func NewCachingAPI(api API, cache Cache, ttl time.Duration) *CachingAPI {
+	return &CachingAPI{
+		api:     api,
+		cache:   cache,
+		ttl:     ttl,
+		metrics: NopMetricsRecorder{},
+		tracer:  NopTracer{},
+	}
+}
+
+// SetMetricsRecorder sets the recorder that receives the cache hits and misses. Passing nil disables the metrics.
+func (a *CachingAPI) SetMetricsRecorder(recorder MetricsRecorder) {
+	if recorder == nil {
+		recorder = NopMetricsRecorder{}
+	}
+
+	a.metrics = recorder
+}
+
+// SetTracer sets the tracer that receives a span for every call served from the cache. Passing nil disables tracing.
+func (a *CachingAPI) SetTracer(tracer Tracer) {
+	if tracer == nil {
+		tracer = NopTracer{}
+	}
+
+	a.tracer = tracer
+}
+
+// FindByIMDbId implements the API interface
+func (a *CachingAPI) FindByIMDbId(ctx context.Context, imdbId string) (*FindOneResponse, error) {
+	return a.findOne(ctx, OperationFindByIMDbId, imdbId, func() (*FindOneResponse, error) {
+		return a.api.FindByIMDbId(ctx, imdbId)
+	})
+}
+
+// FindByKPId implements the API interface
+func (a *CachingAPI) FindByKPId(ctx context.Context, kpId int) (*FindOneResponse, error) {
+	return a.findOne(ctx, OperationFindByKPId, strconv.Itoa(kpId), func() (*FindOneResponse, error) {
+		return a.api.FindByKPId(ctx, kpId)
+	})
+}
+
+// FindByTMDbId implements the API interface
+func (a *CachingAPI) FindByTMDbId(ctx context.Context, tmdbId int) (*FindOneResponse, error) {
+	return a.findOne(ctx, OperationFindByTMDbId, strconv.Itoa(tmdbId), func() (*FindOneResponse, error) {
+		return a.api.FindByTMDbId(ctx, tmdbId)
+	})
+}
+
+// GetListOfLatestSeries implements the API interface
+func (a *CachingAPI) GetListOfLatestSeries(ctx context.Context, pageNum int) (*ListOfLatestSeriesResponse, error) {
+	key := cacheKey(OperationGetListOfLatestSeries, strconv.Itoa(pageNum))
+	if cached, found := a.get(ctx, OperationGetListOfLatestSeries, key); found {
+		if response, ok := cached.(*ListOfLatestSeriesResponse); ok {
+			return response, nil
+		}
+	}
+
+	response, err := a.api.GetListOfLatestSeries(ctx, pageNum)
+	if err == nil && response != nil && response.Status == "success" {
+		a.cache.Set(key, response, a.ttl)
+	}
+
+	return response, err
+}
+
+// SearchForOneByName implements the API interface
+func (a *CachingAPI) SearchForOneByName(ctx context.Context, movieName string) (*FindOneResponse, error) {
+	return a.findOne(ctx, OperationSearchForOneByName, movieName, func() (*FindOneResponse, error) {
+		return a.api.SearchForOneByName(ctx, movieName)
+	})
+}
+
+// SearchListByName implements the API interface
+func (a *CachingAPI) SearchListByName(ctx context.Context, movieName string) (*FindListResponse, error) {
+	key := cacheKey(OperationSearchListByName, movieName)
+	if cached, found := a.get(ctx, OperationSearchListByName, key); found {
+		if response, ok := cached.(*FindListResponse); ok {
+			return response, nil
+		}
+	}
+
+	response, err := a.api.SearchListByName(ctx, movieName)
+	if err == nil && response != nil && response.Status == "success" {
+		a.cache.Set(key, response, a.ttl)
+	}
+
+	return response, err
+}
+
+// StreamListByName implements the API interface, the call is passed to the decorated API as is
+func (a *CachingAPI) StreamListByName(ctx context.Context, movieName string, fn func(movie *MovieSearchData) error) (*ListPageInfo, error) {
+	return a.api.StreamListByName(ctx, movieName, fn)
+}
+
+// StreamListOfLatestSeries implements the API interface, the call is passed to the decorated API as is
+func (a *CachingAPI) StreamListOfLatestSeries(ctx context.Context, pageNum int, fn func(series *SeriesData) error) (*ListPageInfo, error) {
+	return a.api.StreamListOfLatestSeries(ctx, pageNum, fn)
+}
+
+// findOne serves the single movie call from the cache or executes it and caches the successful response
+func (a *CachingAPI) findOne(ctx context.Context, operation, param string, call func() (*FindOneResponse, error)) (*FindOneResponse, error) {
+	key := cacheKey(operation, param)
+	if cached, found := a.get(ctx, operation, key); found {
+		if response, ok := cached.(*FindOneResponse); ok {
+			return response, nil
+		}
+	}
+
+	response, err := call()
+	if err == nil && response != nil && response.Status == "success" {
+		a.cache.Set(key, response, a.ttl)
+	}
+
+	return response, err
+}
+
+// get looks the key up in the cache and reports the hit or the miss to the metrics recorder and the tracer
+func (a *CachingAPI) get(ctx context.Context, operation, key string) (interface{}, bool) {
+	value, found := a.cache.Get(key)
+	if !found {
+		a.metrics.IncCacheMiss(operation)
+		return nil, false
+	}
+
+	a.metrics.IncCacheHit(operation)
+
+	_, span := a.tracer.Start(ctx, operation)
+	span.SetAttributes(
+		Attribute{Key: AttributeOperation, Value: operation},
+		Attribute{Key: AttributeCacheHit, Value: true},
+	)
+	span.End()
+
+	return value, true
+}
+
+// cacheKey builds the cache key of the call
+func cacheKey(operation, param string) string {
+	return operation + ":" + param
+}
+
+//endregion
@@ -0,0 +1,170 @@
+package alloha
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// ContentDecoder creates a reader that decodes the response body encoded with a specific content coding
+type ContentDecoder func(r io.Reader) (io.ReadCloser, error)
+
+// contentDecoderEntry represents a registered content decoder
+type contentDecoderEntry struct {
+	encoding string
+	decoder  ContentDecoder
+}
+
+// GzipContentDecoder decodes the response body encoded with the "gzip" content coding
+func GzipContentDecoder(r io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(r)
+}
+
+// DeflateContentDecoder decodes the response body encoded with the "deflate" content coding
+func DeflateContentDecoder(r io.Reader) (io.ReadCloser, error) {
+	return flate.NewReader(r), nil
+}
+
+//region - Public Methods
+
+// RegisterContentDecoder registers the decoder of the specified content coding, e.g. "br" or "zstd". Only the
+// registered content codings are advertised in the Accept-Encoding request header. Registering a decoder for an
+// already registered content coding replaces it.
+func (c *APIClient) RegisterContentDecoder(encoding string, decoder ContentDecoder) error {
+	encoding = strings.ToLower(strings.TrimSpace(encoding))
+	if len(encoding) <= 0 || encoding == "identity" {
+		return InvalidContentEncodingParameterError
+	}
+	if decoder == nil {
+		return NilContentDecoderParameterError
+	}
+
+	for i := range c.contentDecoders {
+		if c.contentDecoders[i].encoding == encoding {
+			c.contentDecoders[i].decoder = decoder
+			return nil
+		}
+	}
+
+	c.contentDecoders = append(c.contentDecoders, contentDecoderEntry{encoding: encoding, decoder: decoder})
+
+	return nil
+}
+
+// UnregisterContentDecoder removes the decoder of the specified content coding, so that it is no longer advertised
+// in the Accept-Encoding request header
+func (c *APIClient) UnregisterContentDecoder(encoding string) {
+	encoding = strings.ToLower(strings.TrimSpace(encoding))
+
+	for i := range c.contentDecoders {
+		if c.contentDecoders[i].encoding == encoding {
+			c.contentDecoders = append(c.contentDecoders[:i:i], c.contentDecoders[i+1:]...)
+			return
+		}
+	}
+}
+
+//endregion
+
+//region - Private Methods
+
+// defaultContentDecoders returns the content decoders registered in a new client
+func defaultContentDecoders() []contentDecoderEntry {
+	return []contentDecoderEntry{
+		{encoding: "gzip", decoder: GzipContentDecoder},
+		{encoding: "deflate", decoder: DeflateContentDecoder},
+	}
+}
+
+// acceptEncoding returns the value of the Accept-Encoding request header built from the registered decoders
+func (c *APIClient) acceptEncoding() string {
+	encodings := make([]string, 0, len(c.contentDecoders))
+	for _, entry := range c.contentDecoders {
+		encodings = append(encodings, entry.encoding)
+	}
+
+	if len(encodings) <= 0 {
+		return "identity"
+	}
+
+	return strings.Join(encodings, ", ")
+}
+
+// contentDecoder returns the registered decoder of the specified content coding
+func (c *APIClient) contentDecoder(encoding string) (ContentDecoder, bool) {
+	for _, entry := range c.contentDecoders {
+		if entry.encoding == encoding {
+			return entry.decoder, true
+		}
+	}
+
+	return nil, false
+}
+
+// decodeContent wraps the response body into the decoders of all content codings applied to the response. Each
+// decoded stream is limited by the maximum response size. The returned stream closes all decoders and the body.
+func (c *APIClient) decodeContent(ctx context.Context, resp *http.Response) (*responseBody, error) {
+	// The limit is enforced on the compressed stream as well, to stop reading a broken response as early as possible
+	wireReader := newLimitedReader(resp.Body, c.maxResponseSize)
+	body := &responseBody{
+		Reader:  wireReader,
+		wire:    wireReader,
+		closers: []io.Closer{resp.Body},
+	}
+
+	// The response has already been decoded by the http.Transport
+	if resp.Uncompressed {
+		return body, nil
+	}
+
+	encodings := parseContentEncoding(resp.Header)
+
+	// The content codings are listed in the order they were applied, so they are decoded in the reverse order
+	for i := len(encodings) - 1; i >= 0; i-- {
+		decoder, found := c.contentDecoder(encodings[i])
+		if !found {
+			return nil, c.closeOnError(ctx, body, &UnsupportedContentEncodingError{Encoding: encodings[i]})
+		}
+
+		decodedReader, err := decoder(body.Reader)
+		if err != nil {
+			return nil, c.closeOnError(ctx, body, err)
+		}
+		if decodedReader == nil {
+			decodedReader = io.NopCloser(body.Reader)
+		}
+
+		body.Reader = newLimitedReader(decodedReader, c.maxResponseSize)
+		body.closers = append([]io.Closer{decodedReader}, body.closers...)
+	}
+
+	return body, nil
+}
+
+// closeOnError closes the response body and returns the specified error
+func (c *APIClient) closeOnError(ctx context.Context, body io.Closer, err error) error {
+	c.closeResponseReader(ctx, body)
+
+	return err
+}
+
+// parseContentEncoding returns the content codings listed in the Content-Encoding response headers
+func parseContentEncoding(header http.Header) []string {
+	var encodings []string
+
+	for _, value := range header.Values("Content-Encoding") {
+		for _, encoding := range strings.Split(value, ",") {
+			encoding = strings.ToLower(strings.TrimSpace(encoding))
+			if len(encoding) > 0 && encoding != "identity" {
+				encodings = append(encodings, encoding)
+			}
+		}
+	}
+
+	return encodings
+}
+
+//endregion
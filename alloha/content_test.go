@@ -0,0 +1,194 @@
+package alloha
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIClient_RegisterContentDecoder(t *testing.T) {
+	passthroughDecoder := func(r io.Reader) (io.ReadCloser, error) {
+		return io.NopCloser(r), nil
+	}
+
+	tests := []struct {
+		name                   string
+		encoding               string
+		decoder                ContentDecoder
+		expectedErr            error
+		expectedAcceptEncoding string
+	}{
+		{
+			name:                   "empty encoding",
+			encoding:               " ",
+			decoder:                passthroughDecoder,
+			expectedErr:            InvalidContentEncodingParameterError,
+			expectedAcceptEncoding: "gzip, deflate",
+		},
+		{
+			name:                   "identity encoding",
+			encoding:               "identity",
+			decoder:                passthroughDecoder,
+			expectedErr:            InvalidContentEncodingParameterError,
+			expectedAcceptEncoding: "gzip, deflate",
+		},
+		{
+			name:                   "nil decoder",
+			encoding:               "br",
+			decoder:                nil,
+			expectedErr:            NilContentDecoderParameterError,
+			expectedAcceptEncoding: "gzip, deflate",
+		},
+		{
+			name:                   "new encoding",
+			encoding:               "BR",
+			decoder:                passthroughDecoder,
+			expectedAcceptEncoding: "gzip, deflate, br",
+		},
+		{
+			name:                   "replaced encoding",
+			encoding:               "gzip",
+			decoder:                passthroughDecoder,
+			expectedAcceptEncoding: "gzip, deflate",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, errClient := NewAPIClient(http.DefaultClient, "test-api-token", "https://example.com")
+			assert.NoError(t, errClient)
+
+			err := client.RegisterContentDecoder(tt.encoding, tt.decoder)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedAcceptEncoding, client.acceptEncoding())
+		})
+	}
+}
+
+func TestAPIClient_UnregisterContentDecoder(t *testing.T) {
+	client, errClient := NewAPIClient(http.DefaultClient, "test-api-token", "https://example.com")
+	assert.NoError(t, errClient)
+
+	client.UnregisterContentDecoder("gzip")
+	assert.Equal(t, "deflate", client.acceptEncoding())
+
+	client.UnregisterContentDecoder("Deflate")
+	assert.Equal(t, "identity", client.acceptEncoding())
+}
+
+func TestAPIClient_doApiRequest_ContentEncoding(t *testing.T) {
+	body := []byte("{\"status\":\"success\"}")
+
+	var gzipBody bytes.Buffer
+	gzipWriter := gzip.NewWriter(&gzipBody)
+	_, _ = gzipWriter.Write(body)
+	_ = gzipWriter.Close()
+
+	var deflateGzipBody bytes.Buffer
+	deflateWriter, _ := flate.NewWriter(&deflateGzipBody, flate.BestCompression)
+	_, _ = deflateWriter.Write(gzipBody.Bytes())
+	_ = deflateWriter.Close()
+
+	tests := []struct {
+		name            string
+		contentEncoding []string
+		responseBody    []byte
+		expectedErr     error
+	}{
+		{
+			name:            "identity encoding",
+			contentEncoding: []string{"identity"},
+			responseBody:    body,
+		},
+		{
+			name:            "gzip encoding",
+			contentEncoding: []string{"GZIP"},
+			responseBody:    gzipBody.Bytes(),
+		},
+		{
+			name:            "stacked encodings in a single header",
+			contentEncoding: []string{"gzip, deflate"},
+			responseBody:    deflateGzipBody.Bytes(),
+		},
+		{
+			name:            "stacked encodings in multiple headers",
+			contentEncoding: []string{"gzip", "deflate"},
+			responseBody:    deflateGzipBody.Bytes(),
+		},
+		{
+			name:            "unregistered encoding",
+			contentEncoding: []string{"gzip", "br"},
+			responseBody:    gzipBody.Bytes(),
+			expectedErr:     &UnsupportedContentEncodingError{Encoding: "br"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				// Проверяем заголовки запроса
+				assert.Equal(t, "gzip, deflate", r.Header.Get("Accept-Encoding"))
+
+				// Возвращаем тестовые данные
+				for _, encoding := range tt.contentEncoding {
+					w.Header().Add("Content-Encoding", encoding)
+				}
+				w.WriteHeader(http.StatusOK)
+				_, errWrite := w.Write(tt.responseBody)
+				if errWrite != nil {
+					t.Errorf("failed to write data to response: %v", errWrite)
+				}
+			}))
+			defer ts.Close()
+
+			client, errClient := NewAPIClient(ts.Client(), "test-api-token", ts.URL)
+			assert.NoError(t, errClient)
+
+			resp, statusCode, errRequest := client.doApiRequest(t.Context(), http.MethodGet, client.baseURL, nil)
+
+			if tt.expectedErr != nil {
+				assert.Equal(t, tt.expectedErr, errRequest)
+			} else {
+				assert.NoError(t, errRequest)
+				assert.Equal(t, http.StatusOK, statusCode)
+				assert.Equal(t, body, resp)
+			}
+		})
+	}
+}
+
+// transportDecodedClient emulates an http.Client whose transport has already decoded the response body
+type transportDecodedClient struct{}
+
+// Do implements the HttpClient interface
+func (c *transportDecodedClient) Do(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode:   http.StatusOK,
+		Header:       http.Header{"Content-Encoding": []string{"gzip"}},
+		Body:         io.NopCloser(strings.NewReader("{\"status\":\"success\"}")),
+		Uncompressed: true,
+	}, nil
+}
+
+func TestAPIClient_doApiRequest_TransportUncompressed(t *testing.T) {
+	client, errClient := NewAPIClient(&transportDecodedClient{}, "test-api-token", "https://example.com")
+	assert.NoError(t, errClient)
+
+	resp, _, errRequest := client.doApiRequest(t.Context(), http.MethodGet, client.baseURL, nil)
+
+	// Проверяем результат
+	assert.NoError(t, errRequest)
+	assert.Equal(t, "{\"status\":\"success\"}", string(resp))
+}
@@ -0,0 +1,382 @@
+package alloha
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// DecodeMode defines how strictly the API responses are checked against the SDK types
+type DecodeMode int
+
+const (
+	// DecodeModeDefault decodes the responses without any checks, unknown fields are dropped
+	DecodeModeDefault DecodeMode = iota
+	// DecodeModeStrictWarn checks the responses and collects the found issues as response warnings
+	DecodeModeStrictWarn
+	// DecodeModeStrictError checks the responses and fails the request if any issue is found
+	DecodeModeStrictError
+)
+
+// DecodeIssueKind defines the kind of the issue found while decoding a response
+type DecodeIssueKind int
+
+const (
+	// DecodeIssueUnknownField means that the response contains a field that the SDK does not model
+	DecodeIssueUnknownField DecodeIssueKind = iota + 1
+	// DecodeIssueTypeMismatch means that the type of the field value differs from the SDK type
+	DecodeIssueTypeMismatch
+)
+
+// String implements the fmt.Stringer interface
+func (k DecodeIssueKind) String() string {
+	switch k {
+	case DecodeIssueUnknownField:
+		return "unknown field"
+	case DecodeIssueTypeMismatch:
+		return "type mismatch"
+	default:
+		return fmt.Sprintf("DecodeIssueKind(%d)", int(k))
+	}
+}
+
+// DecodeIssue represents a single difference between the response JSON and the SDK types
+type DecodeIssue struct {
+	// Kind of the issue
+	Kind DecodeIssueKind
+	// JSON path of the value, e.g. "$.data.seasons.1.episodes"
+	Path string
+	// Expected JSON type (only for the type mismatch issues)
+	Expected string
+	// Actual JSON type
+	Actual string
+	// Whether the value could not be coerced to the expected type and was dropped (only for the lenient decoding)
+	Dropped bool
+}
+
+// String implements the fmt.Stringer interface
+func (i *DecodeIssue) String() string {
+	if i.Kind == DecodeIssueTypeMismatch {
+		if i.Dropped {
+			return fmt.Sprintf("%s: %s, expected %s, got %s, value dropped", i.Path, i.Kind, i.Expected, i.Actual)
+		}
+		return fmt.Sprintf("%s: %s, expected %s, got %s", i.Path, i.Kind, i.Expected, i.Actual)
+	}
+
+	return fmt.Sprintf("%s: %s of type %s", i.Path, i.Kind, i.Actual)
+}
+
+// responseMeta holds the decoding metadata shared by all API responses
+type responseMeta struct {
+	raw       json.RawMessage
+	warnings  []*DecodeIssue
+	anomalies []*DecodeIssue
+}
+
+// Raw returns the original JSON body of the response. It is kept only if the client has SetKeepRawResponse enabled
+// or a strict decode mode set, otherwise it is nil.
+func (m *responseMeta) Raw() json.RawMessage {
+	return m.raw
+}
+
+// Warnings returns the issues found while decoding the response in the DecodeModeStrictWarn mode
+func (m *responseMeta) Warnings() []*DecodeIssue {
+	return m.warnings
+}
+
+// Anomalies returns the inconsistently typed fields of the response data that were coerced or dropped while decoding,
+// the paths are relative to the response, e.g. "$.data.year"
+func (m *responseMeta) Anomalies() []*DecodeIssue {
+	return m.anomalies
+}
+
+// setDecodeMeta stores the raw response body and the decoding warnings
+func (m *responseMeta) setDecodeMeta(raw json.RawMessage, warnings []*DecodeIssue) {
+	m.raw = raw
+	m.warnings = warnings
+}
+
+// decodableResponse is implemented by all API responses that keep the decoding metadata
+type decodableResponse interface {
+	setDecodeMeta(raw json.RawMessage, warnings []*DecodeIssue)
+	responseStatus() string
+}
+
+// responseStatus implements the decodableResponse interface
+func (r *FindOneResponse) responseStatus() string {
+	return r.Status
+}
+
+// responseStatus implements the decodableResponse interface
+func (r *FindListResponse) responseStatus() string {
+	return r.Status
+}
+
+// responseStatus implements the decodableResponse interface
+func (r *ListOfLatestSeriesResponse) responseStatus() string {
+	return r.Status
+}
+
+var nullInt32Type = reflect.TypeOf(NullInt32{})
+
+// decodeResponse decodes the response body stream directly into the response structure according to the client
+// decode mode. The body is copied aside only if the raw body is kept or it is inspected in a strict mode.
+func (c *APIClient) decodeResponse(body io.Reader, response decodableResponse) error {
+	var buffer *bytes.Buffer
+	if c.keepRaw || c.decodeMode != DecodeModeDefault {
+		buffer = &bytes.Buffer{}
+		body = io.TeeReader(body, buffer)
+	}
+
+	decoder := json.NewDecoder(body)
+	if err := decoder.Decode(response); err != nil {
+		return err
+	}
+	if buffer == nil {
+		return nil
+	}
+
+	raw := json.RawMessage(bytes.TrimSpace(buffer.Bytes()[:decoder.InputOffset()]))
+
+	var issues []*DecodeIssue
+	if c.decodeMode != DecodeModeDefault {
+		var err error
+		issues, err = inspectJSON(raw, reflect.TypeOf(response))
+		if err != nil {
+			return err
+		}
+
+		if len(issues) > 0 && c.decodeMode == DecodeModeStrictError {
+			return &StrictDecodeError{Issues: issues}
+		}
+	}
+
+	response.setDecodeMeta(raw, issues)
+
+	return nil
+}
+
+// inspectJSON compares the JSON document with the specified type and returns the found issues
+func inspectJSON(data []byte, t reflect.Type) ([]*DecodeIssue, error) {
+	var value interface{}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	return inspectValue("$", value, t), nil
+}
+
+// inspectValue recursively compares the decoded JSON value with the specified type
+func inspectValue(path string, value interface{}, t reflect.Type) []*DecodeIssue {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	// null is accepted by json.Unmarshal for any type
+	if value == nil {
+		return nil
+	}
+
+	expected := jsonTypeOf(t)
+	if expected == "" || t.Kind() == reflect.Interface {
+		return nil
+	}
+
+	mismatch := []*DecodeIssue{{
+		Kind:     DecodeIssueTypeMismatch,
+		Path:     path,
+		Expected: expected,
+		Actual:   jsonKindOf(value),
+	}}
+
+	if t == nullInt32Type {
+		if !isJSONInteger(value) {
+			return mismatch
+		}
+
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return mismatch
+		}
+
+		var issues []*DecodeIssue
+		for _, key := range sortedKeys(object) {
+			fieldPath := path + "." + key
+
+			field, found := jsonField(t, key)
+			if !found {
+				issues = append(issues, &DecodeIssue{
+					Kind:   DecodeIssueUnknownField,
+					Path:   fieldPath,
+					Actual: jsonKindOf(object[key]),
+				})
+				continue
+			}
+
+			issues = append(issues, inspectValue(fieldPath, object[key], field.Type)...)
+		}
+
+		return issues
+	case reflect.Map:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return mismatch
+		}
+
+		var issues []*DecodeIssue
+		for _, key := range sortedKeys(object) {
+			issues = append(issues, inspectValue(path+"."+key, object[key], t.Elem())...)
+		}
+
+		return issues
+	case reflect.Slice, reflect.Array:
+		array, ok := value.([]interface{})
+		if !ok {
+			return mismatch
+		}
+
+		var issues []*DecodeIssue
+		for i, item := range array {
+			issues = append(issues, inspectValue(fmt.Sprintf("%s[%d]", path, i), item, t.Elem())...)
+		}
+
+		return issues
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if !isJSONInteger(value) {
+			return mismatch
+		}
+	case reflect.Float32, reflect.Float64:
+		if _, ok := value.(json.Number); !ok {
+			return mismatch
+		}
+	case reflect.String:
+		if _, ok := value.(string); !ok {
+			return mismatch
+		}
+	case reflect.Bool:
+		if _, ok := value.(bool); !ok {
+			return mismatch
+		}
+	}
+
+	return nil
+}
+
+// jsonField finds the struct field the JSON key is decoded into, the same way encoding/json does
+func jsonField(t reflect.Type, key string) (reflect.StructField, bool) {
+	var foldMatch *reflect.StructField
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		if name == key {
+			return field, true
+		}
+		if foldMatch == nil && strings.EqualFold(name, key) {
+			foldMatch = &field
+		}
+	}
+
+	if foldMatch != nil {
+		return *foldMatch, true
+	}
+
+	return reflect.StructField{}, false
+}
+
+// jsonTypeOf returns the name of the JSON type the specified Go type is decoded from
+func jsonTypeOf(t reflect.Type) string {
+	if t == nullInt32Type {
+		return "integer"
+	}
+
+	switch t.Kind() {
+	case reflect.Struct, reflect.Map:
+		return "object"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Interface:
+		return "any"
+	default:
+		return ""
+	}
+}
+
+// jsonKindOf returns the name of the JSON type of the decoded value
+func jsonKindOf(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case json.Number:
+		if isJSONInteger(v) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// isJSONInteger reports whether the decoded value is an integer number
+func isJSONInteger(value interface{}) bool {
+	number, ok := value.(json.Number)
+	if !ok {
+		return false
+	}
+
+	_, err := number.Int64()
+
+	return err == nil
+}
+
+// sortedKeys returns the keys of the JSON object in a stable order
+func sortedKeys(object map[string]interface{}) []string {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,171 @@
+package alloha
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIClient_SetDecodeMode(t *testing.T) {
+	tests := []struct {
+		name        string
+		mode        DecodeMode
+		expectedErr error
+	}{
+		{
+			name:        "default mode",
+			mode:        DecodeModeDefault,
+			expectedErr: nil,
+		},
+		{
+			name:        "strict error mode",
+			mode:        DecodeModeStrictError,
+			expectedErr: nil,
+		},
+		{
+			name:        "invalid mode",
+			mode:        DecodeMode(42),
+			expectedErr: InvalidDecodeModeParameterError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, errClient := NewAPIClient(http.DefaultClient, "test-api-token", "https://example.com")
+			assert.NoError(t, errClient)
+
+			err := client.SetDecodeMode(tt.mode)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				assert.Equal(t, DecodeModeDefault, client.decodeMode)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.mode, client.decodeMode)
+			}
+		})
+	}
+}
+
+func TestAPIClient_decodeResponse(t *testing.T) {
+	const body = "{\"status\":\"success\",\"data\":{\"name\":\"Бригада\",\"year\":2002,\"id_kp\":77044," +
+		"\"skip_time\":null,\"seasons\":{\"1\":{\"season\":1,\"episodes\":{\"1\":" +
+		"{\"episode\":1,\"iframe_hd\":\"https://example.com/1\"}}}}}}"
+	const bodyWithMismatch = "{\"status\":\"success\",\"data\":{\"name\":\"Бригада\",\"id_tmdb\":\"4561\"}}"
+
+	tests := []struct {
+		name             string
+		mode             DecodeMode
+		body             string
+		expectedWarnings []string
+		expectedIssues   []string
+	}{
+		{
+			name:             "default mode ignores unknown fields",
+			mode:             DecodeModeDefault,
+			body:             body,
+			expectedWarnings: nil,
+		},
+		{
+			name: "strict warn mode collects issues",
+			mode: DecodeModeStrictWarn,
+			body: body,
+			expectedWarnings: []string{
+				"$.data.seasons.1.episodes.1.iframe_hd: unknown field of type string",
+				"$.data.skip_time: unknown field of type null",
+			},
+		},
+		{
+			name:           "strict error mode fails on unknown fields",
+			mode:           DecodeModeStrictError,
+			body:           body,
+			expectedIssues: []string{"$.data.seasons.1.episodes.1.iframe_hd", "$.data.skip_time"},
+		},
+		{
+			name:           "strict error mode fails on type mismatch",
+			mode:           DecodeModeStrictError,
+			body:           bodyWithMismatch,
+			expectedIssues: []string{"$.data.id_tmdb"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, errClient := NewAPIClient(http.DefaultClient, "test-api-token", "https://example.com")
+			assert.NoError(t, errClient)
+			assert.NoError(t, client.SetDecodeMode(tt.mode))
+
+			response := &FindOneResponse{}
+			err := client.decodeResponse(strings.NewReader(tt.body), response)
+
+			if tt.expectedIssues != nil {
+				var strictErr *StrictDecodeError
+				if assert.ErrorAs(t, err, &strictErr) {
+					var paths []string
+					for _, issue := range strictErr.Issues {
+						paths = append(paths, issue.Path)
+					}
+					assert.Equal(t, tt.expectedIssues, paths)
+				}
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, "Бригада", response.Data.Name)
+
+			var warnings []string
+			for _, issue := range response.Warnings() {
+				warnings = append(warnings, issue.String())
+			}
+			assert.Equal(t, tt.expectedWarnings, warnings)
+		})
+	}
+}
+
+func TestAPIClient_FindByKPId_StrictWarnMode(t *testing.T) {
+	const body = "{\"status\":\"success\",\"data\":{\"name\":\"Бригада\",\"year\":2002,\"id_kp\":77044,\"skip_time\":null}}"
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Возвращаем тестовые данные
+		w.WriteHeader(http.StatusOK)
+		_, errWrite := io.WriteString(w, body)
+		if errWrite != nil {
+			t.Errorf("failed to write data to response: %v", errWrite)
+		}
+	}))
+	defer ts.Close()
+
+	// Создаем клиент с тестовым сервером
+	client, err := NewAPIClient(ts.Client(), "test-api-key", ts.URL)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	assert.NoError(t, client.SetDecodeMode(DecodeModeStrictWarn))
+
+	movie, errMovie := client.FindByKPId(t.Context(), 77044)
+
+	// Проверяем результат
+	assert.Nil(t, errMovie)
+
+	assert.Equal(t, "Бригада", movie.Data.Name)
+	assert.JSONEq(t, body, string(movie.Raw()))
+	assert.Len(t, movie.Warnings(), 1)
+	assert.Equal(t, DecodeIssueUnknownField, movie.Warnings()[0].Kind)
+	assert.Equal(t, "$.data.skip_time", movie.Warnings()[0].Path)
+
+	// В режиме по умолчанию исходное тело сохраняется только по запросу
+	assert.NoError(t, client.SetDecodeMode(DecodeModeDefault))
+	movie, errMovie = client.FindByKPId(t.Context(), 77044)
+	assert.Nil(t, errMovie)
+	assert.Nil(t, movie.Raw())
+
+	client.SetKeepRawResponse(true)
+	movie, errMovie = client.FindByKPId(t.Context(), 77044)
+	assert.Nil(t, errMovie)
+	assert.JSONEq(t, body, string(movie.Raw()))
+	assert.Empty(t, movie.Warnings())
+}
@@ -0,0 +1,283 @@
+package alloha
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/electromystyle/alloha-sdk-go/internal/strutil"
+)
+
+// ChangeKind is the kind of the title data change
+type ChangeKind string
+
+// Kinds of the title data changes
+const (
+	ChangeTitleAdded         ChangeKind = "title_added"
+	ChangeTitleRemoved       ChangeKind = "title_removed"
+	ChangeFieldChanged       ChangeKind = "field_changed"
+	ChangeRatingChanged      ChangeKind = "rating_changed"
+	ChangePosterChanged      ChangeKind = "poster_changed"
+	ChangeQualityUpgrade     ChangeKind = "quality_upgrade"
+	ChangeQualityDowngrade   ChangeKind = "quality_downgrade"
+	ChangeSeasonAdded        ChangeKind = "season_added"
+	ChangeSeasonRemoved      ChangeKind = "season_removed"
+	ChangeEpisodeAdded       ChangeKind = "episode_added"
+	ChangeEpisodeRemoved     ChangeKind = "episode_removed"
+	ChangeTranslationAdded   ChangeKind = "translation_added"
+	ChangeTranslationRemoved ChangeKind = "translation_removed"
+)
+
+// Change is a change of the title data between two snapshots
+type Change struct {
+	Kind ChangeKind `json:"kind"`
+	// Path of the changed field built from the JSON field names and the map keys, e.g.
+	// "seasons.1.episodes.2.translation.66.quality"
+	Path string `json:"path"`
+	// Old and new values of the field, the season, the episode or the translation
+	Old interface{} `json:"old,omitempty"`
+	New interface{} `json:"new,omitempty"`
+}
+
+// movieField is a scalar field of the MovieData compared by Diff
+type movieField struct {
+	path  string
+	kind  ChangeKind
+	value func(m *MovieData) interface{}
+}
+
+// movieFields are the scalar fields of the MovieData compared by Diff, the quality is compared separately
+var movieFields = []movieField{
+	{"name", ChangeFieldChanged, func(m *MovieData) interface{} { return m.Name }},
+	{"original_name", ChangeFieldChanged, func(m *MovieData) interface{} { return m.OriginalName }},
+	{"alternative_name", ChangeFieldChanged, func(m *MovieData) interface{} { return m.AlternativeName }},
+	{"year", ChangeFieldChanged, func(m *MovieData) interface{} { return m.Year }},
+	{"category", ChangeFieldChanged, func(m *MovieData) interface{} { return m.Category }},
+	{"id_kp", ChangeFieldChanged, func(m *MovieData) interface{} { return m.IDKp }},
+	{"alternative_id_kp", ChangeFieldChanged, func(m *MovieData) interface{} { return m.AlternativeIDKp }},
+	{"id_imdb", ChangeFieldChanged, func(m *MovieData) interface{} { return m.IDImdb }},
+	{"id_tmdb", ChangeFieldChanged, func(m *MovieData) interface{} { return m.IDTmdb }},
+	{"id_world_art", ChangeFieldChanged, func(m *MovieData) interface{} { return m.IDWorldArt }},
+	{"token_movie", ChangeFieldChanged, func(m *MovieData) interface{} { return m.TokenMovie }},
+	{"country", ChangeFieldChanged, func(m *MovieData) interface{} { return m.Country }},
+	{"genre", ChangeFieldChanged, func(m *MovieData) interface{} { return m.Genre }},
+	{"actors", ChangeFieldChanged, func(m *MovieData) interface{} { return m.Actors }},
+	{"directors", ChangeFieldChanged, func(m *MovieData) interface{} { return m.Directors }},
+	{"producers", ChangeFieldChanged, func(m *MovieData) interface{} { return m.Producers }},
+	{"premiere_ru", ChangeFieldChanged, func(m *MovieData) interface{} { return m.PremiereRu }},
+	{"premiere", ChangeFieldChanged, func(m *MovieData) interface{} { return m.Premiere }},
+	{"age_restrictions", ChangeFieldChanged, func(m *MovieData) interface{} { return m.AgeRestrictions }},
+	{"rating_mpaa", ChangeFieldChanged, func(m *MovieData) interface{} { return m.RatingMpaa }},
+	{"rating_kp", ChangeRatingChanged, func(m *MovieData) interface{} { return m.RatingKp }},
+	{"rating_imdb", ChangeRatingChanged, func(m *MovieData) interface{} { return m.RatingImdb }},
+	{"time", ChangeFieldChanged, func(m *MovieData) interface{} { return m.Time }},
+	{"tagline", ChangeFieldChanged, func(m *MovieData) interface{} { return m.Tagline }},
+	{"poster", ChangePosterChanged, func(m *MovieData) interface{} { return m.Poster }},
+	{"description", ChangeFieldChanged, func(m *MovieData) interface{} { return m.Description }},
+	{"seasons_count", ChangeFieldChanged, func(m *MovieData) interface{} { return m.SeasonsCount }},
+	{"translation", ChangeFieldChanged, func(m *MovieData) interface{} { return m.Translation }},
+	{"iframe", ChangeFieldChanged, func(m *MovieData) interface{} { return m.Iframe }},
+	{"iframe_trailer", ChangeFieldChanged, func(m *MovieData) interface{} { return m.IframeTrailer }},
+	{"lgbt", ChangeFieldChanged, func(m *MovieData) interface{} { return m.Lgbt }},
+	{"uhd", ChangeFieldChanged, func(m *MovieData) interface{} { return m.Uhd }},
+	{"available_directors_cut", ChangeFieldChanged, func(m *MovieData) interface{} { return m.AvailableDirectorsCut }},
+}
+
+// Diff returns the changes between two snapshots of the title. The seasons, the episodes and the translations are
+// compared by their map keys, so that a new episode is reported as a single ChangeEpisodeAdded change. The changes
+// are ordered by the field order of the MovieData and by the numeric map keys.
+func Diff(old, new *MovieData) []Change {
+	switch {
+	case old == nil && new == nil:
+		return nil
+	case old == nil:
+		return []Change{{Kind: ChangeTitleAdded, New: new}}
+	case new == nil:
+		return []Change{{Kind: ChangeTitleRemoved, Old: old}}
+	}
+
+	var changes []Change
+	for _, field := range movieFields {
+		oldValue, newValue := field.value(old), field.value(new)
+		if oldValue != newValue {
+			changes = append(changes, Change{Kind: field.kind, Path: field.path, Old: oldValue, New: newValue})
+		}
+	}
+
+	changes = append(changes, diffQuality("quality", old.Quality, new.Quality)...)
+	changes = append(changes, diffSeasons("seasons", old.Seasons, new.Seasons)...)
+	changes = append(changes, diffTranslations("translation_iframe", old.TranslationIframe, new.TranslationIframe)...)
+
+	return changes
+}
+
+// FormatChanges returns the human-readable representation of the changes, one change per line
+func FormatChanges(changes []Change) string {
+	var sb strings.Builder
+	for _, change := range changes {
+		sb.WriteString(change.String())
+		sb.WriteByte('\n')
+	}
+
+	return sb.String()
+}
+
+// String returns the human-readable representation of the change
+func (c Change) String() string {
+	switch c.Kind {
+	case ChangeTitleAdded, ChangeTitleRemoved:
+		return string(c.Kind)
+	case ChangeSeasonAdded, ChangeEpisodeAdded, ChangeTranslationAdded,
+		ChangeSeasonRemoved, ChangeEpisodeRemoved, ChangeTranslationRemoved:
+		return fmt.Sprintf("%s %s", c.Kind, c.Path)
+	default:
+		return fmt.Sprintf("%s %s: %s -> %s", c.Kind, c.Path, formatChangeValue(c.Old), formatChangeValue(c.New))
+	}
+}
+
+// diffQuality returns the change of the video quality
+func diffQuality(path, old, new string) []Change {
+	if old == new {
+		return nil
+	}
+
+	kind := ChangeFieldChanged
+	switch {
+	case IsQualityUpgrade(old, new):
+		kind = ChangeQualityUpgrade
+	case IsQualityUpgrade(new, old):
+		kind = ChangeQualityDowngrade
+	}
+
+	return []Change{{Kind: kind, Path: path, Old: old, New: new}}
+}
+
+// diffSeasons returns the changes of the seasons
+func diffSeasons(path string, old, new map[string]SeasonIframe) []Change {
+	var changes []Change
+
+	for _, key := range unionKeys(old, new) {
+		seasonPath := path + "." + key
+		oldSeason, inOld := old[key]
+		newSeason, inNew := new[key]
+
+		switch {
+		case !inOld:
+			changes = append(changes, Change{Kind: ChangeSeasonAdded, Path: seasonPath, New: newSeason})
+		case !inNew:
+			changes = append(changes, Change{Kind: ChangeSeasonRemoved, Path: seasonPath, Old: oldSeason})
+		default:
+			if oldSeason.Iframe != newSeason.Iframe {
+				changes = append(changes, Change{
+					Kind: ChangeFieldChanged, Path: seasonPath + ".iframe", Old: oldSeason.Iframe, New: newSeason.Iframe,
+				})
+			}
+			changes = append(changes, diffEpisodes(seasonPath+".episodes", oldSeason.Episodes, newSeason.Episodes)...)
+		}
+	}
+
+	return changes
+}
+
+// diffEpisodes returns the changes of the episodes of a season
+func diffEpisodes(path string, old, new map[string]EpisodeIframe) []Change {
+	var changes []Change
+
+	for _, key := range unionKeys(old, new) {
+		episodePath := path + "." + key
+		oldEpisode, inOld := old[key]
+		newEpisode, inNew := new[key]
+
+		switch {
+		case !inOld:
+			changes = append(changes, Change{Kind: ChangeEpisodeAdded, Path: episodePath, New: newEpisode})
+		case !inNew:
+			changes = append(changes, Change{Kind: ChangeEpisodeRemoved, Path: episodePath, Old: oldEpisode})
+		default:
+			if oldEpisode.Iframe != newEpisode.Iframe {
+				changes = append(changes, Change{
+					Kind: ChangeFieldChanged, Path: episodePath + ".iframe", Old: oldEpisode.Iframe, New: newEpisode.Iframe,
+				})
+			}
+			changes = append(changes, diffTranslations(episodePath+".translation", oldEpisode.Translation, newEpisode.Translation)...)
+		}
+	}
+
+	return changes
+}
+
+// diffTranslations returns the changes of the translations
+func diffTranslations(path string, old, new map[string]TranslationIframe) []Change {
+	var changes []Change
+
+	for _, key := range unionKeys(old, new) {
+		translationPath := path + "." + key
+		oldTranslation, inOld := old[key]
+		newTranslation, inNew := new[key]
+
+		switch {
+		case !inOld:
+			changes = append(changes, Change{Kind: ChangeTranslationAdded, Path: translationPath, New: newTranslation})
+		case !inNew:
+			changes = append(changes, Change{Kind: ChangeTranslationRemoved, Path: translationPath, Old: oldTranslation})
+		default:
+			fields := []struct {
+				name     string
+				old, new interface{}
+			}{
+				{"name", oldTranslation.Name, newTranslation.Name},
+				{"iframe", oldTranslation.Iframe, newTranslation.Iframe},
+				{"adv", oldTranslation.Adv, newTranslation.Adv},
+				{"date", oldTranslation.Date, newTranslation.Date},
+				{"lgbt", oldTranslation.Lgbt, newTranslation.Lgbt},
+				{"uhd", oldTranslation.Uhd, newTranslation.Uhd},
+			}
+			for _, field := range fields {
+				if field.old != field.new {
+					changes = append(changes, Change{
+						Kind: ChangeFieldChanged, Path: translationPath + "." + field.name, Old: field.old, New: field.new,
+					})
+				}
+			}
+			changes = append(changes, diffQuality(translationPath+".quality", oldTranslation.Quality, newTranslation.Quality)...)
+		}
+	}
+
+	return changes
+}
+
+// unionKeys returns the union of the keys of the maps with the string keys ordered numerically, the non-numeric keys
+// go last
+func unionKeys(old, new interface{}) []string {
+	oldKeys, newKeys := reflect.ValueOf(old).MapKeys(), reflect.ValueOf(new).MapKeys()
+	seen := make(map[string]bool, len(oldKeys)+len(newKeys))
+	keys := make([]string, 0, len(oldKeys)+len(newKeys))
+	for _, key := range append(oldKeys, newKeys...) {
+		if !seen[key.String()] {
+			seen[key.String()] = true
+			keys = append(keys, key.String())
+		}
+	}
+
+	strutil.SortNumeric(keys)
+
+	return keys
+}
+
+// formatChangeValue formats the value of the changed field for the human-readable representation
+func formatChangeValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return strconv.Quote(v)
+	case NullInt32:
+		if !v.Valid {
+			return "null"
+		}
+		return strconv.Itoa(int(v.Int32))
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,83 @@
+package alloha
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// diffSnapshot returns a series snapshot for the diff tests
+func diffSnapshot() *MovieData {
+	return &MovieData{
+		Name:     "Бригада",
+		IDKp:     77044,
+		RatingKp: 8.3,
+		Poster:   "https://example.com/poster.jpg",
+		Quality:  "HDTV",
+		Seasons: map[string]SeasonIframe{
+			"1": {Season: 1, Episodes: map[string]EpisodeIframe{
+				"1": {Episode: 1, Translation: map[string]TranslationIframe{
+					"66": {Name: "Оригинал", Quality: "HDTV"},
+				}},
+				"2": {Episode: 2, Translation: map[string]TranslationIframe{
+					"66": {Name: "Оригинал", Quality: "HDTV"},
+				}},
+			}},
+		},
+		TranslationIframe: map[string]TranslationIframe{
+			"66": {Name: "Оригинал", Quality: "HDTV"},
+		},
+	}
+}
+
+func TestDiff(t *testing.T) {
+	old := diffSnapshot()
+	new := diffSnapshot()
+
+	// Изменяем рейтинг, постер, качество, серии и переводы
+	new.RatingKp = 8.4
+	new.Poster = "https://example.com/poster-new.jpg"
+	new.Quality = "WEB-DL"
+	new.AgeRestrictions = NullInt32{Int32: 18, Valid: true}
+	new.Seasons["1"].Episodes["1"].Translation["66"] = TranslationIframe{Name: "Оригинал", Quality: "WEB-DL"}
+	new.Seasons["1"].Episodes["1"].Translation["10"] = TranslationIframe{Name: "LostFilm"}
+	delete(new.Seasons["1"].Episodes, "2")
+	new.Seasons["1"].Episodes["10"] = EpisodeIframe{Episode: 10}
+	new.Seasons["2"] = SeasonIframe{Season: 2}
+	new.TranslationIframe = map[string]TranslationIframe{"66": {Name: "Оригинал", Quality: "CAMRip"}}
+
+	changes := Diff(old, new)
+
+	// Проверяем результат
+	assert.Equal(t, ""+
+		"field_changed age_restrictions: null -> 18\n"+
+		"rating_changed rating_kp: 8.3 -> 8.4\n"+
+		"poster_changed poster: \"https://example.com/poster.jpg\" -> \"https://example.com/poster-new.jpg\"\n"+
+		"quality_upgrade quality: \"HDTV\" -> \"WEB-DL\"\n"+
+		"translation_added seasons.1.episodes.1.translation.10\n"+
+		"quality_upgrade seasons.1.episodes.1.translation.66.quality: \"HDTV\" -> \"WEB-DL\"\n"+
+		"episode_removed seasons.1.episodes.2\n"+
+		"episode_added seasons.1.episodes.10\n"+
+		"season_added seasons.2\n"+
+		"quality_downgrade translation_iframe.66.quality: \"HDTV\" -> \"CAMRip\"\n",
+		FormatChanges(changes))
+
+	data, err := json.Marshal(changes[:2])
+	require.NoError(t, err)
+	assert.JSONEq(t, `[
+		{"kind":"field_changed","path":"age_restrictions","old":null,"new":18},
+		{"kind":"rating_changed","path":"rating_kp","old":8.3,"new":8.4}
+	]`, string(data))
+}
+
+func TestDiff_Snapshots(t *testing.T) {
+	movie := diffSnapshot()
+
+	// Проверяем результат
+	assert.Empty(t, Diff(movie, diffSnapshot()))
+	assert.Nil(t, Diff(nil, nil))
+	assert.Equal(t, []Change{{Kind: ChangeTitleAdded, New: movie}}, Diff(nil, movie))
+	assert.Equal(t, []Change{{Kind: ChangeTitleRemoved, Old: movie}}, Diff(movie, nil))
+}
@@ -3,22 +3,79 @@ package alloha
 import (
 	"errors"
 	"fmt"
+	"strings"
 )
 
 var (
-	ApiTokenEmptyError              = errors.New("api token is empty")
-	BaseApiUrlEmptyError            = errors.New("base api url is empty")
-	BaseApiUrlInvalidHostError      = errors.New("base api url host is invalid")
-	EmptyEndpointApiURLError        = errors.New("endpoint api url is empty")
-	EmptyIMDbIdParameterError       = errors.New("imdb id param is empty")
-	EmptyHttpMethodError            = errors.New("http method param is empty")
-	EmptyMovieNameParameterError    = errors.New("movie name param is empty")
-	FailedCreateRequestError        = errors.New("failed to create a request object")
-	InvalidKPIdParameterError       = errors.New("kp id param is invalid")
-	InvalidTMDbIdParameterError     = errors.New("tmdb id param is invalid")
-	InvalidPageNumberParameterError = errors.New("page number param is invalid")
+	ApiTokenEmptyError                   = errors.New("api token is empty")
+	BaseApiUrlEmptyError                 = errors.New("base api url is empty")
+	BaseApiUrlInvalidHostError           = errors.New("base api url host is invalid")
+	EmptyEndpointApiURLError             = errors.New("endpoint api url is empty")
+	EmptyIMDbIdParameterError            = errors.New("imdb id param is empty")
+	EmptyHttpResponseError               = errors.New("http response is empty")
+	EmptyHttpMethodError                 = errors.New("http method param is empty")
+	EmptyMovieNameParameterError         = errors.New("movie name param is empty")
+	FailedCreateRequestError             = errors.New("failed to create a request object")
+	InvalidBurstParameterError           = errors.New("burst param is invalid")
+	InvalidContentEncodingParameterError = errors.New("content encoding param is invalid")
+	InvalidDecodeModeParameterError      = errors.New("decode mode param is invalid")
+	InvalidKPIdParameterError            = errors.New("kp id param is invalid")
+	InvalidMatchThresholdParameterError  = errors.New("match threshold param is invalid")
+	InvalidMaxEntriesParameterError      = errors.New("max entries param is invalid")
+	InvalidMaxResponseSizeParameterError = errors.New("max response size param is invalid")
+	InvalidTMDbIdParameterError          = errors.New("tmdb id param is invalid")
+	InvalidPageNumberParameterError      = errors.New("page number param is invalid")
+	InvalidRateLimitParameterError       = errors.New("rate limit param is invalid")
+	NilCallbackParameterError            = errors.New("callback param is nil")
+	NilContentDecoderParameterError      = errors.New("content decoder param is nil")
 )
 
+// ErrorInfoNotFound is the error information of the API responses when the movie is not found
+const ErrorInfoNotFound = "not movie"
+
+// APIError represents an error reported by the API in the response body with the "error" status
+type APIError struct {
+	ErrorInfo string
+}
+
+// Error implements the error interface
+func (e *APIError) Error() string {
+	return fmt.Sprintf("api responded with an error: %s", e.ErrorInfo)
+}
+
+// NotFound reports whether the API did not find the requested movie
+func (e *APIError) NotFound() bool {
+	return e.ErrorInfo == ErrorInfoNotFound
+}
+
+// IsNotFound reports whether the error means that the requested movie does not exist: the API responded with
+// the ErrorInfoNotFound error information or with the 404 status code
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	var statusCodeErr *UnexpectedStatusCodeError
+
+	switch {
+	case errors.As(err, &apiErr):
+		return apiErr.NotFound()
+	case errors.As(err, &statusCodeErr):
+		return statusCodeErr.StatusCode == 404
+	default:
+		return false
+	}
+}
+
+// AmbiguousMatchError represents an error when the confidence of the best matching title is below the threshold
+type AmbiguousMatchError struct {
+	// Result with the best candidate and the runner-ups
+	Result    *MatchResult
+	Threshold float64
+}
+
+// Error implements the error interface
+func (e *AmbiguousMatchError) Error() string {
+	return fmt.Sprintf("ambiguous title match: confidence %.2f is below the threshold %.2f", e.Result.Confidence, e.Threshold)
+}
+
 // EmptyResponseBodyError represents an error when the response body is empty
 type EmptyResponseBodyError struct {
 	StatusCode int
@@ -28,3 +85,48 @@ type EmptyResponseBodyError struct {
 func (e *EmptyResponseBodyError) Error() string {
 	return fmt.Sprintf("empty request response body, with StatusCode: %d", e.StatusCode)
 }
+
+// ResponseTooLargeError represents an error when the response body exceeds the maximum response size
+type ResponseTooLargeError struct {
+	Limit int64
+}
+
+// Error implements the error interface
+func (e *ResponseTooLargeError) Error() string {
+	return fmt.Sprintf("response body exceeds the maximum size of %d bytes", e.Limit)
+}
+
+// UnexpectedStatusCodeError represents an error when the server responds with a status code other than 200
+type UnexpectedStatusCodeError struct {
+	StatusCode int
+}
+
+// Error implements the error interface
+func (e *UnexpectedStatusCodeError) Error() string {
+	return fmt.Sprintf("unexpected server response with a status code: %d", e.StatusCode)
+}
+
+// UnsupportedContentEncodingError represents an error when the response is encoded with an unregistered content coding
+type UnsupportedContentEncodingError struct {
+	Encoding string
+}
+
+// Error implements the error interface
+func (e *UnsupportedContentEncodingError) Error() string {
+	return fmt.Sprintf("unsupported response content encoding: %s", e.Encoding)
+}
+
+// StrictDecodeError represents an error when the response does not match the SDK types in the strict decode mode
+type StrictDecodeError struct {
+	Issues []*DecodeIssue
+}
+
+// Error implements the error interface
+func (e *StrictDecodeError) Error() string {
+	messages := make([]string, 0, len(e.Issues))
+	for _, issue := range e.Issues {
+		messages = append(messages, issue.String())
+	}
+
+	return fmt.Sprintf("response does not match the sdk types: %s", strings.Join(messages, "; "))
+}
@@ -0,0 +1,386 @@
+package alloha
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// lenientMovieData, lenientMovieSearchData and lenientSeriesData decode the data without the lenient UnmarshalJSON
+// methods to avoid the recursion
+type (
+	lenientMovieData       MovieData
+	lenientMovieSearchData MovieSearchData
+	lenientSeriesData      SeriesData
+)
+
+// UnmarshalJSON implements the json.Unmarshaler interface and tolerates inconsistently typed fields. The coerced and
+// dropped values are recorded by the response, see FindOneResponse.Anomalies.
+func (m *MovieData) UnmarshalJSON(b []byte) error {
+	_, err := decodeLenient(b, (*lenientMovieData)(m))
+
+	return err
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface and tolerates inconsistently typed fields. The coerced and
+// dropped values are recorded by the response, see FindListResponse.Anomalies.
+func (m *MovieSearchData) UnmarshalJSON(b []byte) error {
+	_, err := decodeLenient(b, (*lenientMovieSearchData)(m))
+
+	return err
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface and tolerates inconsistently typed fields. The coerced and
+// dropped values are recorded by the response, see ListOfLatestSeriesResponse.Anomalies.
+func (s *SeriesData) UnmarshalJSON(b []byte) error {
+	_, err := decodeLenient(b, (*lenientSeriesData)(s))
+
+	return err
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface and records the anomalies of the movie data
+func (r *FindOneResponse) UnmarshalJSON(b []byte) error {
+	type findOneResponse FindOneResponse
+
+	response := struct {
+		*findOneResponse
+		Data json.RawMessage `json:"data"`
+	}{findOneResponse: (*findOneResponse)(r)}
+	if err := json.Unmarshal(b, &response); err != nil {
+		return err
+	}
+
+	r.Data, r.anomalies = nil, nil
+	if isJSONNull(response.Data) {
+		return nil
+	}
+
+	r.Data = &MovieData{}
+	anomalies, err := decodeLenient(response.Data, (*lenientMovieData)(r.Data))
+	if err != nil {
+		return err
+	}
+	r.anomalies = prefixIssues("$.data", anomalies)
+
+	return nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface and records the anomalies of the found movies
+func (r *FindListResponse) UnmarshalJSON(b []byte) error {
+	type findListResponse FindListResponse
+
+	response := struct {
+		*findListResponse
+		Data []json.RawMessage `json:"data"`
+	}{findListResponse: (*findListResponse)(r)}
+	if err := json.Unmarshal(b, &response); err != nil {
+		return err
+	}
+
+	r.Data, r.anomalies = nil, nil
+	if response.Data == nil {
+		return nil
+	}
+
+	r.Data = make([]*MovieSearchData, len(response.Data))
+	for i, item := range response.Data {
+		if isJSONNull(item) {
+			continue
+		}
+
+		r.Data[i] = &MovieSearchData{}
+		anomalies, err := decodeLenient(item, (*lenientMovieSearchData)(r.Data[i]))
+		if err != nil {
+			return err
+		}
+		r.anomalies = append(r.anomalies, prefixIssues(fmt.Sprintf("$.data[%d]", i), anomalies)...)
+	}
+
+	return nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface and records the anomalies of the series
+func (r *ListOfLatestSeriesResponse) UnmarshalJSON(b []byte) error {
+	type listOfLatestSeriesResponse ListOfLatestSeriesResponse
+
+	response := struct {
+		*listOfLatestSeriesResponse
+		Data []json.RawMessage `json:"data"`
+	}{listOfLatestSeriesResponse: (*listOfLatestSeriesResponse)(r)}
+	if err := json.Unmarshal(b, &response); err != nil {
+		return err
+	}
+
+	r.Data, r.anomalies = nil, nil
+	if response.Data == nil {
+		return nil
+	}
+
+	r.Data = make([]*SeriesData, len(response.Data))
+	for i, item := range response.Data {
+		if isJSONNull(item) {
+			continue
+		}
+
+		r.Data[i] = &SeriesData{}
+		anomalies, err := decodeLenient(item, (*lenientSeriesData)(r.Data[i]))
+		if err != nil {
+			return err
+		}
+		r.anomalies = append(r.anomalies, prefixIssues(fmt.Sprintf("$.data[%d]", i), anomalies)...)
+	}
+
+	return nil
+}
+
+// isJSONNull reports whether the raw value is missing or null
+func isJSONNull(raw json.RawMessage) bool {
+	raw = bytes.TrimSpace(raw)
+
+	return len(raw) == 0 || bytes.Equal(raw, []byte("null"))
+}
+
+// prefixIssues returns copies of the issues with the paths moved under the specified prefix
+func prefixIssues(prefix string, issues []*DecodeIssue) []*DecodeIssue {
+	if len(issues) == 0 {
+		return nil
+	}
+
+	prefixed := make([]*DecodeIssue, 0, len(issues))
+	for _, issue := range issues {
+		moved := *issue
+		moved.Path = prefix + strings.TrimPrefix(issue.Path, "$")
+		prefixed = append(prefixed, &moved)
+	}
+
+	return prefixed
+}
+
+// decodeLenient decodes the JSON document into v. If the document does not match the types of v, the values are
+// coerced to the expected types where possible, and every coerced or dropped value is returned as an anomaly.
+func decodeLenient(data []byte, v interface{}) ([]*DecodeIssue, error) {
+	if err := json.Unmarshal(data, v); err == nil {
+		return nil, nil
+	}
+
+	var value interface{}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	dst := reflect.ValueOf(v).Elem()
+	dst.Set(reflect.Zero(dst.Type()))
+
+	var anomalies []*DecodeIssue
+	coerceValue("$", value, dst, &anomalies)
+
+	return anomalies, nil
+}
+
+// coerceValue recursively stores the decoded JSON value into dst, coercing it to the dst type if needed
+func coerceValue(path string, value interface{}, dst reflect.Value, anomalies *[]*DecodeIssue) {
+	// null leaves the value untouched, the same way json.Unmarshal does
+	if value == nil {
+		return
+	}
+
+	if dst.Kind() == reflect.Ptr {
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		coerceValue(path, value, dst.Elem(), anomalies)
+		return
+	}
+
+	addAnomaly := func(dropped bool) {
+		*anomalies = append(*anomalies, &DecodeIssue{
+			Kind:     DecodeIssueTypeMismatch,
+			Path:     path,
+			Expected: jsonTypeOf(dst.Type()),
+			Actual:   jsonKindOf(value),
+			Dropped:  dropped,
+		})
+	}
+
+	if dst.Type() == nullInt32Type {
+		number, ok, exact := coerceInt(value)
+		if ok {
+			dst.Set(reflect.ValueOf(NullInt32{Int32: int32(number), Valid: true}))
+		}
+		if !exact {
+			addAnomaly(!ok)
+		}
+		return
+	}
+
+	switch dst.Kind() {
+	case reflect.Struct:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			addAnomaly(true)
+			return
+		}
+
+		for _, key := range sortedKeys(object) {
+			field, found := jsonField(dst.Type(), key)
+			if found {
+				coerceValue(path+"."+key, object[key], dst.FieldByIndex(field.Index), anomalies)
+			}
+		}
+	case reflect.Map:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			// PHP encodes empty and sequentially indexed associative arrays as JSON arrays
+			array, isArray := value.([]interface{})
+			if !isArray {
+				addAnomaly(true)
+				return
+			}
+
+			object = make(map[string]interface{}, len(array))
+			for i, item := range array {
+				object[strconv.Itoa(i)] = item
+			}
+			addAnomaly(false)
+		}
+
+		if dst.IsNil() {
+			dst.Set(reflect.MakeMapWithSize(dst.Type(), len(object)))
+		}
+		for _, key := range sortedKeys(object) {
+			elem := reflect.New(dst.Type().Elem()).Elem()
+			coerceValue(path+"."+key, object[key], elem, anomalies)
+			dst.SetMapIndex(reflect.ValueOf(key).Convert(dst.Type().Key()), elem)
+		}
+	case reflect.Slice:
+		array, ok := value.([]interface{})
+		if !ok {
+			addAnomaly(true)
+			return
+		}
+
+		slice := reflect.MakeSlice(dst.Type(), len(array), len(array))
+		for i, item := range array {
+			coerceValue(fmt.Sprintf("%s[%d]", path, i), item, slice.Index(i), anomalies)
+		}
+		dst.Set(slice)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		number, ok, exact := coerceInt(value)
+		if ok {
+			dst.SetInt(number)
+		}
+		if !exact {
+			addAnomaly(!ok)
+		}
+	case reflect.Float32, reflect.Float64:
+		number, ok, exact := coerceFloat(value)
+		if ok {
+			dst.SetFloat(number)
+		}
+		if !exact {
+			addAnomaly(!ok)
+		}
+	case reflect.String:
+		switch v := value.(type) {
+		case string:
+			dst.SetString(v)
+		case json.Number:
+			dst.SetString(v.String())
+			addAnomaly(false)
+		case bool:
+			dst.SetString(strconv.FormatBool(v))
+			addAnomaly(false)
+		default:
+			addAnomaly(true)
+		}
+	case reflect.Bool:
+		flag, ok, exact := coerceBool(value)
+		if ok {
+			dst.SetBool(flag)
+		}
+		if !exact {
+			addAnomaly(!ok)
+		}
+	case reflect.Interface:
+		dst.Set(reflect.ValueOf(value))
+	default:
+		addAnomaly(true)
+	}
+}
+
+// coerceInt converts the decoded JSON value to an integer. It returns whether the conversion succeeded and whether
+// the value already was a JSON integer.
+func coerceInt(value interface{}) (int64, bool, bool) {
+	switch v := value.(type) {
+	case json.Number:
+		if number, err := v.Int64(); err == nil {
+			return number, true, true
+		}
+		if number, err := v.Float64(); err == nil && number == math.Trunc(number) {
+			return int64(number), true, false
+		}
+	case string:
+		text := strings.TrimSpace(v)
+		if number, err := strconv.ParseInt(text, 10, 64); err == nil {
+			return number, true, false
+		}
+		if number, err := strconv.ParseFloat(text, 64); err == nil && number == math.Trunc(number) {
+			return int64(number), true, false
+		}
+	case bool:
+		if v {
+			return 1, true, false
+		}
+		return 0, true, false
+	}
+
+	return 0, false, false
+}
+
+// coerceFloat converts the decoded JSON value to a float. It returns whether the conversion succeeded and whether
+// the value already was a JSON number.
+func coerceFloat(value interface{}) (float64, bool, bool) {
+	switch v := value.(type) {
+	case json.Number:
+		if number, err := v.Float64(); err == nil {
+			return number, true, true
+		}
+	case string:
+		text := strings.Replace(strings.TrimSpace(v), ",", ".", 1)
+		if number, err := strconv.ParseFloat(text, 64); err == nil {
+			return number, true, false
+		}
+	}
+
+	return 0, false, false
+}
+
+// coerceBool converts the decoded JSON value to a boolean. It returns whether the conversion succeeded and whether
+// the value already was a JSON boolean.
+func coerceBool(value interface{}) (bool, bool, bool) {
+	switch v := value.(type) {
+	case bool:
+		return v, true, true
+	case json.Number:
+		number, err := v.Float64()
+		if err == nil {
+			return number != 0, true, false
+		}
+	case string:
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "1", "true", "yes":
+			return true, true, false
+		case "", "0", "false", "no":
+			return false, true, false
+		}
+	}
+
+	return false, false, false
+}
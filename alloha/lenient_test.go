@@ -0,0 +1,274 @@
+package alloha
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMovieData_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name              string
+		body              string
+		expected          func(t *testing.T, movie *MovieData)
+		expectedAnomalies []string
+	}{
+		{
+			name: "consistently typed fields",
+			body: "{\"name\":\"Бригада\",\"year\":2002,\"id_kp\":77044,\"id_tmdb\":4561,\"rating_kp\":8.3,\"seasons\":{}}",
+			expected: func(t *testing.T, movie *MovieData) {
+				assert.Equal(t, 2002, movie.Year)
+				assert.Equal(t, int32(4561), movie.IDTmdb.Int32)
+			},
+			expectedAnomalies: nil,
+		},
+		{
+			name: "numbers as strings",
+			body: "{\"name\":\"Бригада\",\"year\":\"2002\",\"id_kp\":\"77044\",\"id_tmdb\":\"4561\",\"rating_kp\":\"8.3\"}",
+			expected: func(t *testing.T, movie *MovieData) {
+				assert.Equal(t, "Бригада", movie.Name)
+				assert.Equal(t, 2002, movie.Year)
+				assert.Equal(t, 77044, movie.IDKp)
+				assert.True(t, movie.IDTmdb.Valid)
+				assert.Equal(t, int32(4561), movie.IDTmdb.Int32)
+				assert.Equal(t, 8.3, movie.RatingKp)
+			},
+			expectedAnomalies: []string{
+				"$.id_kp: type mismatch, expected integer, got string",
+				"$.id_tmdb: type mismatch, expected integer, got string",
+				"$.rating_kp: type mismatch, expected number, got string",
+				"$.year: type mismatch, expected integer, got string",
+			},
+		},
+		{
+			name: "empty string ratings",
+			body: "{\"name\":\"Пульс\",\"rating_kp\":\"\",\"rating_imdb\":\"\",\"age_restrictions\":\"\"}",
+			expected: func(t *testing.T, movie *MovieData) {
+				assert.Equal(t, "Пульс", movie.Name)
+				assert.Zero(t, movie.RatingKp)
+				assert.Zero(t, movie.RatingImdb)
+				assert.False(t, movie.AgeRestrictions.Valid)
+			},
+			expectedAnomalies: []string{
+				"$.age_restrictions: type mismatch, expected integer, got string, value dropped",
+				"$.rating_imdb: type mismatch, expected number, got string, value dropped",
+				"$.rating_kp: type mismatch, expected number, got string, value dropped",
+			},
+		},
+		{
+			name: "seasons as an empty array",
+			body: "{\"name\":\"Криминальное чтиво\",\"id_kp\":342,\"seasons\":[],\"translation_iframe\":[]}",
+			expected: func(t *testing.T, movie *MovieData) {
+				assert.Equal(t, 342, movie.IDKp)
+				assert.NotNil(t, movie.Seasons)
+				assert.Empty(t, movie.Seasons)
+				assert.Empty(t, movie.TranslationIframe)
+			},
+			expectedAnomalies: []string{
+				"$.seasons: type mismatch, expected object, got array",
+				"$.translation_iframe: type mismatch, expected object, got array",
+			},
+		},
+		{
+			name: "nested episodes as an indexed array",
+			body: "{\"id_kp\":1236630,\"seasons\":{\"1\":{\"season\":\"1\",\"episodes\":[{\"episode\":1,\"iframe\":\"https://example.com/1\"}]}}}",
+			expected: func(t *testing.T, movie *MovieData) {
+				assert.Equal(t, 1, movie.Seasons["1"].Season)
+				assert.Equal(t, "https://example.com/1", movie.Seasons["1"].Episodes["0"].Iframe)
+			},
+			expectedAnomalies: []string{
+				"$.seasons.1.episodes: type mismatch, expected object, got array",
+				"$.seasons.1.season: type mismatch, expected integer, got string",
+			},
+		},
+		{
+			name: "booleans as numbers and strings as numbers",
+			body: "{\"id_kp\":342,\"lgbt\":0,\"uhd\":\"1\",\"id_imdb\":110912,\"time\":154}",
+			expected: func(t *testing.T, movie *MovieData) {
+				assert.False(t, movie.Lgbt)
+				assert.True(t, movie.Uhd)
+				assert.Equal(t, "110912", movie.IDImdb)
+				assert.Equal(t, "154", movie.Time)
+			},
+			expectedAnomalies: []string{
+				"$.id_imdb: type mismatch, expected string, got integer",
+				"$.lgbt: type mismatch, expected boolean, got integer",
+				"$.time: type mismatch, expected string, got integer",
+				"$.uhd: type mismatch, expected boolean, got string",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			movie := &MovieData{}
+
+			err := json.Unmarshal([]byte(tt.body), movie)
+			anomalies, errLenient := decodeLenient([]byte(tt.body), (*lenientMovieData)(&MovieData{}))
+
+			assert.NoError(t, err)
+			assert.NoError(t, errLenient)
+			tt.expected(t, movie)
+			assert.Equal(t, tt.expectedAnomalies, issueStrings(anomalies))
+		})
+	}
+}
+
+func TestMovieSearchData_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name              string
+		body              string
+		expectedAnomalies []string
+	}{
+		{
+			name:              "consistently typed fields",
+			body:              "{\"last_season\":2,\"last_episode\":8,\"name\":\"Бригада\",\"year\":2002,\"category_id\":2}",
+			expectedAnomalies: nil,
+		},
+		{
+			name: "last season and episode as strings",
+			body: "{\"last_season\":\"2\",\"last_episode\":\"8\",\"name\":\"Бригада\",\"year\":2002,\"category_id\":\"2\"}",
+			expectedAnomalies: []string{
+				"$.category_id: type mismatch, expected integer, got string",
+				"$.last_episode: type mismatch, expected integer, got string",
+				"$.last_season: type mismatch, expected integer, got string",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			movie := &MovieSearchData{}
+
+			err := json.Unmarshal([]byte(tt.body), movie)
+			anomalies, errLenient := decodeLenient([]byte(tt.body), (*lenientMovieSearchData)(&MovieSearchData{}))
+
+			assert.NoError(t, err)
+			assert.NoError(t, errLenient)
+			assert.Equal(t, "Бригада", movie.Name)
+			assert.Equal(t, int32(2), movie.LastSeason.Int32)
+			assert.Equal(t, int32(8), movie.LastEpisode.Int32)
+			assert.Equal(t, 2, movie.CategoryId)
+			assert.Equal(t, tt.expectedAnomalies, issueStrings(anomalies))
+		})
+	}
+}
+
+func TestSeriesData_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name              string
+		body              string
+		expectedAnomalies []string
+	}{
+		{
+			name:              "consistently typed fields",
+			body:              "{\"season\":1,\"episode\":10,\"translation\":96,\"name\":\"Пульс\",\"id_kp\":5600611,\"adv\":false}",
+			expectedAnomalies: nil,
+		},
+		{
+			name: "numbers as strings",
+			body: "{\"season\":\"1\",\"episode\":\"10\",\"translation\":\"96\",\"name\":\"Пульс\",\"id_kp\":\"5600611\",\"adv\":\"\"}",
+			expectedAnomalies: []string{
+				"$.adv: type mismatch, expected boolean, got string",
+				"$.episode: type mismatch, expected integer, got string",
+				"$.id_kp: type mismatch, expected integer, got string",
+				"$.season: type mismatch, expected integer, got string",
+				"$.translation: type mismatch, expected integer, got string",
+			},
+		},
+		{
+			name: "unparsable values are dropped",
+			body: "{\"season\":1,\"episode\":\"10-11\",\"translation\":96,\"name\":\"Пульс\",\"id_kp\":5600611,\"adv\":{}}",
+			expectedAnomalies: []string{
+				"$.adv: type mismatch, expected boolean, got object, value dropped",
+				"$.episode: type mismatch, expected integer, got string, value dropped",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			series := &SeriesData{}
+
+			err := json.Unmarshal([]byte(tt.body), series)
+			anomalies, errLenient := decodeLenient([]byte(tt.body), (*lenientSeriesData)(&SeriesData{}))
+
+			assert.NoError(t, err)
+			assert.NoError(t, errLenient)
+			assert.Equal(t, 1, series.Season)
+			assert.Equal(t, 96, series.Translation)
+			assert.Equal(t, "Пульс", series.Name)
+			assert.Equal(t, 5600611, series.IDKp)
+			assert.False(t, series.Adv)
+			assert.Equal(t, tt.expectedAnomalies, issueStrings(anomalies))
+		})
+	}
+}
+
+func TestAPIClient_FindByKPId_LenientDecoding(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Возвращаем тестовые данные
+		w.WriteHeader(http.StatusOK)
+		_, errWrite := io.WriteString(w, "{\"status\":\"success\",\"data\":{\"name\":\"Бригада\",\"year\":\"2002\",\"id_kp\":\"77044\",\"rating_imdb\":\"\",\"seasons\":[]}}")
+		if errWrite != nil {
+			t.Errorf("failed to write data to response: %v", errWrite)
+		}
+	}))
+	defer ts.Close()
+
+	// Создаем клиент с тестовым сервером
+	client, err := NewAPIClient(ts.Client(), "test-api-key", ts.URL)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	movie, errMovie := client.FindByKPId(t.Context(), 77044)
+
+	// Проверяем результат
+	assert.Nil(t, errMovie)
+
+	assert.Equal(t, "Бригада", movie.Data.Name)
+	assert.Equal(t, 2002, movie.Data.Year)
+	assert.Equal(t, 77044, movie.Data.IDKp)
+	assert.Equal(t, []string{
+		"$.data.id_kp: type mismatch, expected integer, got string",
+		"$.data.rating_imdb: type mismatch, expected number, got string, value dropped",
+		"$.data.seasons: type mismatch, expected object, got array",
+		"$.data.year: type mismatch, expected integer, got string",
+	}, issueStrings(movie.Anomalies()))
+}
+
+func TestListOfLatestSeriesResponse_UnmarshalJSON(t *testing.T) {
+	body := "{\"status\":\"success\",\"data\":[{\"season\":\"1\",\"episode\":10,\"name\":\"Пульс\"},null],\"next_page\":2}"
+
+	response := &ListOfLatestSeriesResponse{}
+	err := json.Unmarshal([]byte(body), response)
+
+	// Проверяем результат
+	assert.NoError(t, err)
+	assert.Equal(t, "success", response.Status)
+	assert.Equal(t, int32(2), response.NextPage.Int32)
+
+	// Проверяем, что декодированные данные равны построенным вручную, а пустой элемент списка сохранен
+	assert.Equal(t, []*SeriesData{{Season: 1, Episode: 10, Name: "Пульс"}, nil}, response.Data)
+	assert.True(t, *response.Data[0] == SeriesData{Season: 1, Episode: 10, Name: "Пульс"})
+
+	// Проверяем, что аномалии записаны в ответ с путями относительно ответа
+	assert.Equal(t, []string{
+		"$.data[0].season: type mismatch, expected integer, got string",
+	}, issueStrings(response.Anomalies()))
+}
+
+// issueStrings returns the string representations of the decode issues
+func issueStrings(issues []*DecodeIssue) []string {
+	var result []string
+	for _, issue := range issues {
+		result = append(result, issue.String())
+	}
+
+	return result
+}
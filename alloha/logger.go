@@ -0,0 +1,146 @@
+package alloha
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// LogLevel defines the importance of a log record, the values match the log/slog levels
+type LogLevel int
+
+const (
+	LogLevelDebug LogLevel = -4
+	LogLevelInfo  LogLevel = 0
+	LogLevelWarn  LogLevel = 4
+	LogLevelError LogLevel = 8
+)
+
+// String implements the fmt.Stringer interface
+func (l LogLevel) String() string {
+	switch l {
+	case LogLevelDebug:
+		return "DEBUG"
+	case LogLevelInfo:
+		return "INFO"
+	case LogLevelWarn:
+		return "WARN"
+	case LogLevelError:
+		return "ERROR"
+	default:
+		return fmt.Sprintf("LogLevel(%d)", int(l))
+	}
+}
+
+// Logger provides an interface for structured logging compatible with log/slog. The args are alternating keys and
+// values, the same as in slog.Logger.Log.
+type Logger interface {
+	Enabled(ctx context.Context, level LogLevel) bool
+	Log(ctx context.Context, level LogLevel, msg string, args ...interface{})
+}
+
+// NopLogger is a Logger that discards all records, it is used by default
+type NopLogger struct{}
+
+// Enabled implements the Logger interface
+func (NopLogger) Enabled(ctx context.Context, level LogLevel) bool {
+	return false
+}
+
+// Log implements the Logger interface
+func (NopLogger) Log(ctx context.Context, level LogLevel, msg string, args ...interface{}) {}
+
+//region - Public Methods
+
+// SetLogger sets the logger that receives the SDK events. Passing nil disables logging.
+func (c *APIClient) SetLogger(logger Logger) {
+	if logger == nil {
+		logger = NopLogger{}
+	}
+
+	c.logger = logger
+}
+
+// SetLogLevel sets the minimum level of the events passed to the logger
+func (c *APIClient) SetLogLevel(level LogLevel) {
+	c.logLevel = level
+}
+
+//endregion
+
+//region - Private Methods
+
+// log passes the record to the logger if the level is enabled
+func (c *APIClient) log(ctx context.Context, level LogLevel, msg string, args ...interface{}) {
+	if level < c.logLevel || !c.logger.Enabled(ctx, level) {
+		return
+	}
+
+	c.logger.Log(ctx, level, msg, args...)
+}
+
+// logAttempt logs a single attempt of the call to the HttpClient
+func (c *APIClient) logAttempt(call *Call, resp *http.Response, err error, duration time.Duration) {
+	ctx := call.Request.Context()
+
+	if err != nil {
+		c.log(ctx, LogLevelDebug, "alloha api attempt failed",
+			"operation", call.Operation,
+			"url", redactURL(call.Request.URL),
+			"attempt", call.Attempt,
+			"duration", duration,
+			"error", err.Error(),
+		)
+		return
+	}
+
+	c.log(ctx, LogLevelDebug, "alloha api attempt",
+		"operation", call.Operation,
+		"url", redactURL(call.Request.URL),
+		"attempt", call.Attempt,
+		"status", resp.StatusCode,
+		"duration", duration,
+	)
+}
+
+// logRequest logs the result of the whole API request
+func (c *APIClient) logRequest(ctx context.Context, request *apiRequest, err error) {
+	args := []interface{}{
+		"operation", request.operation,
+		"url", request.url,
+		"status", request.statusCode,
+		"duration", time.Since(request.started),
+		"bytes", request.bytes(),
+		"wire_bytes", request.wireBytes(),
+		"attempts", request.attempts(),
+	}
+
+	if err != nil {
+		c.log(ctx, LogLevelWarn, "alloha api request failed", append(args, "error", err.Error())...)
+		return
+	}
+
+	c.log(ctx, LogLevelDebug, "alloha api request", args...)
+}
+
+// redactURL returns the URL with the API token replaced, so that it can be logged safely
+func redactURL(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+
+	queryValues := u.Query()
+	if _, found := queryValues["token"]; !found {
+		return u.String()
+	}
+
+	redacted := *u
+	queryValues.Set("token", "REDACTED")
+	redacted.RawQuery = queryValues.Encode()
+
+	return redacted.String()
+}
+
+//endregion
@@ -0,0 +1,148 @@
+package alloha
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// logRecord represents a record received by the recordingLogger
+type logRecord struct {
+	level LogLevel
+	msg   string
+	attrs map[string]interface{}
+}
+
+// recordingLogger is a Logger that keeps all records in memory
+type recordingLogger struct {
+	records []logRecord
+}
+
+// Enabled implements the Logger interface
+func (l *recordingLogger) Enabled(ctx context.Context, level LogLevel) bool {
+	return true
+}
+
+// Log implements the Logger interface
+func (l *recordingLogger) Log(ctx context.Context, level LogLevel, msg string, args ...interface{}) {
+	attrs := make(map[string]interface{})
+	for i := 0; i+1 < len(args); i += 2 {
+		attrs[args[i].(string)] = args[i+1]
+	}
+
+	l.records = append(l.records, logRecord{level: level, msg: msg, attrs: attrs})
+}
+
+func TestAPIClient_SetLogger(t *testing.T) {
+	const body = "{\"status\":\"success\",\"data\":{\"name\":\"Бригада\",\"id_kp\":77044}}"
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Возвращаем тестовые данные
+		w.WriteHeader(http.StatusOK)
+		_, errWrite := io.WriteString(w, body)
+		if errWrite != nil {
+			t.Errorf("failed to write data to response: %v", errWrite)
+		}
+	}))
+	defer ts.Close()
+
+	// Создаем клиент с тестовым сервером
+	client, err := NewAPIClient(ts.Client(), "secret-api-key", ts.URL)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	logger := &recordingLogger{}
+	client.SetLogger(logger)
+
+	_, errMovie := client.FindByKPId(t.Context(), 77044)
+
+	// Проверяем результат
+	assert.NoError(t, errMovie)
+	if assert.Len(t, logger.records, 2) {
+		attempt := logger.records[0]
+		assert.Equal(t, LogLevelDebug, attempt.level)
+		assert.Equal(t, "alloha api attempt", attempt.msg)
+		assert.Equal(t, 1, attempt.attrs["attempt"])
+		assert.Equal(t, http.StatusOK, attempt.attrs["status"])
+
+		request := logger.records[1]
+		assert.Equal(t, LogLevelDebug, request.level)
+		assert.Equal(t, "alloha api request", request.msg)
+		assert.Equal(t, OperationFindByKPId, request.attrs["operation"])
+		assert.Equal(t, ts.URL+"/?kp=77044&token=REDACTED", request.attrs["url"])
+		assert.Equal(t, http.StatusOK, request.attrs["status"])
+		assert.Equal(t, int64(len(body)), request.attrs["bytes"])
+		assert.Equal(t, 1, request.attrs["attempts"])
+		assert.Contains(t, request.attrs, "duration")
+	}
+
+	for _, record := range logger.records {
+		assert.NotContains(t, record.attrs["url"], "secret-api-key")
+	}
+}
+
+func TestAPIClient_SetLogLevel(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	// Создаем клиент с тестовым сервером
+	client, err := NewAPIClient(ts.Client(), "test-api-key", ts.URL)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	logger := &recordingLogger{}
+	client.SetLogger(logger)
+	client.SetLogLevel(LogLevelWarn)
+
+	_, errMovie := client.FindByTMDbId(t.Context(), 680)
+
+	// Проверяем результат
+	assert.Error(t, errMovie)
+	if assert.Len(t, logger.records, 1) {
+		assert.Equal(t, LogLevelWarn, logger.records[0].level)
+		assert.Equal(t, "alloha api request failed", logger.records[0].msg)
+		assert.Equal(t, "unexpected server response with a status code: 500", logger.records[0].attrs["error"])
+	}
+
+	client.SetLogger(nil)
+	_, errMovie = client.FindByTMDbId(t.Context(), 680)
+	assert.Error(t, errMovie)
+	assert.Len(t, logger.records, 1)
+}
+
+func Test_redactURL(t *testing.T) {
+	tests := []struct {
+		name        string
+		rawURL      string
+		expectedURL string
+	}{
+		{
+			name:        "url with token",
+			rawURL:      "https://example.com/?kp=342&token=secret",
+			expectedURL: "https://example.com/?kp=342&token=REDACTED",
+		},
+		{
+			name:        "url without token",
+			rawURL:      "https://example.com/?kp=342",
+			expectedURL: "https://example.com/?kp=342",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsedURL, err := url.Parse(tt.rawURL)
+			assert.NoError(t, err)
+
+			assert.Equal(t, tt.expectedURL, redactURL(parsedURL))
+		})
+	}
+}
@@ -0,0 +1,235 @@
+package alloha
+
+import (
+	"context"
+	"math"
+	"sort"
+)
+
+// Default Matcher settings
+const (
+	DefaultMatchThreshold = 0.6
+	MaxMatchRunnerUps     = 5
+)
+
+// Weights of the match score components
+const (
+	matchWeightName         = 0.6
+	matchWeightOriginalName = 0.2
+	matchWeightYear         = 0.25
+	matchWeightCategory     = 0.15
+	// matchMargin is the score margin over the runner-up that gives the full confidence
+	matchMargin = 0.2
+)
+
+// MatchQuery describes the searched title, only the name is required
+type MatchQuery struct {
+	Name string
+	// Year of the title, the adjacent years match partially because of the different premiere dates
+	Year int
+	// Category ID of the title
+	Category     int
+	OriginalName string
+}
+
+// MatchCandidate is a search result scored against the query
+type MatchCandidate struct {
+	Movie *MovieSearchData
+	// Score of the agreement with the query from 0 to 1
+	Score float64
+}
+
+// MatchResult is the best matching title
+type MatchResult struct {
+	Best *MatchCandidate
+	// Confidence of the match from 0 to 1, the score of the best candidate reduced when the runner-up scores close to it
+	Confidence float64
+	// Next best candidates ordered by the score, MaxMatchRunnerUps at most
+	RunnerUps []*MatchCandidate
+}
+
+// Matcher resolves the title by the name, the year and the category
+type Matcher struct {
+	api       API
+	threshold float64
+}
+
+//region - Constructor
+
+// NewMatcher creates a new Matcher instance with the DefaultMatchThreshold
+func NewMatcher(api API) *Matcher {
+	return &Matcher{api: api, threshold: DefaultMatchThreshold}
+}
+
+//endregion
+
+//region - Public Methods
+
+// SetThreshold sets the minimum confidence of the match, from 0 to 1
+func (m *Matcher) SetThreshold(threshold float64) error {
+	if threshold < 0 || threshold > 1 || math.IsNaN(threshold) {
+		return InvalidMatchThresholdParameterError
+	}
+
+	m.threshold = threshold
+	return nil
+}
+
+// MatchTitle searches the titles by the name, the original name and their variants returned by QueryVariants, scores
+// every candidate by the similarity of the names and the agreement of the year and the category, and returns the best
+// one. The not found error is returned only if neither name is found. It returns *AmbiguousMatchError with the result
+// if the confidence is below the threshold.
+func (m *Matcher) MatchTitle(ctx context.Context, query MatchQuery) (*MatchResult, error) {
+	names := []string{query.Name}
+	if len(query.OriginalName) > 0 && NormalizeName(query.OriginalName) != NormalizeName(query.Name) {
+		names = append(names, query.OriginalName)
+	}
+
+	var movies []*MovieSearchData
+	searched := false
+	for _, name := range names {
+		if len(QueryVariants(name)) <= 0 {
+			continue
+		}
+		searched = true
+
+		response, err := SearchVariants(ctx, m.api, name)
+		if IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		movies = append(movies, response.Data...)
+	}
+	if !searched {
+		return nil, EmptyMovieNameParameterError
+	}
+
+	seen := make(map[int]bool, len(movies))
+	candidates := make([]*MatchCandidate, 0, len(movies))
+	for _, movie := range movies {
+		if movie == nil || seen[movie.IDKp] {
+			continue
+		}
+		seen[movie.IDKp] = true
+		candidates = append(candidates, &MatchCandidate{Movie: movie, Score: matchScore(&query, movie)})
+	}
+	if len(candidates) <= 0 {
+		return nil, &APIError{ErrorInfo: ErrorInfoNotFound}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+
+	result := &MatchResult{Best: candidates[0], Confidence: candidates[0].Score}
+	if len(candidates) > 1 {
+		result.RunnerUps = candidates[1:]
+		if len(result.RunnerUps) > MaxMatchRunnerUps {
+			result.RunnerUps = result.RunnerUps[:MaxMatchRunnerUps]
+		}
+
+		margin := candidates[0].Score - candidates[1].Score
+		result.Confidence *= 0.5 + 0.5*math.Min(1, margin/matchMargin)
+	}
+
+	if result.Confidence < m.threshold {
+		return nil, &AmbiguousMatchError{Result: result, Threshold: m.threshold}
+	}
+
+	return result, nil
+}
+
+//endregion
+
+//region - Private Methods
+
+// matchScore returns the weighted agreement of the candidate with the query, the unknown years and categories are
+// not counted
+func matchScore(query *MatchQuery, movie *MovieSearchData) float64 {
+	if movie == nil {
+		return 0
+	}
+
+	names := []string{movie.Name, movie.OriginalName, movie.AlternativeName}
+
+	score := matchWeightName * bestSimilarity(query.Name, names)
+	weights := matchWeightName
+
+	if len(query.OriginalName) > 0 {
+		score += matchWeightOriginalName * bestSimilarity(query.OriginalName, names)
+		weights += matchWeightOriginalName
+	}
+	if query.Year > 0 && movie.Year > 0 {
+		switch diff := query.Year - movie.Year; {
+		case diff == 0:
+			score += matchWeightYear
+		case diff == 1 || diff == -1:
+			score += matchWeightYear / 2
+		}
+		weights += matchWeightYear
+	}
+	if query.Category > 0 {
+		if query.Category == movie.CategoryId {
+			score += matchWeightCategory
+		}
+		weights += matchWeightCategory
+	}
+
+	return score / weights
+}
+
+// bestSimilarity returns the best similarity of the name variants with the names of the candidate
+func bestSimilarity(name string, names []string) float64 {
+	best := 0.0
+	for _, variant := range QueryVariants(name) {
+		for _, candidate := range names {
+			if s := similarity(variant, NormalizeName(candidate)); s > best {
+				best = s
+			}
+		}
+	}
+
+	return best
+}
+
+// similarity returns the similarity of the strings from 0 to 1 based on the Levenshtein distance
+func similarity(a, b string) float64 {
+	if len(a) <= 0 || len(b) <= 0 {
+		return 0
+	}
+
+	ar, br := []rune(a), []rune(b)
+	previous := make([]int, len(br)+1)
+	current := make([]int, len(br)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ar); i++ {
+		current[0] = i
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+			current[j] = previous[j-1] + cost
+			if previous[j]+1 < current[j] {
+				current[j] = previous[j] + 1
+			}
+			if current[j-1]+1 < current[j] {
+				current[j] = current[j-1] + 1
+			}
+		}
+		previous, current = current, previous
+	}
+
+	maxLen := len(ar)
+	if len(br) > maxLen {
+		maxLen = len(br)
+	}
+
+	return 1 - float64(previous[len(br)])/float64(maxLen)
+}
+
+//endregion
@@ -0,0 +1,92 @@
+package alloha
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newMatchClient creates a client with a test server that finds the same-name titles
+func newMatchClient(t *testing.T) *APIClient {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Возвращаем тестовые данные
+		switch r.URL.Query().Get("name") {
+		case "бригада":
+			_, _ = io.WriteString(w, `{"status":"success","data":[
+				{"id_kp":1,"name":"Бригада 2: Наследник","year":2012,"category_id":1},
+				{"id_kp":45,"name":"Бригада","year":1965,"category_id":1},
+				{"id_kp":77044,"name":"Бригада","year":2002,"category_id":2}
+			]}`)
+		case "brigada":
+			_, _ = io.WriteString(w, `{"status":"success","data":[null,{"id_kp":77044,"name":"Бригада","year":2002,"category_id":2}]}`)
+		default:
+			_, _ = io.WriteString(w, `{"status":"error","error_info":"not movie"}`)
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	// Создаем клиент с тестовым сервером
+	client, err := NewAPIClient(ts.Client(), "test-api-key", ts.URL)
+	require.NoError(t, err)
+
+	return client
+}
+
+func TestMatcher_MatchTitle(t *testing.T) {
+	matcher := NewMatcher(newMatchClient(t))
+
+	result, err := matcher.MatchTitle(t.Context(), MatchQuery{Name: "Бригада (2002)", Year: 2002, Category: 2})
+
+	// Проверяем результат
+	require.NoError(t, err)
+	assert.Equal(t, 77044, result.Best.Movie.IDKp)
+	assert.Equal(t, 1.0, result.Best.Score)
+	assert.Equal(t, 1.0, result.Confidence)
+	require.Len(t, result.RunnerUps, 2)
+	assert.Equal(t, 45, result.RunnerUps[0].Movie.IDKp)
+	assert.Equal(t, 1, result.RunnerUps[1].Movie.IDKp)
+	assert.Less(t, result.RunnerUps[1].Score, result.RunnerUps[0].Score)
+
+	// Проверяем поиск по оригинальному названию, если название не найдено
+	result, err = matcher.MatchTitle(t.Context(), MatchQuery{Name: "Бригада: Наследие", OriginalName: "Brigada", Year: 2002})
+	require.NoError(t, err)
+	assert.Equal(t, 77044, result.Best.Movie.IDKp)
+
+	// Проверяем поиск по транслитерации и соседнему году
+	result, err = matcher.MatchTitle(t.Context(), MatchQuery{Name: "Brigada", Year: 2003})
+	require.NoError(t, err)
+	assert.Equal(t, 77044, result.Best.Movie.IDKp)
+	assert.Greater(t, result.Confidence, DefaultMatchThreshold)
+}
+
+func TestMatcher_Ambiguous(t *testing.T) {
+	matcher := NewMatcher(newMatchClient(t))
+
+	_, err := matcher.MatchTitle(t.Context(), MatchQuery{Name: "Бригада"})
+
+	// Проверяем результат
+	var ambiguousErr *AmbiguousMatchError
+	require.True(t, errors.As(err, &ambiguousErr))
+	assert.Equal(t, DefaultMatchThreshold, ambiguousErr.Threshold)
+	assert.Equal(t, 0.5, ambiguousErr.Result.Confidence)
+	assert.Len(t, ambiguousErr.Result.RunnerUps, 2)
+	assert.EqualError(t, err, "ambiguous title match: confidence 0.50 is below the threshold 0.60")
+
+	// Снижаем порог
+	require.NoError(t, matcher.SetThreshold(0.5))
+	result, err := matcher.MatchTitle(t.Context(), MatchQuery{Name: "Бригада"})
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, result.Best.Score)
+
+	_, err = matcher.MatchTitle(t.Context(), MatchQuery{Name: "Бумер"})
+	assert.True(t, IsNotFound(err))
+	_, err = matcher.MatchTitle(t.Context(), MatchQuery{Name: "", OriginalName: "  "})
+	assert.Equal(t, EmptyMovieNameParameterError, err)
+
+	assert.Equal(t, InvalidMatchThresholdParameterError, matcher.SetThreshold(1.5))
+}
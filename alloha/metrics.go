@@ -0,0 +1,100 @@
+package alloha
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Outcomes of the API requests passed to the MetricsRecorder
+const (
+	OutcomeSuccess        = "success"
+	OutcomeAPIError       = "api_error"
+	OutcomeHTTPError      = "http_error"
+	OutcomeTransportError = "transport_error"
+	OutcomeDecodeError    = "decode_error"
+	OutcomeTooLarge       = "too_large"
+	OutcomeCanceled       = "canceled"
+)
+
+// MetricsRecorder provides an interface for collecting the SDK metrics. Implementations must be safe for
+// concurrent use.
+type MetricsRecorder interface {
+	// ObserveRequest records a finished API request with its outcome, latency and decoded response size
+	ObserveRequest(operation, outcome string, duration time.Duration, responseBytes int64)
+	// IncRetry records a repeated attempt of an API request
+	IncRetry(operation string)
+	// IncCacheHit records an API call served from a cache
+	IncCacheHit(operation string)
+	// IncCacheMiss records an API call not found in a cache
+	IncCacheMiss(operation string)
+	// ObserveRateLimitWait records the time an API request spent waiting for the rate limiter
+	ObserveRateLimitWait(operation string, wait time.Duration)
+}
+
+// NopMetricsRecorder is a MetricsRecorder that discards all metrics, it is used by default
+type NopMetricsRecorder struct{}
+
+// ObserveRequest implements the MetricsRecorder interface
+func (NopMetricsRecorder) ObserveRequest(operation, outcome string, duration time.Duration, responseBytes int64) {
+}
+
+// IncRetry implements the MetricsRecorder interface
+func (NopMetricsRecorder) IncRetry(operation string) {}
+
+// IncCacheHit implements the MetricsRecorder interface
+func (NopMetricsRecorder) IncCacheHit(operation string) {}
+
+// IncCacheMiss implements the MetricsRecorder interface
+func (NopMetricsRecorder) IncCacheMiss(operation string) {}
+
+// ObserveRateLimitWait implements the MetricsRecorder interface
+func (NopMetricsRecorder) ObserveRateLimitWait(operation string, wait time.Duration) {}
+
+//region - Public Methods
+
+// SetMetricsRecorder sets the recorder that receives the SDK metrics. Passing nil disables the metrics.
+func (c *APIClient) SetMetricsRecorder(recorder MetricsRecorder) {
+	if recorder == nil {
+		recorder = NopMetricsRecorder{}
+	}
+
+	c.metrics = recorder
+}
+
+//endregion
+
+//region - Private Methods
+
+// recordRequest reports the result of the whole API request to the metrics recorder
+func (c *APIClient) recordRequest(request *apiRequest, err error) {
+	c.metrics.ObserveRequest(request.operation, request.outcome(err), time.Since(request.started), request.bytes())
+
+	if request.call != nil && request.call.RateLimitWait > 0 {
+		c.metrics.ObserveRateLimitWait(request.operation, request.call.RateLimitWait)
+	}
+}
+
+// outcome classifies the result of the API request
+func (r *apiRequest) outcome(err error) string {
+	var tooLargeErr *ResponseTooLargeError
+
+	switch {
+	case err == nil && r.apiStatus == "error":
+		return OutcomeAPIError
+	case err == nil:
+		return OutcomeSuccess
+	case errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded):
+		return OutcomeCanceled
+	case errors.As(err, &tooLargeErr):
+		return OutcomeTooLarge
+	case r.transportErr:
+		return OutcomeTransportError
+	case r.statusCode != 0 && r.statusCode != 200:
+		return OutcomeHTTPError
+	default:
+		return OutcomeDecodeError
+	}
+}
+
+//endregion
@@ -0,0 +1,188 @@
+package alloha
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingMetrics is a MetricsRecorder that keeps all observations in memory
+type recordingMetrics struct {
+	mu       sync.Mutex
+	outcomes []string
+	retries  int
+	sizes    []int64
+}
+
+// ObserveRequest implements the MetricsRecorder interface
+func (m *recordingMetrics) ObserveRequest(operation, outcome string, duration time.Duration, responseBytes int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.outcomes = append(m.outcomes, operation+":"+outcome)
+	m.sizes = append(m.sizes, responseBytes)
+}
+
+// IncRetry implements the MetricsRecorder interface
+func (m *recordingMetrics) IncRetry(operation string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.retries++
+}
+
+// IncCacheHit implements the MetricsRecorder interface
+func (m *recordingMetrics) IncCacheHit(operation string) {}
+
+// IncCacheMiss implements the MetricsRecorder interface
+func (m *recordingMetrics) IncCacheMiss(operation string) {}
+
+// ObserveRateLimitWait implements the MetricsRecorder interface
+func (m *recordingMetrics) ObserveRateLimitWait(operation string, wait time.Duration) {}
+
+func TestAPIClient_SetMetricsRecorder(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		body       string
+		outcome    string
+	}{
+		{
+			name:       "success",
+			statusCode: http.StatusOK,
+			body:       "{\"status\":\"success\",\"data\":{\"name\":\"Бригада\",\"id_kp\":77044}}",
+			outcome:    OutcomeSuccess,
+		},
+		{
+			name:       "api error",
+			statusCode: http.StatusOK,
+			body:       "{\"status\":\"error\",\"error_info\":\"not movie\"}",
+			outcome:    OutcomeAPIError,
+		},
+		{
+			name:       "http error",
+			statusCode: http.StatusNotFound,
+			body:       "",
+			outcome:    OutcomeHTTPError,
+		},
+		{
+			name:       "decode error",
+			statusCode: http.StatusOK,
+			body:       "{\"status\":",
+			outcome:    OutcomeDecodeError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				// Возвращаем тестовые данные
+				w.WriteHeader(tt.statusCode)
+				_, errWrite := io.WriteString(w, tt.body)
+				if errWrite != nil {
+					t.Errorf("failed to write data to response: %v", errWrite)
+				}
+			}))
+			defer ts.Close()
+
+			// Создаем клиент с тестовым сервером
+			client, err := NewAPIClient(ts.Client(), "test-api-key", ts.URL)
+			if err != nil {
+				t.Fatalf("failed to create client: %v", err)
+			}
+
+			metrics := &recordingMetrics{}
+			client.SetMetricsRecorder(metrics)
+
+			_, _ = client.FindByKPId(t.Context(), 77044)
+
+			// Проверяем результат
+			assert.Equal(t, []string{OperationFindByKPId + ":" + tt.outcome}, metrics.outcomes)
+			assert.Equal(t, []int64{int64(len(tt.body))}, metrics.sizes)
+		})
+	}
+}
+
+func TestAPIClient_SetMetricsRecorder_RetriesAndRateLimit(t *testing.T) {
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests < 3 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+
+		// Возвращаем тестовые данные
+		w.WriteHeader(http.StatusOK)
+		_, errWrite := io.WriteString(w, "{\"status\":\"success\",\"data\":[]}")
+		if errWrite != nil {
+			t.Errorf("failed to write data to response: %v", errWrite)
+		}
+	}))
+	defer ts.Close()
+
+	// Создаем клиент с тестовым сервером
+	client, err := NewAPIClient(ts.Client(), "test-api-key", ts.URL)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	limiter, err := NewRateLimiter(1000, 1)
+	if err != nil {
+		t.Fatalf("failed to create rate limiter: %v", err)
+	}
+
+	metrics := &recordingMetrics{}
+	client.SetMetricsRecorder(metrics)
+	client.Use(RetryMiddleware(3, time.Millisecond), RateLimitMiddleware(limiter))
+
+	_, errList := client.SearchListByName(t.Context(), "Бригада")
+
+	// Проверяем результат
+	assert.NoError(t, errList)
+	assert.Equal(t, []string{OperationSearchListByName + ":" + OutcomeSuccess}, metrics.outcomes)
+	assert.Equal(t, 2, metrics.retries)
+}
+
+func TestAPIClient_SetMetricsRecorder_Canceled(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	// Создаем клиент с тестовым сервером
+	client, err := NewAPIClient(ts.Client(), "test-api-key", ts.URL)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	metrics := &recordingMetrics{}
+	client.SetMetricsRecorder(metrics)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	_, errMovie := client.FindByKPId(ctx, 77044)
+
+	// Проверяем результат
+	assert.Error(t, errMovie)
+	assert.Equal(t, []string{OperationFindByKPId + ":" + OutcomeCanceled}, metrics.outcomes)
+}
+
+func TestAPIClient_SetMetricsRecorder_Nil(t *testing.T) {
+	client, err := NewAPIClient(http.DefaultClient, "test-api-key", "https://api.alloha.tv")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	client.SetMetricsRecorder(nil)
+
+	// Проверяем результат
+	assert.Equal(t, NopMetricsRecorder{}, client.metrics)
+}
@@ -0,0 +1,365 @@
+package alloha
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"math"
+	mathrand "math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Names of the API operations passed to the middlewares
+const (
+	OperationFindByIMDbId             = "FindByIMDbId"
+	OperationFindByKPId               = "FindByKPId"
+	OperationFindByTMDbId             = "FindByTMDbId"
+	OperationGetListOfLatestSeries    = "GetListOfLatestSeries"
+	OperationSearchForOneByName       = "SearchForOneByName"
+	OperationSearchListByName         = "SearchListByName"
+	OperationStreamListByName         = "StreamListByName"
+	OperationStreamListOfLatestSeries = "StreamListOfLatestSeries"
+)
+
+// operationContextKey is the context key of the operation name
+type operationContextKey struct{}
+
+// OperationFromContext returns the name of the API operation carried by the context of the outgoing request, e.g.
+// "FindByKPId", so that an HttpClient can tell the operations apart. It returns an empty string for other contexts.
+func OperationFromContext(ctx context.Context) string {
+	operation, _ := ctx.Value(operationContextKey{}).(string)
+
+	return operation
+}
+
+// Call represents a single API call passed through the middleware chain
+type Call struct {
+	// Name of the client method, e.g. "FindByKPId"
+	Operation string
+	// Parsed query parameters of the call without the API token, e.g. {"kp": "1236630"}
+	Params map[string]string
+	// Number of the current attempt, starting from 1
+	Attempt int
+	// Time spent waiting for the rate limiter
+	RateLimitWait time.Duration
+	// HTTP request of the current attempt
+	Request *http.Request
+}
+
+// Doer executes the API call
+type Doer interface {
+	Do(call *Call) (*http.Response, error)
+}
+
+// DoerFunc is an adapter to use an ordinary function as a Doer
+type DoerFunc func(call *Call) (*http.Response, error)
+
+// Do implements the Doer interface
+func (f DoerFunc) Do(call *Call) (*http.Response, error) {
+	return f(call)
+}
+
+// Middleware wraps the next Doer of the chain
+type Middleware func(next Doer) Doer
+
+//region - Public Methods
+
+// Use adds the middlewares to the end of the chain. The first added middleware is the outermost one, the last one
+// calls the HttpClient directly.
+func (c *APIClient) Use(middlewares ...Middleware) {
+	for _, middleware := range middlewares {
+		if middleware != nil {
+			c.middlewares = append(c.middlewares, middleware)
+		}
+	}
+}
+
+//endregion
+
+//region - Built-in Middlewares
+
+// HeaderMiddleware sets the specified headers on every request, e.g. an authorization header of a mirror
+func HeaderMiddleware(header http.Header) Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(call *Call) (*http.Response, error) {
+			for key, values := range header {
+				call.Request.Header.Del(key)
+				for _, value := range values {
+					call.Request.Header.Add(key, value)
+				}
+			}
+
+			return next.Do(call)
+		})
+	}
+}
+
+// RequestIDMiddleware sets a random request ID in the specified header, unless the request already has one. All
+// attempts of the same call share the request ID.
+func RequestIDMiddleware(headerName string) Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(call *Call) (*http.Response, error) {
+			if len(call.Request.Header.Get(headerName)) <= 0 {
+				call.Request.Header.Set(headerName, newRequestID())
+			}
+
+			return next.Do(call)
+		})
+	}
+}
+
+// RetryMiddleware retries the call on transport errors and on 429 and 5xx responses up to maxAttempts times in
+// total. The delay between the attempts starts from the backoff value and doubles with every attempt, the
+// Retry-After response header is respected.
+func RetryMiddleware(maxAttempts int, backoff time.Duration) Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(call *Call) (*http.Response, error) {
+			ctx := call.Request.Context()
+
+			for {
+				resp, err := next.Do(call)
+				if call.Attempt >= maxAttempts || !isRetryable(resp, err) {
+					return resp, err
+				}
+
+				delay := time.Duration(float64(backoff) * math.Pow(2, float64(call.Attempt-1)))
+				if resp != nil {
+					if retryAfter, parseErr := strconv.Atoi(resp.Header.Get("Retry-After")); parseErr == nil && retryAfter > 0 {
+						delay = time.Duration(retryAfter) * time.Second
+					}
+					drainResponse(resp)
+				}
+
+				if err = sleepContext(ctx, delay); err != nil {
+					return nil, err
+				}
+
+				req, cloneErr := cloneRequest(call.Request)
+				if cloneErr != nil {
+					return nil, cloneErr
+				}
+
+				call.Request = req
+				call.Attempt++
+			}
+		})
+	}
+}
+
+// RateLimitMiddleware waits for the rate limiter before every attempt of the call
+func RateLimitMiddleware(limiter *RateLimiter) Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(call *Call) (*http.Response, error) {
+			wait, err := limiter.Wait(call.Request.Context())
+			call.RateLimitWait += wait
+			if err != nil {
+				return nil, err
+			}
+
+			return next.Do(call)
+		})
+	}
+}
+
+// FaultInjectionMiddleware fails the specified share of calls (from 0 to 1) with an empty response with
+// the specified status code instead of executing them. It is intended for testing the error handling of
+// the applications.
+func FaultInjectionMiddleware(probability float64, statusCode int) Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(call *Call) (*http.Response, error) {
+			if mathrand.Float64() >= probability {
+				return next.Do(call)
+			}
+
+			return &http.Response{
+				Status:     strconv.Itoa(statusCode) + " " + http.StatusText(statusCode),
+				StatusCode: statusCode,
+				Proto:      "HTTP/1.1",
+				ProtoMajor: 1,
+				ProtoMinor: 1,
+				Header:     http.Header{},
+				Body:       http.NoBody,
+				Request:    call.Request,
+			}, nil
+		})
+	}
+}
+
+//endregion
+
+//region - Rate Limiter
+
+// RateLimiter is a token bucket rate limiter that can be shared by several clients
+type RateLimiter struct {
+	mu       sync.Mutex
+	rate     float64
+	burst    float64
+	tokens   float64
+	lastTime time.Time
+}
+
+// NewRateLimiter creates a new RateLimiter instance that allows requestsPerSecond requests per second on average
+// and bursts of up to burst requests
+func NewRateLimiter(requestsPerSecond float64, burst int) (*RateLimiter, error) {
+	if requestsPerSecond <= 0 {
+		return nil, InvalidRateLimitParameterError
+	}
+	if burst <= 0 {
+		return nil, InvalidBurstParameterError
+	}
+
+	return &RateLimiter{
+		rate:     requestsPerSecond,
+		burst:    float64(burst),
+		tokens:   float64(burst),
+		lastTime: time.Now(),
+	}, nil
+}
+
+// Wait blocks until the next request is allowed or the context is done and returns the time spent waiting
+func (l *RateLimiter) Wait(ctx context.Context) (time.Duration, error) {
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens = math.Min(l.burst, l.tokens+now.Sub(l.lastTime).Seconds()*l.rate)
+	l.lastTime = now
+	l.tokens--
+	delay := time.Duration(-l.tokens / l.rate * float64(time.Second))
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return 0, nil
+	}
+
+	started := time.Now()
+	if err := sleepContext(ctx, delay); err != nil {
+		// Return the reserved token, the request won't be executed
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+
+		return time.Since(started), err
+	}
+
+	return time.Since(started), nil
+}
+
+// Allow reports whether a request is allowed now and takes a token if it is, it never blocks
+func (l *RateLimiter) Allow() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.tokens = math.Min(l.burst, l.tokens+now.Sub(l.lastTime).Seconds()*l.rate)
+	l.lastTime = now
+	if l.tokens < 1 {
+		return false
+	}
+	l.tokens--
+
+	return true
+}
+
+//endregion
+
+//region - Private Methods
+
+// handler returns the Doer that executes the calls through all middlewares
+func (c *APIClient) handler() Doer {
+	var doer Doer = DoerFunc(func(call *Call) (*http.Response, error) {
+		if call.Attempt > 1 {
+			c.metrics.IncRetry(call.Operation)
+		}
+
+		started := time.Now()
+		resp, err := c.client.Do(call.Request)
+		c.logAttempt(call, resp, err, time.Since(started))
+
+		return resp, err
+	})
+
+	for i := len(c.middlewares) - 1; i >= 0; i-- {
+		doer = c.middlewares[i](doer)
+	}
+
+	return doer
+}
+
+// newCall creates a new Call instance for the first attempt of the request
+func newCall(operation string, req *http.Request) *Call {
+	params := make(map[string]string)
+	for key, values := range req.URL.Query() {
+		if key != "token" && len(values) > 0 {
+			params[key] = values[0]
+		}
+	}
+
+	return &Call{
+		Operation: operation,
+		Params:    params,
+		Attempt:   1,
+		Request:   req,
+	}
+}
+
+// isRetryable reports whether the call result is worth retrying
+func isRetryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+}
+
+// cloneRequest creates a copy of the request with a fresh body for the next attempt
+func cloneRequest(req *http.Request) (*http.Request, error) {
+	clone := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		clone.Body = body
+	}
+
+	return clone, nil
+}
+
+// drainResponse reads the rest of the response body and closes it, so that the connection can be reused
+func drainResponse(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	_ = resp.Body.Close()
+}
+
+// sleepContext pauses for the specified duration or until the context is done
+func sleepContext(ctx context.Context, delay time.Duration) error {
+	if delay <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// newRequestID generates a random request ID
+func newRequestID() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+
+	return hex.EncodeToString(buf)
+}
+
+//endregion
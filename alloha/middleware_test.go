@@ -0,0 +1,263 @@
+package alloha
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIClient_Use(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Проверяем заголовки, установленные посредниками
+		assert.Equal(t, "Bearer mirror-token", r.Header.Get("Authorization"))
+		assert.Len(t, r.Header.Get("X-Request-Id"), 32)
+
+		// Возвращаем тестовые данные
+		w.WriteHeader(http.StatusOK)
+		_, errWrite := io.WriteString(w, "{\"status\":\"success\",\"data\":{\"name\":\"Бригада\",\"id_kp\":77044}}")
+		if errWrite != nil {
+			t.Errorf("failed to write data to response: %v", errWrite)
+		}
+	}))
+	defer ts.Close()
+
+	// Создаем клиент с тестовым сервером
+	client, err := NewAPIClient(ts.Client(), "test-api-key", ts.URL)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	var order []string
+	var seenCall Call
+	recorder := func(name string) Middleware {
+		return func(next Doer) Doer {
+			return DoerFunc(func(call *Call) (*http.Response, error) {
+				order = append(order, name)
+				seenCall = *call
+				return next.Do(call)
+			})
+		}
+	}
+
+	client.Use(
+		recorder("outer"),
+		HeaderMiddleware(http.Header{"Authorization": []string{"Bearer mirror-token"}}),
+		RequestIDMiddleware("X-Request-Id"),
+		nil,
+		recorder("inner"),
+	)
+
+	movie, errMovie := client.FindByKPId(t.Context(), 77044)
+
+	// Проверяем результат
+	assert.NoError(t, errMovie)
+	assert.Equal(t, "Бригада", movie.Data.Name)
+	assert.Equal(t, []string{"outer", "inner"}, order)
+	assert.Equal(t, OperationFindByKPId, seenCall.Operation)
+	assert.Equal(t, map[string]string{"kp": "77044"}, seenCall.Params)
+	assert.Equal(t, 1, seenCall.Attempt)
+}
+
+func TestRetryMiddleware(t *testing.T) {
+	tests := []struct {
+		name             string
+		maxAttempts      int
+		failures         int
+		failureStatus    int
+		expectedAttempts int
+		expectedErr      bool
+	}{
+		{
+			name:             "success after server errors",
+			maxAttempts:      3,
+			failures:         2,
+			failureStatus:    http.StatusServiceUnavailable,
+			expectedAttempts: 3,
+			expectedErr:      false,
+		},
+		{
+			name:             "too many requests exhaust attempts",
+			maxAttempts:      2,
+			failures:         5,
+			failureStatus:    http.StatusTooManyRequests,
+			expectedAttempts: 2,
+			expectedErr:      true,
+		},
+		{
+			name:             "client errors are not retried",
+			maxAttempts:      3,
+			failures:         1,
+			failureStatus:    http.StatusForbidden,
+			expectedAttempts: 1,
+			expectedErr:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				if requests <= tt.failures {
+					w.WriteHeader(tt.failureStatus)
+					return
+				}
+
+				// Возвращаем тестовые данные
+				w.WriteHeader(http.StatusOK)
+				_, errWrite := io.WriteString(w, "{\"status\":\"success\",\"data\":[]}")
+				if errWrite != nil {
+					t.Errorf("failed to write data to response: %v", errWrite)
+				}
+			}))
+			defer ts.Close()
+
+			client, errClient := NewAPIClient(ts.Client(), "test-api-token", ts.URL)
+			assert.NoError(t, errClient)
+
+			lastAttempt := 0
+			client.Use(RetryMiddleware(tt.maxAttempts, time.Millisecond), func(next Doer) Doer {
+				return DoerFunc(func(call *Call) (*http.Response, error) {
+					lastAttempt = call.Attempt
+					return next.Do(call)
+				})
+			})
+
+			series, errSeries := client.GetListOfLatestSeries(t.Context(), 1)
+
+			if tt.expectedErr {
+				assert.Error(t, errSeries)
+				assert.Nil(t, series)
+			} else {
+				assert.NoError(t, errSeries)
+				assert.Equal(t, "success", series.Status)
+			}
+			assert.Equal(t, tt.expectedAttempts, requests)
+			assert.Equal(t, tt.expectedAttempts, lastAttempt)
+		})
+	}
+}
+
+func TestFaultInjectionMiddleware(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("the request must not reach the server")
+	}))
+	defer ts.Close()
+
+	client, errClient := NewAPIClient(ts.Client(), "test-api-token", ts.URL)
+	assert.NoError(t, errClient)
+	client.Use(FaultInjectionMiddleware(1, http.StatusBadGateway))
+
+	movie, errMovie := client.FindByIMDbId(t.Context(), "tt0110912")
+
+	// Проверяем результат
+	assert.Nil(t, movie)
+	assert.EqualError(t, errMovie, "unexpected server response with a status code: 502")
+}
+
+func TestNewRateLimiter(t *testing.T) {
+	tests := []struct {
+		name              string
+		requestsPerSecond float64
+		burst             int
+		expectedErr       error
+	}{
+		{
+			name:              "invalid rate",
+			requestsPerSecond: 0,
+			burst:             1,
+			expectedErr:       InvalidRateLimitParameterError,
+		},
+		{
+			name:              "invalid burst",
+			requestsPerSecond: 10,
+			burst:             0,
+			expectedErr:       InvalidBurstParameterError,
+		},
+		{
+			name:              "valid params",
+			requestsPerSecond: 10,
+			burst:             2,
+			expectedErr:       nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter, err := NewRateLimiter(tt.requestsPerSecond, tt.burst)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				assert.Nil(t, limiter)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, limiter)
+			}
+		})
+	}
+}
+
+func TestRateLimiter_Wait(t *testing.T) {
+	limiter, err := NewRateLimiter(50, 1)
+	assert.NoError(t, err)
+
+	firstWait, errFirst := limiter.Wait(t.Context())
+	secondWait, errSecond := limiter.Wait(t.Context())
+
+	// Проверяем результат
+	assert.NoError(t, errFirst)
+	assert.NoError(t, errSecond)
+	assert.Zero(t, firstWait)
+	assert.Greater(t, secondWait, 10*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	_, errCanceled := limiter.Wait(ctx)
+	assert.ErrorIs(t, errCanceled, context.Canceled)
+}
+
+func TestRateLimiter_Allow(t *testing.T) {
+	limiter, err := NewRateLimiter(0.001, 2)
+	assert.NoError(t, err)
+
+	// Проверяем результат
+	assert.True(t, limiter.Allow())
+	assert.True(t, limiter.Allow())
+	assert.False(t, limiter.Allow())
+}
+
+// operationClient records the operations carried by the request contexts
+type operationClient struct {
+	operations []string
+}
+
+// Do implements the HttpClient interface
+func (c *operationClient) Do(req *http.Request) (*http.Response, error) {
+	c.operations = append(c.operations, OperationFromContext(req.Context()))
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader("{\"status\":\"success\",\"data\":[]}")),
+	}, nil
+}
+
+func TestOperationFromContext(t *testing.T) {
+	httpClient := &operationClient{}
+	client, errClient := NewAPIClient(httpClient, "test-api-token", "https://example.com")
+	assert.NoError(t, errClient)
+
+	_, _ = client.FindByKPId(t.Context(), 77044)
+	_, _ = client.StreamListByName(t.Context(), "Бригада", func(movie *MovieSearchData) error { return nil })
+
+	// Проверяем результат
+	assert.Equal(t, []string{OperationFindByKPId, OperationStreamListByName}, httpClient.operations)
+	assert.Equal(t, "", OperationFromContext(t.Context()))
+}
@@ -0,0 +1,223 @@
+package alloha
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// TransliterationScheme is a scheme of the Cyrillic to Latin transliteration
+type TransliterationScheme int
+
+// Transliteration schemes
+const (
+	// SchemeGOST is the GOST 7.79-2000 system B without the apostrophes, e.g. "щука" is "shhuka"
+	SchemeGOST TransliterationScheme = iota
+	// SchemeInformal is the common informal scheme, e.g. "щука" is "schuka"
+	SchemeInformal
+)
+
+// yearPattern matches the years in brackets anywhere and the bare year at the end of the name
+var yearPattern = regexp.MustCompile(`[(\[]\s*(18|19|20)\d{2}\s*[)\]]|\s(18|19|20)\d{2}\s*$`)
+
+// gostTable is the GOST 7.79-2000 system B transliteration, the hard and soft signs are dropped. The "ц" letter is
+// "c" before "и", "е", "ы" and "й".
+var gostTable = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "yo", 'ж': "zh", 'з': "z", 'и': "i",
+	'й': "j", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t",
+	'у': "u", 'ф': "f", 'х': "x", 'ц': "cz", 'ч': "ch", 'ш': "sh", 'щ': "shh", 'ъ': "", 'ы': "y", 'ь': "",
+	'э': "e", 'ю': "yu", 'я': "ya",
+}
+
+// informalTable overrides the GOST transliteration with the common informal spelling
+var informalTable = map[rune]string{
+	'ё': "e", 'й': "y", 'х': "kh", 'ц': "ts", 'щ': "sch",
+}
+
+// latinDigraphs are the Latin letter combinations of both schemes, the longest first
+var latinDigraphs = []struct {
+	latin    string
+	cyrillic string
+}{
+	{"shch", "щ"}, {"shh", "щ"}, {"sch", "щ"},
+	{"zh", "ж"}, {"kh", "х"}, {"ch", "ч"}, {"sh", "ш"}, {"ts", "ц"}, {"cz", "ц"},
+	{"yu", "ю"}, {"ju", "ю"}, {"ya", "я"}, {"ja", "я"}, {"yo", "е"}, {"jo", "е"},
+}
+
+// latinLetters is the Latin to Cyrillic transliteration of the single letters
+var latinLetters = map[rune]string{
+	'a': "а", 'b': "б", 'c': "к", 'd': "д", 'e': "е", 'f': "ф", 'g': "г", 'h': "х", 'i': "и", 'j': "й",
+	'k': "к", 'l': "л", 'm': "м", 'n': "н", 'o': "о", 'p': "п", 'q': "к", 'r': "р", 's': "с", 't': "т",
+	'u': "у", 'v': "в", 'w': "в", 'x': "кс", 'z': "з",
+}
+
+// FoldName folds the name for the comparison: lower-cases it, folds "ё" to "е", replaces the punctuation with spaces
+// and collapses the spaces, e.g. "Зелёная миля: Фильм" is "зеленая миля фильм"
+func FoldName(name string) string {
+	name = strings.ReplaceAll(strings.ToLower(name), "ё", "е")
+
+	return strings.Join(strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+// NormalizeName folds the name with FoldName and strips the years in brackets and the year at the end of the name,
+// e.g. "Бригада (2002)" is "бригада". The name consisting of a year only, e.g. "1917", is kept.
+func NormalizeName(name string) string {
+	if stripped := FoldName(yearPattern.ReplaceAllString(name, " ")); len(stripped) > 0 {
+		return stripped
+	}
+
+	return FoldName(name)
+}
+
+// Transliterate transliterates the Cyrillic letters of the text to Latin with the scheme, other characters are kept
+func Transliterate(text string, scheme TransliterationScheme) string {
+	var builder strings.Builder
+	runes := []rune(text)
+	for i, r := range runes {
+		lower := unicode.ToLower(r)
+		latin, found := gostTable[lower]
+		if lower == 'ц' && i+1 < len(runes) && strings.ContainsRune("иеый", unicode.ToLower(runes[i+1])) {
+			latin = "c"
+		}
+		if scheme == SchemeInformal {
+			if informal, overridden := informalTable[lower]; overridden {
+				latin = informal
+			}
+		}
+		if !found {
+			builder.WriteRune(r)
+			continue
+		}
+
+		if lower != r && len(latin) > 0 {
+			latin = strings.ToUpper(latin[:1]) + latin[1:]
+		}
+		builder.WriteString(latin)
+	}
+
+	return builder.String()
+}
+
+// ToCyrillic transliterates the lower-case Latin text to Cyrillic, recognizing the letter combinations of both
+// GOST and informal schemes, e.g. "brigada" is "бригада" and "schuka" is "щука"
+func ToCyrillic(text string) string {
+	var builder strings.Builder
+	runes := []rune(text)
+
+	for i := 0; i < len(runes); {
+		rest := string(runes[i:])
+		matched := false
+		for _, digraph := range latinDigraphs {
+			if strings.HasPrefix(rest, digraph.latin) {
+				builder.WriteString(digraph.cyrillic)
+				i += len(digraph.latin)
+				matched = true
+				break
+			}
+		}
+		if matched {
+			continue
+		}
+
+		r := runes[i]
+		switch {
+		case r == 'y':
+			// "y" is "й" after a vowel, e.g. "tolstoy", and "ы" otherwise
+			if i > 0 && strings.ContainsRune("aeiouy", runes[i-1]) {
+				builder.WriteString("й")
+			} else {
+				builder.WriteString("ы")
+			}
+		case len(latinLetters[r]) > 0:
+			builder.WriteString(latinLetters[r])
+		default:
+			builder.WriteRune(r)
+		}
+		i++
+	}
+
+	return builder.String()
+}
+
+// QueryVariants returns the normalized name followed by its transliterations: the GOST and informal ones for
+// the Cyrillic names and the Cyrillic one for the Latin names. It returns nil for the name without letters and digits.
+func QueryVariants(name string) []string {
+	normalized := NormalizeName(name)
+	if len(normalized) <= 0 {
+		return nil
+	}
+
+	variants := []string{normalized}
+	add := func(variant string) {
+		for _, v := range variants {
+			if v == variant {
+				return
+			}
+		}
+		variants = append(variants, variant)
+	}
+
+	if hasScript(normalized, unicode.Cyrillic) {
+		add(Transliterate(normalized, SchemeGOST))
+		add(Transliterate(normalized, SchemeInformal))
+	}
+	if hasScript(normalized, unicode.Latin) {
+		add(ToCyrillic(normalized))
+	}
+
+	return variants
+}
+
+// SearchVariants searches the titles by every variant of the name returned by QueryVariants and merges the results
+// without the duplicates in the order of the variants. It returns the not found error only if no variant is found.
+func SearchVariants(ctx context.Context, api API, name string) (*FindListResponse, error) {
+	variants := QueryVariants(name)
+	if len(variants) <= 0 {
+		return nil, EmptyMovieNameParameterError
+	}
+
+	merged := &FindListResponse{Status: "success"}
+	seen := make(map[int]bool)
+	var notFoundErr error
+
+	for _, variant := range variants {
+		response, err := api.SearchListByName(ctx, variant)
+		if err == nil {
+			err = response.Err()
+		}
+		if IsNotFound(err) {
+			notFoundErr = err
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, movie := range response.Data {
+			if movie != nil && !seen[movie.IDKp] {
+				seen[movie.IDKp] = true
+				merged.Data = append(merged.Data, movie)
+			}
+		}
+	}
+
+	if len(merged.Data) <= 0 && notFoundErr != nil {
+		return nil, notFoundErr
+	}
+
+	return merged, nil
+}
+
+// hasScript reports whether the text contains the letters of the script
+func hasScript(text string, script *unicode.RangeTable) bool {
+	for _, r := range text {
+		if unicode.Is(script, r) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,93 @@
+package alloha
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "Бригада", want: "бригада"},
+		{name: "  Бригада (2002) ", want: "бригада"},
+		{name: "Бригада [2002]", want: "бригада"},
+		{name: "Бригада 2002", want: "бригада"},
+		{name: "Зелёная миля: Фильм!", want: "зеленая миля фильм"},
+		{name: "Бригада 2: Наследник", want: "бригада 2 наследник"},
+		{name: "1917", want: "1917"},
+		{name: "(2002)", want: "2002"},
+		{name: "...", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Проверяем результат
+			assert.Equal(t, tt.want, NormalizeName(tt.name))
+		})
+	}
+}
+
+func TestTransliterate(t *testing.T) {
+	// Проверяем результат
+	assert.Equal(t, "Brigada", Transliterate("Бригада", SchemeGOST))
+	assert.Equal(t, "Shhuka i xolodnyj cyplyonok otcza", Transliterate("Щука и холодный цыплёнок отца", SchemeGOST))
+	assert.Equal(t, "Schuka i kholodnyy tsyplenok", Transliterate("Щука и холодный цыплёнок", SchemeInformal))
+	assert.Equal(t, "Zelenaya milya 2", Transliterate("Зеленая миля 2", SchemeGOST))
+	assert.Equal(t, "podezd", Transliterate("подъезд", SchemeGOST))
+
+	assert.Equal(t, "бригада", ToCyrillic("brigada"))
+	assert.Equal(t, "щука", ToCyrillic("schuka"))
+	assert.Equal(t, "щука", ToCyrillic("shhuka"))
+	assert.Equal(t, "холодный", ToCyrillic("kholodnyj"))
+	assert.Equal(t, "толстой", ToCyrillic("tolstoy"))
+	assert.Equal(t, "зеленая миля", ToCyrillic("zelenaya milya"))
+}
+
+func TestQueryVariants(t *testing.T) {
+	// Проверяем результат
+	assert.Equal(t, []string{"бригада", "brigada"}, QueryVariants("Бригада (2002)"))
+	assert.Equal(t, []string{"щука", "shhuka", "schuka"}, QueryVariants("Щука"))
+	assert.Equal(t, []string{"brigada", "бригада"}, QueryVariants("Brigada"))
+	assert.Equal(t, []string{"2002"}, QueryVariants("2002"))
+	assert.Nil(t, QueryVariants("?!"))
+}
+
+func TestSearchVariants(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Возвращаем тестовые данные
+		switch r.URL.Query().Get("name") {
+		case "brigada":
+			_, _ = io.WriteString(w, `{"status":"error","error_info":"not movie"}`)
+		case "бригада":
+			_, _ = io.WriteString(w, `{"status":"success","data":[{"id_kp":77044},{"id_kp":1}]}`)
+		default:
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	// Создаем клиент с тестовым сервером
+	client, err := NewAPIClient(ts.Client(), "test-api-key", ts.URL)
+	require.NoError(t, err)
+
+	response, err := SearchVariants(t.Context(), client, "Brigada (2002)")
+
+	// Проверяем результат
+	require.NoError(t, err)
+	require.Len(t, response.Data, 2)
+	assert.Equal(t, 77044, response.Data[0].IDKp)
+	assert.Equal(t, 1, response.Data[1].IDKp)
+
+	_, err = SearchVariants(t.Context(), client, "?!")
+	assert.Equal(t, EmptyMovieNameParameterError, err)
+
+	_, err = SearchVariants(t.Context(), client, "Щука")
+	assert.Equal(t, &UnexpectedStatusCodeError{StatusCode: http.StatusBadGateway}, err)
+}
@@ -0,0 +1,41 @@
+package alloha
+
+import "strings"
+
+// qualityRanks are the ranks of the known video qualities, the higher rank is the better quality
+var qualityRanks = map[string]int{
+	"camrip":  1,
+	"ts":      2,
+	"tc":      3,
+	"dvdscr":  4,
+	"satrip":  5,
+	"tvrip":   5,
+	"dvdrip":  6,
+	"hdtv":    7,
+	"hdtvrip": 7,
+	"webrip":  8,
+	"web-dl":  9,
+	"hdrip":   9,
+	"bdrip":   10,
+	"bluray":  11,
+	"remux":   12,
+}
+
+// QualityRank returns the rank of the video quality, e.g. "WEB-DL". The quality can list several variants separated
+// by commas, e.g. "WEB-DL, WEBRip", then the best one is ranked. Unknown qualities have the zero rank.
+func QualityRank(quality string) int {
+	rank := 0
+	for _, variant := range strings.Split(quality, ",") {
+		variant = strings.ToLower(strings.TrimSpace(variant))
+		if r := qualityRanks[variant]; r > rank {
+			rank = r
+		}
+	}
+
+	return rank
+}
+
+// IsQualityUpgrade reports whether the new video quality is better than the old one
+func IsQualityUpgrade(old, new string) bool {
+	return QualityRank(new) > QualityRank(old)
+}
@@ -0,0 +1,30 @@
+package alloha
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQualityRank(t *testing.T) {
+	tests := []struct {
+		name    string
+		old     string
+		new     string
+		upgrade bool
+	}{
+		{name: "upgrade", old: "HDTV", new: "WEB-DL", upgrade: true},
+		{name: "downgrade", old: "BDRip", new: "WEBRip", upgrade: false},
+		{name: "same", old: "WEB-DL", new: "web-dl", upgrade: false},
+		{name: "list", old: "WEBRip", new: "WEB-DL, WEBRip, HDTV", upgrade: true},
+		{name: "unknown", old: "", new: "HDTV", upgrade: true},
+		{name: "to unknown", old: "HDTV", new: "4K", upgrade: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Проверяем результат
+			assert.Equal(t, tt.upgrade, IsQualityUpgrade(tt.old, tt.new))
+		})
+	}
+}
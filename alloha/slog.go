@@ -0,0 +1,32 @@
+//go:build go1.21
+
+package alloha
+
+import (
+	"context"
+	"log/slog"
+)
+
+// slogLogger adapts slog.Logger to the Logger interface
+type slogLogger struct {
+	logger *slog.Logger
+}
+
+// NewSlogLogger creates a Logger that writes the SDK events to the slog.Logger
+func NewSlogLogger(logger *slog.Logger) Logger {
+	if logger == nil {
+		return NopLogger{}
+	}
+
+	return &slogLogger{logger: logger}
+}
+
+// Enabled implements the Logger interface
+func (l *slogLogger) Enabled(ctx context.Context, level LogLevel) bool {
+	return l.logger.Enabled(ctx, slog.Level(level))
+}
+
+// Log implements the Logger interface
+func (l *slogLogger) Log(ctx context.Context, level LogLevel, msg string, args ...interface{}) {
+	l.logger.Log(ctx, slog.Level(level), msg, args...)
+}
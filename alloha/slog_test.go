@@ -0,0 +1,32 @@
+//go:build go1.21
+
+package alloha
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSlogLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewSlogLogger(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo})))
+
+	assert.False(t, logger.Enabled(context.Background(), LogLevelDebug))
+	assert.True(t, logger.Enabled(context.Background(), LogLevelWarn))
+
+	logger.Log(context.Background(), LogLevelWarn, "alloha api request failed", "operation", OperationFindByKPId, "status", 502)
+
+	var record map[string]interface{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "WARN", record["level"])
+	assert.Equal(t, "alloha api request failed", record["msg"])
+	assert.Equal(t, OperationFindByKPId, record["operation"])
+	assert.Equal(t, float64(502), record["status"])
+
+	assert.Equal(t, NopLogger{}, NewSlogLogger(nil))
+}
@@ -0,0 +1,320 @@
+package alloha
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+)
+
+// ListPageInfo represents the page metadata of a list response decoded incrementally
+type ListPageInfo struct {
+	// Request status ("success" or "error")
+	Status string
+	// Error information (only for the "error" status)
+	ErrorInfo string
+	// Next page (optional)
+	NextPage NullInt32
+	// Previous page (optional)
+	PrevPage NullInt32
+	// Number of the list items passed to the callback
+	Items int
+	// Issues found while decoding the list items in the DecodeModeStrictWarn mode
+	Warnings []*DecodeIssue
+	// Inconsistently typed fields of the list items that were coerced or dropped while decoding
+	Anomalies []*DecodeIssue
+}
+
+// Err returns *APIError if the page has the "error" status
+func (i *ListPageInfo) Err() error {
+	return statusError(i.Status, i.ErrorInfo)
+}
+
+//region - Public Methods
+
+// StreamListOfLatestSeries requests a list of latest series and passes every list item to the callback as soon as
+// it is decoded, without buffering the whole page. If the callback returns an error, decoding stops and the error
+// is returned.
+func (c *APIClient) StreamListOfLatestSeries(ctx context.Context, pageNum int, fn func(series *SeriesData) error) (*ListPageInfo, error) {
+	if pageNum <= 0 {
+		return nil, InvalidPageNumberParameterError
+	}
+	if fn == nil {
+		return nil, NilCallbackParameterError
+	}
+
+	var err error
+	var parsedBaseURL *url.URL
+
+	parsedBaseURL, err = url.Parse(c.baseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	queryValues := parsedBaseURL.Query()
+	queryValues.Set("last", "serial")
+	queryValues.Set("order", "date")
+	queryValues.Set("page", strconv.Itoa(pageNum))
+	parsedBaseURL.RawQuery = queryValues.Encode()
+
+	return c.streamList(ctx, OperationStreamListOfLatestSeries, parsedBaseURL.String(), reflect.TypeOf(SeriesData{}), func(itemBytes []byte) ([]*DecodeIssue, error) {
+		series := &SeriesData{}
+		anomalies, err := decodeLenient(itemBytes, (*lenientSeriesData)(series))
+		if err != nil {
+			return nil, err
+		}
+
+		return anomalies, fn(series)
+	})
+}
+
+// StreamListByName searches a list of movies by name and passes every list item to the callback as soon as it is
+// decoded, without buffering the whole page. If the callback returns an error, decoding stops and the error is
+// returned.
+func (c *APIClient) StreamListByName(ctx context.Context, movieName string, fn func(movie *MovieSearchData) error) (*ListPageInfo, error) {
+	if len(movieName) <= 0 {
+		return nil, EmptyMovieNameParameterError
+	}
+	if fn == nil {
+		return nil, NilCallbackParameterError
+	}
+
+	var err error
+	var parsedBaseURL *url.URL
+
+	parsedBaseURL, err = url.Parse(c.baseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	queryValues := parsedBaseURL.Query()
+	queryValues.Set("name", movieName)
+	queryValues.Set("list", "1")
+	parsedBaseURL.RawQuery = queryValues.Encode()
+
+	return c.streamList(ctx, OperationStreamListByName, parsedBaseURL.String(), reflect.TypeOf(MovieSearchData{}), func(itemBytes []byte) ([]*DecodeIssue, error) {
+		movie := &MovieSearchData{}
+		anomalies, err := decodeLenient(itemBytes, (*lenientMovieSearchData)(movie))
+		if err != nil {
+			return nil, err
+		}
+
+		return anomalies, fn(movie)
+	})
+}
+
+//endregion
+
+//region - Private Methods
+
+// streamList executes the GET request of the specified operation to the specified URL and decodes the list response
+// token by token. Every item of the "data" array is checked according to the client decode mode and passed to
+// the item callback, the anomalies returned by the item callback are added to the page info.
+func (c *APIClient) streamList(ctx context.Context, operation, endpointApiUrl string, itemType reflect.Type, onItem func(itemBytes []byte) ([]*DecodeIssue, error)) (pageInfo *ListPageInfo, err error) {
+	var respReader io.ReadCloser
+	var statusCode int
+
+	ctx, request := c.startApiRequest(ctx, operation, endpointApiUrl)
+	defer func() { c.finishApiRequest(ctx, request, err) }()
+
+	respReader, statusCode, err = c.openApiResponse(ctx, request, http.MethodGet, endpointApiUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer c.closeResponseReader(ctx, respReader)
+
+	if statusCode != 200 {
+		return nil, &UnexpectedStatusCodeError{StatusCode: statusCode}
+	}
+
+	decoder := json.NewDecoder(respReader)
+
+	token, err := decoder.Token()
+	if err == io.EOF {
+		return nil, &EmptyResponseBodyError{StatusCode: statusCode}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return nil, fmt.Errorf("unexpected list response token: %v", token)
+	}
+
+	pageInfo = &ListPageInfo{}
+
+	for decoder.More() {
+		token, err = decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, _ := token.(string)
+
+		switch key {
+		case "status":
+			err = decoder.Decode(&pageInfo.Status)
+		case "error_info":
+			err = decoder.Decode(&pageInfo.ErrorInfo)
+		case "next_page":
+			err = decoder.Decode(&pageInfo.NextPage)
+		case "prev_page":
+			err = decoder.Decode(&pageInfo.PrevPage)
+		case "data":
+			err = c.streamListItems(decoder, pageInfo, itemType, onItem)
+		default:
+			var skipped json.RawMessage
+			err = decoder.Decode(&skipped)
+			if err == nil && c.decodeMode != DecodeModeDefault {
+				err = c.addStreamIssues(pageInfo, []*DecodeIssue{{
+					Kind:   DecodeIssueUnknownField,
+					Path:   "$." + key,
+					Actual: rawJSONKind(skipped),
+				}})
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err = decoder.Token(); err != nil {
+		return nil, err
+	}
+	request.apiStatus = pageInfo.Status
+
+	return pageInfo, nil
+}
+
+// streamListItems decodes the items of the "data" array one by one and passes them to the item callback
+func (c *APIClient) streamListItems(decoder *json.Decoder, pageInfo *ListPageInfo, itemType reflect.Type, onItem func(itemBytes []byte) ([]*DecodeIssue, error)) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+
+	switch token {
+	case nil:
+		// The API returns null instead of an empty list for some queries
+		return nil
+	case json.Delim('['):
+	default:
+		return fmt.Errorf("unexpected list data token: %v", token)
+	}
+
+	for i := 0; decoder.More(); i++ {
+		var itemBytes json.RawMessage
+		if err = decoder.Decode(&itemBytes); err != nil {
+			return err
+		}
+
+		if c.decodeMode != DecodeModeDefault {
+			issues, inspectErr := inspectJSON(itemBytes, itemType)
+			if inspectErr != nil {
+				return inspectErr
+			}
+			if err = c.addStreamIssues(pageInfo, prefixIssues(fmt.Sprintf("$.data[%d]", i), issues)); err != nil {
+				return err
+			}
+		}
+
+		var anomalies []*DecodeIssue
+		anomalies, err = onItem(itemBytes)
+		pageInfo.Anomalies = append(pageInfo.Anomalies, prefixIssues(fmt.Sprintf("$.data[%d]", i), anomalies)...)
+		if err != nil {
+			return err
+		}
+		pageInfo.Items++
+	}
+
+	_, err = decoder.Token()
+
+	return err
+}
+
+// addStreamIssues adds the found issues to the page warnings, or returns an error in the DecodeModeStrictError mode
+func (c *APIClient) addStreamIssues(pageInfo *ListPageInfo, issues []*DecodeIssue) error {
+	if len(issues) == 0 {
+		return nil
+	}
+	if c.decodeMode == DecodeModeStrictError {
+		return &StrictDecodeError{Issues: issues}
+	}
+
+	pageInfo.Warnings = append(pageInfo.Warnings, issues...)
+
+	return nil
+}
+
+// rawJSONKind returns the name of the JSON type of the raw value
+func rawJSONKind(raw json.RawMessage) string {
+	var value interface{}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return "invalid"
+	}
+
+	return jsonKindOf(value)
+}
+
+// responseBody is a response stream that closes all underlying readers
+type responseBody struct {
+	io.Reader
+	wire    *limitedReader
+	closers []io.Closer
+}
+
+// decodedBytes returns the number of decoded bytes read from the stream
+func (b *responseBody) decodedBytes() int64 {
+	if reader, ok := b.Reader.(*limitedReader); ok {
+		return reader.read
+	}
+
+	return 0
+}
+
+// Close implements the io.Closer interface
+func (b *responseBody) Close() error {
+	var firstErr error
+	for _, closer := range b.closers {
+		if err := closer.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+// limitedReader reads from the underlying reader and fails with ResponseTooLargeError once more than limit bytes
+// are read
+type limitedReader struct {
+	reader io.Reader
+	limit  int64
+	read   int64
+}
+
+// newLimitedReader creates a new limitedReader instance
+func newLimitedReader(reader io.Reader, limit int64) *limitedReader {
+	return &limitedReader{reader: reader, limit: limit}
+}
+
+// Read implements the io.Reader interface
+func (l *limitedReader) Read(p []byte) (int, error) {
+	// Read at most one byte over the limit, which is enough to detect the overflow
+	if remaining := l.limit - l.read + 1; int64(len(p)) > remaining {
+		p = p[:remaining]
+	}
+
+	n, err := l.reader.Read(p)
+	l.read += int64(n)
+	if l.read > l.limit {
+		return n - int(l.read-l.limit), &ResponseTooLargeError{Limit: l.limit}
+	}
+
+	return n, err
+}
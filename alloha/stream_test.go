@@ -0,0 +1,231 @@
+package alloha
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIClient_SetMaxResponseSize(t *testing.T) {
+	tests := []struct {
+		name            string
+		maxResponseSize int64
+		expectedErr     error
+	}{
+		{
+			name:            "zero size",
+			maxResponseSize: 0,
+			expectedErr:     InvalidMaxResponseSizeParameterError,
+		},
+		{
+			name:            "negative size",
+			maxResponseSize: -1,
+			expectedErr:     InvalidMaxResponseSizeParameterError,
+		},
+		{
+			name:            "valid size",
+			maxResponseSize: 1 << 10,
+			expectedErr:     nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, errClient := NewAPIClient(http.DefaultClient, "test-api-token", "https://example.com")
+			assert.NoError(t, errClient)
+
+			err := client.SetMaxResponseSize(tt.maxResponseSize)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				assert.Equal(t, DefaultMaxResponseSize, client.maxResponseSize)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.maxResponseSize, client.maxResponseSize)
+			}
+		})
+	}
+}
+
+func TestAPIClient_FindByKPId_ResponseTooLarge(t *testing.T) {
+	body := "{\"status\":\"success\",\"data\":{\"name\":\"" + strings.Repeat("a", 4096) + "\"}}"
+
+	var gzipBody bytes.Buffer
+	gzipWriter := gzip.NewWriter(&gzipBody)
+	_, _ = gzipWriter.Write([]byte(body))
+	_ = gzipWriter.Close()
+
+	tests := []struct {
+		name            string
+		contentEncoding string
+		responseBody    []byte
+		maxResponseSize int64
+		expectedErr     bool
+	}{
+		{
+			name:            "plain body within the limit",
+			responseBody:    []byte(body),
+			maxResponseSize: int64(len(body)),
+			expectedErr:     false,
+		},
+		{
+			name:            "plain body over the limit",
+			responseBody:    []byte(body),
+			maxResponseSize: int64(len(body)) - 1,
+			expectedErr:     true,
+		},
+		{
+			name:            "compressed body within the limit decompresses over the limit",
+			contentEncoding: "gzip",
+			responseBody:    gzipBody.Bytes(),
+			maxResponseSize: int64(gzipBody.Len()),
+			expectedErr:     true,
+		},
+		{
+			name:            "compressed body over the limit",
+			contentEncoding: "gzip",
+			responseBody:    gzipBody.Bytes(),
+			maxResponseSize: int64(gzipBody.Len()) - 1,
+			expectedErr:     true,
+		},
+		{
+			name:            "compressed body within the limit",
+			contentEncoding: "gzip",
+			responseBody:    gzipBody.Bytes(),
+			maxResponseSize: int64(len(body)),
+			expectedErr:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				// Возвращаем тестовые данные
+				if len(tt.contentEncoding) > 0 {
+					w.Header().Set("Content-Encoding", tt.contentEncoding)
+				}
+				w.WriteHeader(http.StatusOK)
+				_, errWrite := w.Write(tt.responseBody)
+				if errWrite != nil {
+					t.Errorf("failed to write data to response: %v", errWrite)
+				}
+			}))
+			defer ts.Close()
+
+			client, errClient := NewAPIClient(ts.Client(), "test-api-token", ts.URL)
+			assert.NoError(t, errClient)
+			assert.NoError(t, client.SetMaxResponseSize(tt.maxResponseSize))
+
+			movie, errMovie := client.FindByKPId(t.Context(), 342)
+
+			if tt.expectedErr {
+				var tooLargeErr *ResponseTooLargeError
+				assert.ErrorAs(t, errMovie, &tooLargeErr)
+				assert.Equal(t, tt.maxResponseSize, tooLargeErr.Limit)
+				assert.Nil(t, movie)
+			} else {
+				assert.NoError(t, errMovie)
+				assert.Len(t, movie.Data.Name, 4096)
+			}
+		})
+	}
+}
+
+func TestAPIClient_StreamListOfLatestSeries(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Проверяем наличие конкретных параметров в URL
+		assert.Equal(t, "serial", r.URL.Query().Get("last"))
+		assert.Equal(t, "date", r.URL.Query().Get("order"))
+		assert.Equal(t, "2", r.URL.Query().Get("page"))
+
+		// Возвращаем тестовые данные
+		w.WriteHeader(http.StatusOK)
+		_, errWrite := io.WriteString(w, "{\"status\":\"success\",\"data\":[{\"season\":1,\"episode\":10,\"name\":\"Пульс\","+
+			"\"id_kp\":5600611,\"skip_time\":null},{\"season\":\"3\",\"episode\":2,\"name\":\"Бригада\",\"id_kp\":77044}],"+
+			"\"next_page\":3,\"prev_page\":1}")
+		if errWrite != nil {
+			t.Errorf("failed to write data to response: %v", errWrite)
+		}
+	}))
+	defer ts.Close()
+
+	// Создаем клиент с тестовым сервером
+	client, err := NewAPIClient(ts.Client(), "test-api-key", ts.URL)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	assert.NoError(t, client.SetDecodeMode(DecodeModeStrictWarn))
+
+	var names []string
+	pageInfo, errStream := client.StreamListOfLatestSeries(t.Context(), 2, func(series *SeriesData) error {
+		names = append(names, series.Name)
+		return nil
+	})
+
+	// Проверяем результат
+	assert.NoError(t, errStream)
+	assert.Equal(t, []string{"Пульс", "Бригада"}, names)
+	assert.Equal(t, "success", pageInfo.Status)
+	assert.Equal(t, 2, pageInfo.Items)
+	assert.Equal(t, int32(3), pageInfo.NextPage.Int32)
+	assert.Equal(t, int32(1), pageInfo.PrevPage.Int32)
+	assert.Equal(t, []string{
+		"$.data[0].skip_time: unknown field of type null",
+		"$.data[1].season: type mismatch, expected integer, got string",
+	}, issueStrings(pageInfo.Warnings))
+	assert.Equal(t, []string{
+		"$.data[1].season: type mismatch, expected integer, got string",
+	}, issueStrings(pageInfo.Anomalies))
+}
+
+func TestAPIClient_StreamListByName_StopOnCallbackError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Возвращаем тестовые данные
+		w.WriteHeader(http.StatusOK)
+		_, errWrite := io.WriteString(w, "{\"status\":\"success\",\"data\":[{\"name\":\"Бригада\",\"id_kp\":77044},"+
+			"{\"name\":\"Бригада: Наследник\",\"id_kp\":1045172}]}")
+		if errWrite != nil {
+			t.Errorf("failed to write data to response: %v", errWrite)
+		}
+	}))
+	defer ts.Close()
+
+	// Создаем клиент с тестовым сервером
+	client, err := NewAPIClient(ts.Client(), "test-api-key", ts.URL)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	stopErr := errors.New("stop")
+	var ids []int
+	pageInfo, errStream := client.StreamListByName(t.Context(), "Бригада", func(movie *MovieSearchData) error {
+		ids = append(ids, movie.IDKp)
+		return stopErr
+	})
+
+	// Проверяем результат
+	assert.ErrorIs(t, errStream, stopErr)
+	assert.Nil(t, pageInfo)
+	assert.Equal(t, []int{77044}, ids)
+}
+
+func TestAPIClient_StreamListByName_InvalidParameters(t *testing.T) {
+	client, err := NewAPIClient(http.DefaultClient, "test-api-key", "https://example.com")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	_, errEmptyName := client.StreamListByName(t.Context(), "", func(movie *MovieSearchData) error { return nil })
+	_, errNilCallback := client.StreamListByName(t.Context(), "Бригада", nil)
+
+	// Проверяем результат
+	assert.ErrorIs(t, errEmptyName, EmptyMovieNameParameterError)
+	assert.ErrorIs(t, errNilCallback, NilCallbackParameterError)
+}
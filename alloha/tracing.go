@@ -0,0 +1,140 @@
+package alloha
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// Keys of the span attributes set by the API client
+const (
+	AttributeOperation  = "alloha.operation"
+	AttributeIDKind     = "alloha.id_kind"
+	AttributePage       = "alloha.page"
+	AttributeStatusCode = "http.response.status_code"
+	AttributeAPIStatus  = "alloha.api_status"
+	AttributeRetries    = "alloha.retries"
+	AttributeCacheHit   = "alloha.cache_hit"
+)
+
+// Kinds of the movie identifier passed in the AttributeIDKind attribute
+const (
+	IDKindKP   = "kp"
+	IDKindIMDb = "imdb"
+	IDKindTMDb = "tmdb"
+	IDKindName = "name"
+)
+
+// Attribute is a key-value pair describing a span. The value is a string, an int, an int64, a float64 or a bool.
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// Span represents a single traced API operation
+type Span interface {
+	// SetAttributes sets the attributes of the span
+	SetAttributes(attributes ...Attribute)
+	// RecordError marks the span as failed with the error
+	RecordError(err error)
+	// End completes the span
+	End()
+}
+
+// Tracer provides an interface for tracing the API operations, e.g. with OpenTelemetry. Implementations must be safe
+// for concurrent use.
+type Tracer interface {
+	// Start starts a span of the operation and returns the context carrying it
+	Start(ctx context.Context, operation string) (context.Context, Span)
+	// Inject writes the trace context carried by the context into the outgoing request headers
+	Inject(ctx context.Context, header http.Header)
+}
+
+// NopTracer is a Tracer that records nothing, it is used by default
+type NopTracer struct{}
+
+// Start implements the Tracer interface
+func (NopTracer) Start(ctx context.Context, operation string) (context.Context, Span) {
+	return ctx, nopSpan{}
+}
+
+// Inject implements the Tracer interface
+func (NopTracer) Inject(ctx context.Context, header http.Header) {}
+
+// nopSpan is a Span that records nothing
+type nopSpan struct{}
+
+// SetAttributes implements the Span interface
+func (nopSpan) SetAttributes(attributes ...Attribute) {}
+
+// RecordError implements the Span interface
+func (nopSpan) RecordError(err error) {}
+
+// End implements the Span interface
+func (nopSpan) End() {}
+
+//region - Public Methods
+
+// SetTracer sets the tracer that receives a span for every API operation. Passing nil disables tracing.
+func (c *APIClient) SetTracer(tracer Tracer) {
+	if tracer == nil {
+		tracer = NopTracer{}
+	}
+
+	c.tracer = tracer
+}
+
+//endregion
+
+//region - Private Methods
+
+// startSpan starts the span of the API request and sets the attributes known before the request is executed
+func (c *APIClient) startSpan(ctx context.Context, request *apiRequest, endpointURL *url.URL) context.Context {
+	ctx, request.span = c.tracer.Start(ctx, request.operation)
+
+	attributes := []Attribute{{Key: AttributeOperation, Value: request.operation}}
+	if endpointURL != nil {
+		queryValues := endpointURL.Query()
+		for _, idKind := range []string{IDKindKP, IDKindIMDb, IDKindTMDb, IDKindName} {
+			if _, found := queryValues[idKind]; found {
+				attributes = append(attributes, Attribute{Key: AttributeIDKind, Value: idKind})
+				break
+			}
+		}
+		if page, err := strconv.Atoi(queryValues.Get("page")); err == nil {
+			attributes = append(attributes, Attribute{Key: AttributePage, Value: page})
+		}
+	}
+	request.span.SetAttributes(attributes...)
+
+	return ctx
+}
+
+// endSpan sets the result of the API request on the span and ends it. The client always calls the server, so the
+// cache hit attribute is false.
+func (c *APIClient) endSpan(request *apiRequest, err error) {
+	retries := 0
+	if request.attempts() > 1 {
+		retries = request.attempts() - 1
+	}
+
+	attributes := []Attribute{
+		{Key: AttributeRetries, Value: retries},
+		{Key: AttributeCacheHit, Value: false},
+	}
+	if request.statusCode != 0 {
+		attributes = append(attributes, Attribute{Key: AttributeStatusCode, Value: request.statusCode})
+	}
+	if len(request.apiStatus) > 0 {
+		attributes = append(attributes, Attribute{Key: AttributeAPIStatus, Value: request.apiStatus})
+	}
+	request.span.SetAttributes(attributes...)
+
+	if err != nil {
+		request.span.RecordError(err)
+	}
+	request.span.End()
+}
+
+//endregion
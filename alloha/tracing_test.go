@@ -0,0 +1,147 @@
+package alloha
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingSpan is a Span that keeps all attributes in memory
+type recordingSpan struct {
+	operation  string
+	attributes map[string]interface{}
+	err        error
+	ended      bool
+}
+
+// SetAttributes implements the Span interface
+func (s *recordingSpan) SetAttributes(attributes ...Attribute) {
+	for _, attribute := range attributes {
+		s.attributes[attribute.Key] = attribute.Value
+	}
+}
+
+// RecordError implements the Span interface
+func (s *recordingSpan) RecordError(err error) {
+	s.err = err
+}
+
+// End implements the Span interface
+func (s *recordingSpan) End() {
+	s.ended = true
+}
+
+// recordingTracer is a Tracer that keeps all spans in memory
+type recordingTracer struct {
+	spans []*recordingSpan
+}
+
+// Start implements the Tracer interface
+func (t *recordingTracer) Start(ctx context.Context, operation string) (context.Context, Span) {
+	span := &recordingSpan{operation: operation, attributes: make(map[string]interface{})}
+	t.spans = append(t.spans, span)
+
+	return ctx, span
+}
+
+// Inject implements the Tracer interface
+func (t *recordingTracer) Inject(ctx context.Context, header http.Header) {
+	header.Set("Traceparent", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+}
+
+func TestAPIClient_SetTracer(t *testing.T) {
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Проверяем заголовки запроса
+		assert.Equal(t, "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01", r.Header.Get("Traceparent"))
+
+		requests++
+		if requests == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+
+		// Возвращаем тестовые данные
+		w.WriteHeader(http.StatusOK)
+		_, errWrite := io.WriteString(w, "{\"status\":\"success\",\"data\":{\"name\":\"Бригада\",\"id_kp\":77044}}")
+		if errWrite != nil {
+			t.Errorf("failed to write data to response: %v", errWrite)
+		}
+	}))
+	defer ts.Close()
+
+	// Создаем клиент с тестовым сервером
+	client, err := NewAPIClient(ts.Client(), "test-api-key", ts.URL)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	tracer := &recordingTracer{}
+	client.SetTracer(tracer)
+	client.Use(RetryMiddleware(2, time.Millisecond))
+
+	_, errMovie := client.FindByTMDbId(t.Context(), 1399)
+
+	// Проверяем результат
+	assert.NoError(t, errMovie)
+	if assert.Len(t, tracer.spans, 1) {
+		span := tracer.spans[0]
+		assert.Equal(t, OperationFindByTMDbId, span.operation)
+		assert.True(t, span.ended)
+		assert.NoError(t, span.err)
+		assert.Equal(t, map[string]interface{}{
+			AttributeOperation:  OperationFindByTMDbId,
+			AttributeIDKind:     IDKindTMDb,
+			AttributeStatusCode: http.StatusOK,
+			AttributeAPIStatus:  "success",
+			AttributeRetries:    1,
+			AttributeCacheHit:   false,
+		}, span.attributes)
+	}
+}
+
+func TestAPIClient_SetTracer_Error(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	// Создаем клиент с тестовым сервером
+	client, err := NewAPIClient(ts.Client(), "test-api-key", ts.URL)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	tracer := &recordingTracer{}
+	client.SetTracer(tracer)
+
+	_, errList := client.GetListOfLatestSeries(t.Context(), 3)
+
+	// Проверяем результат
+	assert.Error(t, errList)
+	if assert.Len(t, tracer.spans, 1) {
+		span := tracer.spans[0]
+		assert.True(t, span.ended)
+		assert.Equal(t, errList, span.err)
+		assert.Equal(t, 3, span.attributes[AttributePage])
+		assert.Equal(t, http.StatusServiceUnavailable, span.attributes[AttributeStatusCode])
+		assert.NotContains(t, span.attributes, AttributeIDKind)
+	}
+}
+
+func TestAPIClient_SetTracer_Nil(t *testing.T) {
+	client, err := NewAPIClient(http.DefaultClient, "test-api-key", "https://api.alloha.tv")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	client.SetTracer(nil)
+
+	// Проверяем результат
+	assert.Equal(t, NopTracer{}, client.tracer)
+}
@@ -25,8 +25,19 @@ func (n *NullInt32) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
+// MarshalJSON implements the json.Marshaler interface
+func (n NullInt32) MarshalJSON() ([]byte, error) {
+	if !n.Valid {
+		return []byte("null"), nil
+	}
+
+	return json.Marshal(n.Int32)
+}
+
 // FindOneResponse represents the structure of the API response to searching for data by ID
 type FindOneResponse struct {
+	responseMeta
+
 	// Request status ("success" or "error")
 	Status string `json:"status"`
 	// Error information (only for the "error" status)
@@ -37,6 +48,8 @@ type FindOneResponse struct {
 
 // FindListResponse represents a structure for processing the API response to data search
 type FindListResponse struct {
+	responseMeta
+
 	// Request status ("success" or "error")
 	Status string `json:"status"`
 	// Error information (only for the "error" status)
@@ -51,6 +64,8 @@ type FindListResponse struct {
 
 // ListOfLatestSeriesResponse represents a structure for handling an API response to a list of the latest episodes of a TV series
 type ListOfLatestSeriesResponse struct {
+	responseMeta
+
 	// Request status ("success" or "error")
 	Status string `json:"status"`
 	// Error information (only for the "error" status)
@@ -63,6 +78,30 @@ type ListOfLatestSeriesResponse struct {
 	PrevPage NullInt32 `json:"prev_page"`
 }
 
+// Err returns *APIError if the response has the "error" status
+func (r *FindOneResponse) Err() error {
+	return statusError(r.Status, r.ErrorInfo)
+}
+
+// Err returns *APIError if the response has the "error" status
+func (r *FindListResponse) Err() error {
+	return statusError(r.Status, r.ErrorInfo)
+}
+
+// Err returns *APIError if the response has the "error" status
+func (r *ListOfLatestSeriesResponse) Err() error {
+	return statusError(r.Status, r.ErrorInfo)
+}
+
+// statusError returns *APIError for the "error" status
+func statusError(status, errorInfo string) error {
+	if status != "error" {
+		return nil
+	}
+
+	return &APIError{ErrorInfo: errorInfo}
+}
+
 // MovieData represents the structure of information about a movie or TV series
 type MovieData struct {
 	Name                  string                       `json:"name"`
@@ -0,0 +1,48 @@
+package alloha
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNullInt32_MarshalJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		value NullInt32
+		want  string
+	}{
+		{name: "valid", value: NullInt32{Int32: 77044, Valid: true}, want: "77044"},
+		{name: "null", value: NullInt32{}, want: "null"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.value)
+
+			// Проверяем результат
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, string(data))
+
+			var decoded NullInt32
+			assert.NoError(t, json.Unmarshal(data, &decoded))
+			assert.Equal(t, tt.value, decoded)
+		})
+	}
+}
+
+func TestFindOneResponse_Err(t *testing.T) {
+	notFound := (&FindOneResponse{Status: "error", ErrorInfo: ErrorInfoNotFound}).Err()
+	invalidToken := (&FindListResponse{Status: "error", ErrorInfo: "not valid token"}).Err()
+
+	// Проверяем результат
+	assert.NoError(t, (&FindOneResponse{Status: "success"}).Err())
+	assert.NoError(t, (&ListOfLatestSeriesResponse{Status: "success"}).Err())
+	assert.EqualError(t, notFound, "api responded with an error: not movie")
+	assert.True(t, IsNotFound(notFound))
+	assert.False(t, IsNotFound(invalidToken))
+	assert.True(t, IsNotFound(&UnexpectedStatusCodeError{StatusCode: 404}))
+	assert.False(t, IsNotFound(&UnexpectedStatusCodeError{StatusCode: 500}))
+	assert.Error(t, (&ListPageInfo{Status: "error"}).Err())
+}
@@ -0,0 +1,169 @@
+// Package allohamock provides a programmable implementation of the alloha.API interface that records all calls.
+package allohamock
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/electromystyle/alloha-sdk-go/alloha"
+)
+
+// NotProgrammedError is returned by the methods without a programmed function
+var NotProgrammedError = errors.New("allohamock: the method is not programmed")
+
+// Call represents a recorded call of the Mock. The context and the callbacks are not recorded.
+type Call struct {
+	// Name of the method, e.g. "FindByKPId"
+	Method string
+	// Arguments of the call, e.g. [77044]
+	Args []interface{}
+}
+
+// Mock implements the alloha.API interface. Every method calls the corresponding function, if it is set, and returns
+// NotProgrammedError otherwise. It is safe for concurrent use.
+type Mock struct {
+	FindByIMDbIdFunc             func(ctx context.Context, imdbId string) (*alloha.FindOneResponse, error)
+	FindByKPIdFunc               func(ctx context.Context, kpId int) (*alloha.FindOneResponse, error)
+	FindByTMDbIdFunc             func(ctx context.Context, tmdbId int) (*alloha.FindOneResponse, error)
+	GetListOfLatestSeriesFunc    func(ctx context.Context, pageNum int) (*alloha.ListOfLatestSeriesResponse, error)
+	SearchForOneByNameFunc       func(ctx context.Context, movieName string) (*alloha.FindOneResponse, error)
+	SearchListByNameFunc         func(ctx context.Context, movieName string) (*alloha.FindListResponse, error)
+	StreamListByNameFunc         func(ctx context.Context, movieName string, fn func(movie *alloha.MovieSearchData) error) (*alloha.ListPageInfo, error)
+	StreamListOfLatestSeriesFunc func(ctx context.Context, pageNum int, fn func(series *alloha.SeriesData) error) (*alloha.ListPageInfo, error)
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+var _ alloha.API = (*Mock)(nil)
+
+//region - Public Methods
+
+// FindByIMDbId implements the alloha.API interface
+func (m *Mock) FindByIMDbId(ctx context.Context, imdbId string) (*alloha.FindOneResponse, error) {
+	m.record(alloha.OperationFindByIMDbId, imdbId)
+	if m.FindByIMDbIdFunc == nil {
+		return nil, NotProgrammedError
+	}
+
+	return m.FindByIMDbIdFunc(ctx, imdbId)
+}
+
+// FindByKPId implements the alloha.API interface
+func (m *Mock) FindByKPId(ctx context.Context, kpId int) (*alloha.FindOneResponse, error) {
+	m.record(alloha.OperationFindByKPId, kpId)
+	if m.FindByKPIdFunc == nil {
+		return nil, NotProgrammedError
+	}
+
+	return m.FindByKPIdFunc(ctx, kpId)
+}
+
+// FindByTMDbId implements the alloha.API interface
+func (m *Mock) FindByTMDbId(ctx context.Context, tmdbId int) (*alloha.FindOneResponse, error) {
+	m.record(alloha.OperationFindByTMDbId, tmdbId)
+	if m.FindByTMDbIdFunc == nil {
+		return nil, NotProgrammedError
+	}
+
+	return m.FindByTMDbIdFunc(ctx, tmdbId)
+}
+
+// GetListOfLatestSeries implements the alloha.API interface
+func (m *Mock) GetListOfLatestSeries(ctx context.Context, pageNum int) (*alloha.ListOfLatestSeriesResponse, error) {
+	m.record(alloha.OperationGetListOfLatestSeries, pageNum)
+	if m.GetListOfLatestSeriesFunc == nil {
+		return nil, NotProgrammedError
+	}
+
+	return m.GetListOfLatestSeriesFunc(ctx, pageNum)
+}
+
+// SearchForOneByName implements the alloha.API interface
+func (m *Mock) SearchForOneByName(ctx context.Context, movieName string) (*alloha.FindOneResponse, error) {
+	m.record(alloha.OperationSearchForOneByName, movieName)
+	if m.SearchForOneByNameFunc == nil {
+		return nil, NotProgrammedError
+	}
+
+	return m.SearchForOneByNameFunc(ctx, movieName)
+}
+
+// SearchListByName implements the alloha.API interface
+func (m *Mock) SearchListByName(ctx context.Context, movieName string) (*alloha.FindListResponse, error) {
+	m.record(alloha.OperationSearchListByName, movieName)
+	if m.SearchListByNameFunc == nil {
+		return nil, NotProgrammedError
+	}
+
+	return m.SearchListByNameFunc(ctx, movieName)
+}
+
+// StreamListByName implements the alloha.API interface
+func (m *Mock) StreamListByName(ctx context.Context, movieName string, fn func(movie *alloha.MovieSearchData) error) (*alloha.ListPageInfo, error) {
+	m.record(alloha.OperationStreamListByName, movieName)
+	if m.StreamListByNameFunc == nil {
+		return nil, NotProgrammedError
+	}
+
+	return m.StreamListByNameFunc(ctx, movieName, fn)
+}
+
+// StreamListOfLatestSeries implements the alloha.API interface
+func (m *Mock) StreamListOfLatestSeries(ctx context.Context, pageNum int, fn func(series *alloha.SeriesData) error) (*alloha.ListPageInfo, error) {
+	m.record(alloha.OperationStreamListOfLatestSeries, pageNum)
+	if m.StreamListOfLatestSeriesFunc == nil {
+		return nil, NotProgrammedError
+	}
+
+	return m.StreamListOfLatestSeriesFunc(ctx, pageNum, fn)
+}
+
+// Calls returns all recorded calls in the order they were made
+func (m *Mock) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	calls := make([]Call, len(m.calls))
+	copy(calls, m.calls)
+
+	return calls
+}
+
+// CallsTo returns the recorded calls of the method, e.g. "FindByKPId"
+func (m *Mock) CallsTo(method string) []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var calls []Call
+	for _, call := range m.calls {
+		if call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+
+	return calls
+}
+
+// Reset removes all recorded calls
+func (m *Mock) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls = nil
+}
+
+//endregion
+
+//region - Private Methods
+
+// record records the call of the method
+func (m *Mock) record(method string, args ...interface{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls = append(m.calls, Call{Method: method, Args: args})
+}
+
+//endregion
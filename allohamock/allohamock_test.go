@@ -0,0 +1,76 @@
+package allohamock
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/electromystyle/alloha-sdk-go/alloha"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMock(t *testing.T) {
+	mock := &Mock{
+		FindByKPIdFunc: func(ctx context.Context, kpId int) (*alloha.FindOneResponse, error) {
+			return &alloha.FindOneResponse{Status: "success", Data: &alloha.MovieData{IDKp: kpId, Name: "Бригада"}}, nil
+		},
+		StreamListOfLatestSeriesFunc: func(ctx context.Context, pageNum int, fn func(series *alloha.SeriesData) error) (*alloha.ListPageInfo, error) {
+			_ = fn(&alloha.SeriesData{Season: 1, Episode: 2})
+			return &alloha.ListPageInfo{Status: "success", Items: 1}, nil
+		},
+	}
+
+	var api alloha.API = mock
+	ctx := context.Background()
+
+	movie, err := api.FindByKPId(ctx, 77044)
+	assert.NoError(t, err)
+	assert.Equal(t, "Бригада", movie.Data.Name)
+
+	var episodes []int
+	info, err := api.StreamListOfLatestSeries(ctx, 1, func(series *alloha.SeriesData) error {
+		episodes = append(episodes, series.Episode)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, info.Items)
+	assert.Equal(t, []int{2}, episodes)
+
+	_, err = api.SearchListByName(ctx, "Бригада")
+	assert.ErrorIs(t, err, NotProgrammedError)
+
+	// Проверяем записанные вызовы
+	assert.Equal(t, []Call{
+		{Method: alloha.OperationFindByKPId, Args: []interface{}{77044}},
+		{Method: alloha.OperationStreamListOfLatestSeries, Args: []interface{}{1}},
+		{Method: alloha.OperationSearchListByName, Args: []interface{}{"Бригада"}},
+	}, mock.Calls())
+	assert.Len(t, mock.CallsTo(alloha.OperationFindByKPId), 1)
+
+	mock.Reset()
+	assert.Empty(t, mock.Calls())
+}
+
+func TestMock_Decorators(t *testing.T) {
+	calls := 0
+	mock := &Mock{
+		FindByKPIdFunc: func(ctx context.Context, kpId int) (*alloha.FindOneResponse, error) {
+			calls++
+			if calls == 1 {
+				return nil, &alloha.UnexpectedStatusCodeError{StatusCode: 502}
+			}
+			return &alloha.FindOneResponse{Status: "success", Data: &alloha.MovieData{IDKp: kpId}}, nil
+		},
+	}
+
+	api := alloha.NewCachingAPI(alloha.NewRetryAPI(mock, 2, time.Millisecond), alloha.NewMemoryCache(), time.Minute)
+
+	for i := 0; i < 3; i++ {
+		movie, err := api.FindByKPId(context.Background(), 77044)
+		assert.NoError(t, err)
+		assert.Equal(t, 77044, movie.Data.IDKp)
+	}
+
+	// Проверяем результат: одна повторная попытка, остальные вызовы из кэша
+	assert.Len(t, mock.CallsTo(alloha.OperationFindByKPId), 2)
+}
@@ -0,0 +1,275 @@
+// Package allohaprom collects the Alloha SDK metrics and exposes them in the Prometheus text exposition format
+// without any external dependency.
+package allohaprom
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/electromystyle/alloha-sdk-go/alloha"
+)
+
+var (
+	// DefaultDurationBuckets are the upper bounds of the latency histogram buckets in seconds
+	DefaultDurationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+	// DefaultSizeBuckets are the upper bounds of the response size histogram buckets in bytes
+	DefaultSizeBuckets = []float64{1 << 10, 4 << 10, 16 << 10, 64 << 10, 256 << 10, 1 << 20, 4 << 20, 16 << 20, 64 << 20}
+)
+
+var _ alloha.MetricsRecorder = (*Collector)(nil)
+
+// Collector implements the alloha.MetricsRecorder interface and serves the collected metrics as an http.Handler
+type Collector struct {
+	mu sync.Mutex
+
+	requests      *counterVec
+	retries       *counterVec
+	cacheHits     *counterVec
+	cacheMisses   *counterVec
+	duration      *histogramVec
+	responseSize  *histogramVec
+	rateLimitWait *histogramVec
+}
+
+// NewCollector creates a new Collector instance. All metric names are prefixed with the namespace, e.g.
+// "alloha_requests_total" for the "alloha" namespace.
+func NewCollector(namespace string) *Collector {
+	prefix := ""
+	if len(namespace) > 0 {
+		prefix = namespace + "_"
+	}
+
+	return &Collector{
+		requests: newCounterVec(prefix+"requests_total",
+			"Total number of API requests by operation and outcome.", "operation", "outcome"),
+		retries: newCounterVec(prefix+"retries_total",
+			"Total number of repeated API request attempts.", "operation"),
+		cacheHits: newCounterVec(prefix+"cache_hits_total",
+			"Total number of API calls served from a cache.", "operation"),
+		cacheMisses: newCounterVec(prefix+"cache_misses_total",
+			"Total number of API calls not found in a cache.", "operation"),
+		duration: newHistogramVec(prefix+"request_duration_seconds",
+			"API request latency in seconds.", DefaultDurationBuckets, "operation"),
+		responseSize: newHistogramVec(prefix+"response_size_bytes",
+			"Decoded API response size in bytes.", DefaultSizeBuckets, "operation"),
+		rateLimitWait: newHistogramVec(prefix+"rate_limit_wait_seconds",
+			"Time API requests spent waiting for the rate limiter in seconds.", DefaultDurationBuckets, "operation"),
+	}
+}
+
+// ObserveRequest implements the alloha.MetricsRecorder interface
+func (c *Collector) ObserveRequest(operation, outcome string, duration time.Duration, responseBytes int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.requests.inc(operation, outcome)
+	c.duration.observe(duration.Seconds(), operation)
+	if outcome == alloha.OutcomeSuccess || outcome == alloha.OutcomeAPIError {
+		c.responseSize.observe(float64(responseBytes), operation)
+	}
+}
+
+// IncRetry implements the alloha.MetricsRecorder interface
+func (c *Collector) IncRetry(operation string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.retries.inc(operation)
+}
+
+// IncCacheHit implements the alloha.MetricsRecorder interface
+func (c *Collector) IncCacheHit(operation string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.cacheHits.inc(operation)
+}
+
+// IncCacheMiss implements the alloha.MetricsRecorder interface
+func (c *Collector) IncCacheMiss(operation string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.cacheMisses.inc(operation)
+}
+
+// ObserveRateLimitWait implements the alloha.MetricsRecorder interface
+func (c *Collector) ObserveRateLimitWait(operation string, wait time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.rateLimitWait.observe(wait.Seconds(), operation)
+}
+
+// WriteTo writes the collected metrics in the Prometheus text exposition format
+func (c *Collector) WriteTo(w io.Writer) (int64, error) {
+	var sb strings.Builder
+
+	c.mu.Lock()
+	c.requests.write(&sb)
+	c.retries.write(&sb)
+	c.cacheHits.write(&sb)
+	c.cacheMisses.write(&sb)
+	c.duration.write(&sb)
+	c.responseSize.write(&sb)
+	c.rateLimitWait.write(&sb)
+	c.mu.Unlock()
+
+	n, err := io.WriteString(w, sb.String())
+
+	return int64(n), err
+}
+
+// ServeHTTP implements the http.Handler interface
+func (c *Collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = c.WriteTo(w)
+}
+
+// counterVec is a set of counters partitioned by the label values
+type counterVec struct {
+	name   string
+	help   string
+	labels []string
+	values map[string]float64
+}
+
+// newCounterVec creates a new counterVec instance
+func newCounterVec(name, help string, labels ...string) *counterVec {
+	return &counterVec{name: name, help: help, labels: labels, values: make(map[string]float64)}
+}
+
+// inc increments the counter with the specified label values
+func (v *counterVec) inc(labelValues ...string) {
+	v.values[formatLabels(v.labels, labelValues)]++
+}
+
+// write writes the counters in the text exposition format
+func (v *counterVec) write(sb *strings.Builder) {
+	writeHeader(sb, v.name, v.help, "counter")
+	for _, labels := range sortedKeys(v.values) {
+		fmt.Fprintf(sb, "%s%s %s\n", v.name, labels, formatFloat(v.values[labels]))
+	}
+}
+
+// histogram holds the cumulative bucket counts of a single histogram
+type histogram struct {
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// histogramVec is a set of histograms partitioned by the label values
+type histogramVec struct {
+	name    string
+	help    string
+	buckets []float64
+	labels  []string
+	values  map[string]*histogram
+}
+
+// newHistogramVec creates a new histogramVec instance
+func newHistogramVec(name, help string, buckets []float64, labels ...string) *histogramVec {
+	return &histogramVec{name: name, help: help, buckets: buckets, labels: labels, values: make(map[string]*histogram)}
+}
+
+// observe adds the value to the histogram with the specified label values
+func (v *histogramVec) observe(value float64, labelValues ...string) {
+	key := formatLabels(v.labels, labelValues)
+
+	h, found := v.values[key]
+	if !found {
+		h = &histogram{counts: make([]uint64, len(v.buckets))}
+		v.values[key] = h
+	}
+
+	for i, upperBound := range v.buckets {
+		if value <= upperBound {
+			h.counts[i]++
+		}
+	}
+	h.sum += value
+	h.count++
+}
+
+// write writes the histograms in the text exposition format
+func (v *histogramVec) write(sb *strings.Builder) {
+	writeHeader(sb, v.name, v.help, "histogram")
+	for _, labels := range sortedHistogramKeys(v.values) {
+		h := v.values[labels]
+		for i, upperBound := range v.buckets {
+			fmt.Fprintf(sb, "%s_bucket%s %d\n", v.name, withLabel(labels, "le", formatFloat(upperBound)), h.counts[i])
+		}
+		fmt.Fprintf(sb, "%s_bucket%s %d\n", v.name, withLabel(labels, "le", "+Inf"), h.count)
+		fmt.Fprintf(sb, "%s_sum%s %s\n", v.name, labels, formatFloat(h.sum))
+		fmt.Fprintf(sb, "%s_count%s %d\n", v.name, labels, h.count)
+	}
+}
+
+// writeHeader writes the HELP and TYPE lines of the metric
+func writeHeader(sb *strings.Builder, name, help, metricType string) {
+	fmt.Fprintf(sb, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(sb, "# TYPE %s %s\n", name, metricType)
+}
+
+// formatLabels formats the label pairs, e.g. {operation="FindByKPId",outcome="success"}
+func formatLabels(names, values []string) string {
+	pairs := make([]string, 0, len(names))
+	for i, name := range names {
+		value := ""
+		if i < len(values) {
+			value = values[i]
+		}
+		pairs = append(pairs, name+"=\""+escapeLabelValue(value)+"\"")
+	}
+
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// withLabel adds one more label pair to the formatted labels
+func withLabel(labels, name, value string) string {
+	pair := name + "=\"" + escapeLabelValue(value) + "\""
+	if labels == "{}" {
+		return "{" + pair + "}"
+	}
+
+	return strings.TrimSuffix(labels, "}") + "," + pair + "}"
+}
+
+// escapeLabelValue escapes the label value according to the text exposition format
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer("\\", "\\\\", "\"", "\\\"", "\n", "\\n").Replace(value)
+}
+
+// formatFloat formats the sample value according to the text exposition format
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// sortedKeys returns the formatted labels of the counters in a stable order
+func sortedKeys(values map[string]float64) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// sortedHistogramKeys returns the formatted labels of the histograms in a stable order
+func sortedHistogramKeys(values map[string]*histogram) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
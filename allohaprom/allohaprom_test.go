@@ -0,0 +1,91 @@
+package allohaprom
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/electromystyle/alloha-sdk-go/alloha"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollector_ServeHTTP(t *testing.T) {
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		// Возвращаем тестовые данные
+		w.WriteHeader(http.StatusOK)
+		_, errWrite := io.WriteString(w, "{\"status\":\"success\",\"data\":{\"name\":\"Бригада\",\"id_kp\":77044}}")
+		if errWrite != nil {
+			t.Errorf("failed to write data to response: %v", errWrite)
+		}
+	}))
+	defer ts.Close()
+
+	collector := NewCollector("alloha")
+
+	// Создаем клиент с тестовым сервером
+	client, err := alloha.NewAPIClient(ts.Client(), "test-api-key", ts.URL)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	client.SetMetricsRecorder(collector)
+	client.Use(alloha.RetryMiddleware(2, time.Millisecond))
+
+	_, errMovie := client.FindByKPId(t.Context(), 77044)
+	assert.NoError(t, errMovie)
+
+	_, errMovie = client.FindByIMDbId(t.Context(), "tt0110912")
+	assert.NoError(t, errMovie)
+
+	collector.IncCacheHit(alloha.OperationFindByKPId)
+	collector.ObserveRateLimitWait(alloha.OperationFindByKPId, 30*time.Millisecond)
+
+	recorder := httptest.NewRecorder()
+	collector.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	// Проверяем результат
+	body := recorder.Body.String()
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", recorder.Header().Get("Content-Type"))
+	assert.Contains(t, body, "# TYPE alloha_requests_total counter\n")
+	assert.Contains(t, body, "alloha_requests_total{operation=\"FindByIMDbId\",outcome=\"success\"} 1\n")
+	assert.Contains(t, body, "alloha_requests_total{operation=\"FindByKPId\",outcome=\"success\"} 1\n")
+	assert.Contains(t, body, "alloha_retries_total{operation=\"FindByKPId\"} 1\n")
+	assert.Contains(t, body, "alloha_cache_hits_total{operation=\"FindByKPId\"} 1\n")
+	assert.Contains(t, body, "# TYPE alloha_request_duration_seconds histogram\n")
+	assert.Contains(t, body, "alloha_request_duration_seconds_count{operation=\"FindByKPId\"} 1\n")
+	assert.Contains(t, body, "alloha_response_size_bytes_bucket{operation=\"FindByKPId\",le=\"1024\"} 1\n")
+	assert.Contains(t, body, "alloha_response_size_bytes_sum{operation=\"FindByKPId\"} 67\n")
+	assert.Contains(t, body, "alloha_rate_limit_wait_seconds_bucket{operation=\"FindByKPId\",le=\"0.025\"} 0\n")
+	assert.Contains(t, body, "alloha_rate_limit_wait_seconds_bucket{operation=\"FindByKPId\",le=\"0.05\"} 1\n")
+	assert.Contains(t, body, "alloha_rate_limit_wait_seconds_bucket{operation=\"FindByKPId\",le=\"+Inf\"} 1\n")
+}
+
+func TestCollector_ObserveRequest_Outcomes(t *testing.T) {
+	collector := NewCollector("")
+
+	collector.ObserveRequest(alloha.OperationSearchListByName, alloha.OutcomeHTTPError, time.Second, 0)
+	collector.ObserveRequest(alloha.OperationSearchListByName, alloha.OutcomeHTTPError, time.Second, 0)
+	collector.ObserveRequest(alloha.OperationSearchListByName, alloha.OutcomeTransportError, time.Second, 0)
+
+	var sb strings.Builder
+	_, err := collector.WriteTo(&sb)
+
+	// Проверяем результат
+	assert.NoError(t, err)
+	assert.Contains(t, sb.String(), "requests_total{operation=\"SearchListByName\",outcome=\"http_error\"} 2\n")
+	assert.Contains(t, sb.String(), "requests_total{operation=\"SearchListByName\",outcome=\"transport_error\"} 1\n")
+	assert.NotContains(t, sb.String(), "response_size_bytes_count")
+}
+
+func Test_escapeLabelValue(t *testing.T) {
+	assert.Equal(t, "a\\\"b\\\\c\\nd", escapeLabelValue("a\"b\\c\nd"))
+}
@@ -0,0 +1,531 @@
+// Package allohatest provides an in-memory fake Alloha API server for testing the applications that use the SDK.
+package allohatest
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/electromystyle/alloha-sdk-go/alloha"
+)
+
+// DefaultToken is the API token accepted by the server when no tokens are specified
+const DefaultToken = "allohatest-token"
+
+// DefaultPageSize is the default number of items on a page of the list responses
+const DefaultPageSize = 10
+
+// Error information returned by the server with the "error" status
+const (
+	ErrorInfoInvalidToken = "not valid token"
+	ErrorInfoNotFound     = alloha.ErrorInfoNotFound
+	ErrorInfoBadRequest   = "not valid request"
+)
+
+// Server is a fake Alloha API server seeded with the movie and series fixtures. It is safe for concurrent use.
+type Server struct {
+	// URL of the running server, e.g. "http://127.0.0.1:40123"
+	URL string
+
+	server *httptest.Server
+
+	mu       sync.Mutex
+	tokens   map[string]bool
+	movies   []*alloha.MovieData
+	series   []*alloha.SeriesData
+	pageSize int
+	latency  time.Duration
+	encoding string
+	faults   []fault
+	requests int
+}
+
+// Option configures the Server
+type Option func(s *Server)
+
+// fault is a one-shot failure returned instead of the next response
+type fault struct {
+	statusCode int
+	retryAfter int
+	malformed  bool
+}
+
+//region - Constructor
+
+// NewServer creates and starts a new Server instance. The server must be closed by the caller.
+func NewServer(options ...Option) *Server {
+	s := NewUnstartedServer(options...)
+	s.Start()
+
+	return s
+}
+
+// NewUnstartedServer creates a new Server instance without starting it, so that it can be used as an http.Handler
+func NewUnstartedServer(options ...Option) *Server {
+	s := &Server{
+		tokens:   map[string]bool{DefaultToken: true},
+		pageSize: DefaultPageSize,
+	}
+
+	for _, option := range options {
+		option(s)
+	}
+
+	return s
+}
+
+//endregion
+
+//region - Options
+
+// WithTokens sets the API tokens accepted by the server instead of the DefaultToken
+func WithTokens(tokens ...string) Option {
+	return func(s *Server) {
+		s.tokens = make(map[string]bool, len(tokens))
+		for _, token := range tokens {
+			s.tokens[token] = true
+		}
+	}
+}
+
+// WithMovies seeds the server with the movie fixtures
+func WithMovies(movies ...*alloha.MovieData) Option {
+	return func(s *Server) {
+		s.movies = append(s.movies, movies...)
+	}
+}
+
+// WithSeries seeds the server with the latest series episode fixtures
+func WithSeries(series ...*alloha.SeriesData) Option {
+	return func(s *Server) {
+		s.series = append(s.series, series...)
+	}
+}
+
+// WithPageSize sets the number of items on a page of the list responses
+func WithPageSize(pageSize int) Option {
+	return func(s *Server) {
+		if pageSize > 0 {
+			s.pageSize = pageSize
+		}
+	}
+}
+
+//endregion
+
+//region - Public Methods
+
+// Start starts the server created by NewUnstartedServer
+func (s *Server) Start() {
+	s.server = httptest.NewServer(s)
+	s.URL = s.server.URL
+}
+
+// Close shuts down the server
+func (s *Server) Close() {
+	if s.server != nil {
+		s.server.Close()
+	}
+}
+
+// Client returns an HTTP client configured for the server
+func (s *Server) Client() *http.Client {
+	if s.server == nil {
+		return http.DefaultClient
+	}
+
+	return s.server.Client()
+}
+
+// NewClient creates an API client connected to the server with the first accepted token
+func (s *Server) NewClient() (*alloha.APIClient, error) {
+	return alloha.NewAPIClient(s.Client(), s.token(), s.URL)
+}
+
+// AddMovies adds the movie fixtures
+func (s *Server) AddMovies(movies ...*alloha.MovieData) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.movies = append(s.movies, movies...)
+}
+
+// AddSeries adds the latest series episode fixtures
+func (s *Server) AddSeries(series ...*alloha.SeriesData) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.series = append(s.series, series...)
+}
+
+// SetLatency delays every response by the specified duration
+func (s *Server) SetLatency(latency time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.latency = latency
+}
+
+// SetContentEncoding encodes every response with the specified content coding ("gzip", "deflate" or "" for none)
+func (s *Server) SetContentEncoding(encoding string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.encoding = encoding
+}
+
+// FailNext makes the next count requests fail with the specified status code, e.g. 500 or 503
+func (s *Server) FailNext(count, statusCode int) {
+	s.addFaults(count, fault{statusCode: statusCode})
+}
+
+// ThrottleNext makes the next count requests fail with the 429 status code and the Retry-After header
+func (s *Server) ThrottleNext(count int, retryAfter time.Duration) {
+	s.addFaults(count, fault{statusCode: http.StatusTooManyRequests, retryAfter: int(retryAfter / time.Second)})
+}
+
+// MalformNext makes the next count requests return a truncated JSON body
+func (s *Server) MalformNext(count int) {
+	s.addFaults(count, fault{statusCode: http.StatusOK, malformed: true})
+}
+
+// RequestCount returns the number of requests received by the server
+func (s *Server) RequestCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.requests
+}
+
+// ServeHTTP implements the http.Handler interface
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests++
+	latency := s.latency
+	encoding := s.encoding
+	var nextFault *fault
+	if len(s.faults) > 0 {
+		nextFault = &s.faults[0]
+		s.faults = s.faults[1:]
+	}
+	s.mu.Unlock()
+
+	if latency > 0 {
+		timer := time.NewTimer(latency)
+		select {
+		case <-r.Context().Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+
+	if nextFault != nil && !nextFault.malformed {
+		if nextFault.retryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(nextFault.retryAfter))
+		}
+		w.WriteHeader(nextFault.statusCode)
+		return
+	}
+
+	body, err := json.Marshal(s.respond(r.URL.Query()))
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if nextFault != nil {
+		body = body[:len(body)/2]
+	}
+
+	writeBody(w, encoding, body)
+}
+
+//endregion
+
+//region - Private Methods
+
+// token returns the first accepted token in a stable order
+func (s *Server) token() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tokens := make([]string, 0, len(s.tokens))
+	for token := range s.tokens {
+		tokens = append(tokens, token)
+	}
+	sort.Strings(tokens)
+
+	if len(tokens) <= 0 {
+		return DefaultToken
+	}
+
+	return tokens[0]
+}
+
+// addFaults queues the fault for the next count requests
+func (s *Server) addFaults(count int, f fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := 0; i < count; i++ {
+		s.faults = append(s.faults, f)
+	}
+}
+
+// respond builds the API response to the query
+func (s *Server) respond(query map[string][]string) interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	values := func(key string) (string, bool) {
+		v, found := query[key]
+		if !found || len(v) <= 0 {
+			return "", false
+		}
+		return v[0], true
+	}
+
+	token, _ := values("token")
+	if !s.tokens[token] {
+		return errorResponse(ErrorInfoInvalidToken)
+	}
+
+	page := 1
+	if pageValue, found := values("page"); found {
+		parsedPage, err := strconv.Atoi(pageValue)
+		if err != nil || parsedPage <= 0 {
+			return errorResponse(ErrorInfoBadRequest)
+		}
+		page = parsedPage
+	}
+
+	if kp, found := values("kp"); found {
+		id, err := strconv.Atoi(kp)
+		if err != nil {
+			return errorResponse(ErrorInfoBadRequest)
+		}
+		return s.findOne(func(movie *alloha.MovieData) bool {
+			return movie.IDKp == id || (movie.AlternativeIDKp.Valid && int(movie.AlternativeIDKp.Int32) == id)
+		})
+	}
+	if imdb, found := values("imdb"); found {
+		return s.findOne(func(movie *alloha.MovieData) bool {
+			return len(movie.IDImdb) > 0 && movie.IDImdb == imdb
+		})
+	}
+	if tmdb, found := values("tmdb"); found {
+		id, err := strconv.Atoi(tmdb)
+		if err != nil {
+			return errorResponse(ErrorInfoBadRequest)
+		}
+		return s.findOne(func(movie *alloha.MovieData) bool {
+			return movie.IDTmdb.Valid && int(movie.IDTmdb.Int32) == id
+		})
+	}
+	if name, found := values("name"); found {
+		if list, _ := values("list"); list == "1" {
+			return s.searchList(name, page)
+		}
+		return s.searchOne(name)
+	}
+	if last, _ := values("last"); last == "serial" {
+		return s.latestSeries(page)
+	}
+
+	return errorResponse(ErrorInfoBadRequest)
+}
+
+// findOne returns the first movie matching the predicate
+func (s *Server) findOne(match func(movie *alloha.MovieData) bool) *alloha.FindOneResponse {
+	for _, movie := range s.movies {
+		if match(movie) {
+			return &alloha.FindOneResponse{Status: "success", Data: movie}
+		}
+	}
+
+	return errorResponse(ErrorInfoNotFound)
+}
+
+// searchOne returns the movie with the exact name or the first movie whose name contains the searched one
+func (s *Server) searchOne(name string) *alloha.FindOneResponse {
+	response := s.findOne(func(movie *alloha.MovieData) bool {
+		return strings.EqualFold(movie.Name, name) || strings.EqualFold(movie.OriginalName, name)
+	})
+	if response.Status == "success" {
+		return response
+	}
+
+	return s.findOne(func(movie *alloha.MovieData) bool {
+		return matchName(movie, name)
+	})
+}
+
+// searchList returns the page of movies whose names contain the searched one
+func (s *Server) searchList(name string, page int) interface{} {
+	var found []*alloha.MovieSearchData
+	for _, movie := range s.movies {
+		if matchName(movie, name) {
+			found = append(found, searchData(movie))
+		}
+	}
+	if len(found) <= 0 {
+		return errorResponse(ErrorInfoNotFound)
+	}
+
+	from, to, nextPage, prevPage := paginate(len(found), page, s.pageSize)
+
+	return &alloha.FindListResponse{
+		Status:   "success",
+		Data:     found[from:to],
+		NextPage: nextPage,
+		PrevPage: prevPage,
+	}
+}
+
+// latestSeries returns the page of the latest series episodes ordered by date from the newest
+func (s *Server) latestSeries(page int) interface{} {
+	series := make([]*alloha.SeriesData, len(s.series))
+	copy(series, s.series)
+	sort.SliceStable(series, func(i, j int) bool {
+		return series[i].Date > series[j].Date
+	})
+
+	from, to, nextPage, prevPage := paginate(len(series), page, s.pageSize)
+
+	return &alloha.ListOfLatestSeriesResponse{
+		Status:   "success",
+		Data:     series[from:to],
+		NextPage: nextPage,
+		PrevPage: prevPage,
+	}
+}
+
+// errorResponse builds the response with the "error" status
+func errorResponse(errorInfo string) *alloha.FindOneResponse {
+	return &alloha.FindOneResponse{Status: "error", ErrorInfo: errorInfo}
+}
+
+// matchName reports whether any name of the movie contains the searched one ignoring case
+func matchName(movie *alloha.MovieData, name string) bool {
+	name = strings.ToLower(name)
+	for _, movieName := range []string{movie.Name, movie.OriginalName, movie.AlternativeName} {
+		if len(movieName) > 0 && strings.Contains(strings.ToLower(movieName), name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// paginate returns the bounds of the page and the numbers of the neighbouring pages
+func paginate(total, page, pageSize int) (int, int, alloha.NullInt32, alloha.NullInt32) {
+	var nextPage, prevPage alloha.NullInt32
+
+	from := (page - 1) * pageSize
+	if from > total {
+		from = total
+	}
+	to := from + pageSize
+	if to > total {
+		to = total
+	}
+
+	if to < total {
+		nextPage = alloha.NullInt32{Int32: int32(page + 1), Valid: true}
+	}
+	if page > 1 {
+		prevPage = alloha.NullInt32{Int32: int32(page - 1), Valid: true}
+	}
+
+	return from, to, nextPage, prevPage
+}
+
+// searchData converts the movie fixture to the item of the search list
+func searchData(movie *alloha.MovieData) *alloha.MovieSearchData {
+	data := &alloha.MovieSearchData{
+		Name:                  movie.Name,
+		OriginalName:          movie.OriginalName,
+		AlternativeName:       movie.AlternativeName,
+		Year:                  movie.Year,
+		CategoryId:            movie.Category,
+		IDKp:                  movie.IDKp,
+		AlternativeIDKp:       movie.AlternativeIDKp,
+		IDImdb:                movie.IDImdb,
+		IDTmdb:                movie.IDTmdb,
+		IDWorldArt:            movie.IDWorldArt,
+		TokenMovie:            movie.TokenMovie,
+		Country:               movie.Country,
+		Genre:                 movie.Genre,
+		Actors:                movie.Actors,
+		Directors:             movie.Directors,
+		Producers:             movie.Producers,
+		PremiereRu:            movie.PremiereRu,
+		Premiere:              movie.Premiere,
+		AgeRestrictions:       movie.AgeRestrictions,
+		RatingMpaa:            movie.RatingMpaa,
+		RatingKp:              movie.RatingKp,
+		RatingImdb:            movie.RatingImdb,
+		Time:                  movie.Time,
+		Tagline:               movie.Tagline,
+		Poster:                movie.Poster,
+		Description:           movie.Description,
+		SeasonsCount:          movie.SeasonsCount,
+		Seasons:               movie.Seasons,
+		Quality:               movie.Quality,
+		Translation:           movie.Translation,
+		TranslationIframe:     movie.TranslationIframe,
+		Iframe:                movie.Iframe,
+		IframeTrailer:         movie.IframeTrailer,
+		Lgbt:                  movie.Lgbt,
+		Uhd:                   movie.Uhd,
+		AvailableDirectorsCut: movie.AvailableDirectorsCut,
+	}
+
+	for _, season := range movie.Seasons {
+		if !data.LastSeason.Valid || int32(season.Season) > data.LastSeason.Int32 {
+			data.LastSeason = alloha.NullInt32{Int32: int32(season.Season), Valid: true}
+			data.LastEpisode = alloha.NullInt32{}
+			for _, episode := range season.Episodes {
+				if !data.LastEpisode.Valid || int32(episode.Episode) > data.LastEpisode.Int32 {
+					data.LastEpisode = alloha.NullInt32{Int32: int32(episode.Episode), Valid: true}
+				}
+			}
+		}
+	}
+
+	return data
+}
+
+// writeBody writes the response body encoded with the content coding
+func writeBody(w http.ResponseWriter, encoding string, body []byte) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+	var writer io.WriteCloser
+	switch encoding {
+	case "gzip":
+		writer = gzip.NewWriter(w)
+	case "deflate":
+		writer, _ = flate.NewWriter(w, flate.DefaultCompression)
+	}
+
+	if writer == nil {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(body)
+		return
+	}
+
+	w.Header().Set("Content-Encoding", encoding)
+	w.WriteHeader(http.StatusOK)
+	_, _ = writer.Write(body)
+	_ = writer.Close()
+}
+
+//endregion
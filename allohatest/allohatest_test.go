@@ -0,0 +1,211 @@
+package allohatest
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/electromystyle/alloha-sdk-go/alloha"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestServer creates a server seeded with the test fixtures
+func newTestServer(t *testing.T, options ...Option) (*Server, *alloha.APIClient) {
+	movies := []*alloha.MovieData{
+		{
+			Name:            "Бригада",
+			OriginalName:    "Brigada",
+			Year:            2002,
+			Category:        2,
+			IDKp:            77044,
+			AlternativeIDKp: alloha.NullInt32{Int32: 77045, Valid: true},
+			IDImdb:          "tt0330013",
+			IDTmdb:          alloha.NullInt32{Int32: 40096, Valid: true},
+			Seasons: map[string]alloha.SeasonIframe{
+				"1": {Season: 1, Episodes: map[string]alloha.EpisodeIframe{
+					"1":  {Episode: 1},
+					"15": {Episode: 15},
+				}},
+			},
+		},
+		{Name: "Бригада: Наследник", Year: 2022, Category: 1, IDKp: 4370148},
+		{Name: "Криминальное чтиво", OriginalName: "Pulp Fiction", Year: 1994, Category: 1, IDKp: 342, IDImdb: "tt0110912"},
+	}
+
+	var series []*alloha.SeriesData
+	for i := 1; i <= 5; i++ {
+		series = append(series, &alloha.SeriesData{
+			Name:    "Серия " + strconv.Itoa(i),
+			Season:  1,
+			Episode: i,
+			IDKp:    1000 + i,
+			Date:    "2024-01-0" + strconv.Itoa(i) + " 12:00:00",
+		})
+	}
+
+	server := NewServer(append([]Option{WithMovies(movies...), WithSeries(series...), WithPageSize(2)}, options...)...)
+	t.Cleanup(server.Close)
+
+	client, err := server.NewClient()
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	return server, client
+}
+
+func TestServer_Find(t *testing.T) {
+	_, client := newTestServer(t)
+	ctx := context.Background()
+
+	byKP, err := client.FindByKPId(ctx, 77044)
+	assert.NoError(t, err)
+	assert.Equal(t, "success", byKP.Status)
+	assert.Equal(t, "Бригада", byKP.Data.Name)
+
+	byAlternativeKP, err := client.FindByKPId(ctx, 77045)
+	assert.NoError(t, err)
+	assert.Equal(t, 77044, byAlternativeKP.Data.IDKp)
+
+	byIMDb, err := client.FindByIMDbId(ctx, "tt0110912")
+	assert.NoError(t, err)
+	assert.Equal(t, 342, byIMDb.Data.IDKp)
+
+	byTMDb, err := client.FindByTMDbId(ctx, 40096)
+	assert.NoError(t, err)
+	assert.Equal(t, 77044, byTMDb.Data.IDKp)
+	assert.Equal(t, alloha.NullInt32{Int32: 40096, Valid: true}, byTMDb.Data.IDTmdb)
+
+	notFound, err := client.FindByKPId(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "error", notFound.Status)
+	assert.Equal(t, ErrorInfoNotFound, notFound.ErrorInfo)
+}
+
+func TestServer_Search(t *testing.T) {
+	_, client := newTestServer(t)
+	ctx := context.Background()
+
+	one, err := client.SearchForOneByName(ctx, "бригада")
+	assert.NoError(t, err)
+	assert.Equal(t, 77044, one.Data.IDKp)
+
+	byOriginalName, err := client.SearchForOneByName(ctx, "pulp")
+	assert.NoError(t, err)
+	assert.Equal(t, 342, byOriginalName.Data.IDKp)
+
+	list, err := client.SearchListByName(ctx, "Бригада")
+	assert.NoError(t, err)
+	if assert.Len(t, list.Data, 2) {
+		assert.Equal(t, 2, list.Data[0].CategoryId)
+		assert.Equal(t, alloha.NullInt32{Int32: 1, Valid: true}, list.Data[0].LastSeason)
+		assert.Equal(t, alloha.NullInt32{Int32: 15, Valid: true}, list.Data[0].LastEpisode)
+		assert.Equal(t, 4370148, list.Data[1].IDKp)
+	}
+	assert.False(t, list.NextPage.Valid)
+	assert.False(t, list.PrevPage.Valid)
+}
+
+func TestServer_LatestSeries(t *testing.T) {
+	_, client := newTestServer(t)
+	ctx := context.Background()
+
+	first, err := client.GetListOfLatestSeries(ctx, 1)
+	assert.NoError(t, err)
+	if assert.Len(t, first.Data, 2) {
+		assert.Equal(t, 5, first.Data[0].Episode)
+		assert.Equal(t, 4, first.Data[1].Episode)
+	}
+	assert.Equal(t, alloha.NullInt32{Int32: 2, Valid: true}, first.NextPage)
+	assert.False(t, first.PrevPage.Valid)
+
+	last, err := client.GetListOfLatestSeries(ctx, 3)
+	assert.NoError(t, err)
+	if assert.Len(t, last.Data, 1) {
+		assert.Equal(t, 1, last.Data[0].Episode)
+	}
+	assert.False(t, last.NextPage.Valid)
+	assert.Equal(t, alloha.NullInt32{Int32: 2, Valid: true}, last.PrevPage)
+}
+
+func TestServer_InvalidToken(t *testing.T) {
+	server, _ := newTestServer(t, WithTokens("valid-token"))
+
+	client, err := alloha.NewAPIClient(server.Client(), "wrong-token", server.URL)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	movie, err := client.FindByKPId(context.Background(), 77044)
+
+	// Проверяем результат
+	assert.NoError(t, err)
+	assert.Equal(t, "error", movie.Status)
+	assert.Equal(t, ErrorInfoInvalidToken, movie.ErrorInfo)
+}
+
+func TestServer_Faults(t *testing.T) {
+	server, client := newTestServer(t)
+	ctx := context.Background()
+
+	server.FailNext(1, http.StatusServiceUnavailable)
+	_, err := client.FindByKPId(ctx, 77044)
+	assert.EqualError(t, err, "unexpected server response with a status code: 503")
+
+	server.ThrottleNext(1, time.Second)
+	_, err = client.FindByKPId(ctx, 77044)
+	assert.EqualError(t, err, "unexpected server response with a status code: 429")
+
+	server.MalformNext(1)
+	_, err = client.FindByKPId(ctx, 77044)
+	assert.Error(t, err)
+
+	// Сбои одноразовые, следующий запрос выполняется успешно
+	movie, err := client.FindByKPId(ctx, 77044)
+	assert.NoError(t, err)
+	assert.Equal(t, "success", movie.Status)
+	assert.Equal(t, 4, server.RequestCount())
+}
+
+func TestServer_Retry(t *testing.T) {
+	server, client := newTestServer(t)
+	client.Use(alloha.RetryMiddleware(3, time.Millisecond))
+
+	server.FailNext(2, http.StatusInternalServerError)
+	movie, err := client.FindByKPId(context.Background(), 77044)
+
+	// Проверяем результат
+	assert.NoError(t, err)
+	assert.Equal(t, "success", movie.Status)
+	assert.Equal(t, 3, server.RequestCount())
+}
+
+func TestServer_Latency(t *testing.T) {
+	server, client := newTestServer(t)
+	server.SetLatency(200 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := client.FindByKPId(ctx, 77044)
+
+	// Проверяем результат
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestServer_ContentEncoding(t *testing.T) {
+	for _, encoding := range []string{"gzip", "deflate"} {
+		t.Run(encoding, func(t *testing.T) {
+			server, client := newTestServer(t)
+			server.SetContentEncoding(encoding)
+
+			movie, err := client.FindByKPId(context.Background(), 77044)
+
+			// Проверяем результат
+			assert.NoError(t, err)
+			assert.Equal(t, "Бригада", movie.Data.Name)
+		})
+	}
+}
@@ -0,0 +1,333 @@
+// Package allohavcr records the Alloha API interactions to a cassette file and replays them, so that the integration
+// tests can run deterministically without network access. Both the Recorder and the Replayer implement the
+// alloha.HttpClient interface.
+package allohavcr
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/electromystyle/alloha-sdk-go/alloha"
+)
+
+// ScrubbedToken replaces the API token in the recorded interactions
+const ScrubbedToken = "REDACTED"
+
+// Cassette is the content of a cassette file
+type Cassette struct {
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// Interaction is a recorded request and response pair
+type Interaction struct {
+	// Name of the API operation, e.g. "FindByKPId"
+	Operation string           `json:"operation"`
+	Request   RecordedRequest  `json:"request"`
+	Response  RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is a recorded request with the token scrubbed
+type RecordedRequest struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+}
+
+// RecordedResponse is a recorded response with the body stored decoded
+type RecordedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header"`
+	Body       string      `json:"body"`
+}
+
+// UnmatchedRequestError represents an error when the cassette has no interaction matching the request
+type UnmatchedRequestError struct {
+	Operation string
+	Method    string
+	Query     string
+}
+
+// Error implements the error interface
+func (e *UnmatchedRequestError) Error() string {
+	return fmt.Sprintf("allohavcr: no recorded interaction matches the %s request of the operation %q with the query %q",
+		e.Method, e.Operation, e.Query)
+}
+
+//region - Recorder
+
+// Recorder is an HttpClient that executes the requests with the wrapped client and writes every interaction to the
+// cassette file
+type Recorder struct {
+	client alloha.HttpClient
+	path   string
+
+	mu       sync.Mutex
+	cassette Cassette
+}
+
+var _ alloha.HttpClient = (*Recorder)(nil)
+
+// NewRecorder creates a new Recorder instance that writes the cassette to the path, replacing the existing one
+func NewRecorder(client alloha.HttpClient, path string) *Recorder {
+	return &Recorder{client: client, path: path}
+}
+
+// Do implements the alloha.HttpClient interface
+func (r *Recorder) Do(req *http.Request) (*http.Response, error) {
+	resp, err := r.client.Do(req)
+	if err != nil || resp == nil {
+		return resp, err
+	}
+
+	body, err := readDecodedBody(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	token := req.URL.Query().Get("token")
+	header := resp.Header.Clone()
+	header.Del("Content-Encoding")
+	header.Del("Content-Length")
+	header.Del("Set-Cookie")
+
+	interaction := &Interaction{
+		Operation: alloha.OperationFromContext(req.Context()),
+		Request: RecordedRequest{
+			Method: req.Method,
+			URL:    scrubURL(req.URL),
+		},
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Header:     header,
+			Body:       scrub(string(body), token),
+		},
+	}
+
+	if err = r.record(interaction); err != nil {
+		return nil, err
+	}
+
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = int64(len(body))
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	return resp, nil
+}
+
+// record appends the interaction to the cassette and writes the cassette file
+func (r *Recorder) record(interaction *Interaction) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+
+	data, err := json.MarshalIndent(&r.cassette, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if dir := filepath.Dir(r.path); len(dir) > 0 {
+		if err = os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+
+	return os.WriteFile(r.path, data, 0o644)
+}
+
+//endregion
+
+//region - Replayer
+
+// Replayer is an HttpClient that serves the interactions of the cassette file without network access. A request
+// matches an interaction with the same operation, method and query without the token. The matching interactions
+// are served in the recorded order, the last one is repeated when they run out.
+type Replayer struct {
+	mu       sync.Mutex
+	cassette Cassette
+	served   []bool
+}
+
+var _ alloha.HttpClient = (*Replayer)(nil)
+
+// NewReplayer creates a new Replayer instance from the cassette file
+func NewReplayer(path string) (*Replayer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Replayer{}
+	if err = json.Unmarshal(data, &r.cassette); err != nil {
+		return nil, fmt.Errorf("allohavcr: invalid cassette %s: %w", path, err)
+	}
+	r.served = make([]bool, len(r.cassette.Interactions))
+
+	return r, nil
+}
+
+// Do implements the alloha.HttpClient interface. It fails with *UnmatchedRequestError when no interaction matches
+// the request.
+func (r *Replayer) Do(req *http.Request) (*http.Response, error) {
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+
+	operation := alloha.OperationFromContext(req.Context())
+	query := queryWithoutToken(req.URL)
+
+	interaction := r.match(operation, req.Method, query)
+	if interaction == nil {
+		return nil, &UnmatchedRequestError{Operation: operation, Method: req.Method, Query: query}
+	}
+
+	header := interaction.Response.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+		StatusCode:    interaction.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(interaction.Response.Body)),
+		ContentLength: int64(len(interaction.Response.Body)),
+		Request:       req,
+	}, nil
+}
+
+// Unused returns the interactions that have never been served, so that the tests can check that the cassette
+// matches the code under test
+func (r *Replayer) Unused() []*Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var unused []*Interaction
+	for i, interaction := range r.cassette.Interactions {
+		if !r.served[i] {
+			unused = append(unused, interaction)
+		}
+	}
+
+	return unused
+}
+
+// match returns the first matching interaction that has not been served yet or the last matching one
+func (r *Replayer) match(operation, method, query string) *Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	last := -1
+	for i, interaction := range r.cassette.Interactions {
+		if interaction.Operation != operation || interaction.Request.Method != method {
+			continue
+		}
+
+		recordedURL, err := url.Parse(interaction.Request.URL)
+		if err != nil || queryWithoutToken(recordedURL) != query {
+			continue
+		}
+
+		if !r.served[i] {
+			r.served[i] = true
+			return interaction
+		}
+		last = i
+	}
+
+	if last < 0 {
+		return nil
+	}
+
+	return r.cassette.Interactions[last]
+}
+
+//endregion
+
+//region - Public Methods
+
+// Open returns a Replayer of the cassette file if it exists, or a Recorder that creates it with the client otherwise
+func Open(client alloha.HttpClient, path string) (alloha.HttpClient, error) {
+	if _, err := os.Stat(path); err == nil {
+		return NewReplayer(path)
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	return NewRecorder(client, path), nil
+}
+
+//endregion
+
+//region - Private Methods
+
+// readDecodedBody reads the response body and decodes the gzip and deflate content codings
+func readDecodedBody(resp *http.Response) ([]byte, error) {
+	defer resp.Body.Close()
+
+	var reader io.Reader = resp.Body
+	if !resp.Uncompressed {
+		encodings := strings.Split(resp.Header.Get("Content-Encoding"), ",")
+		for i := len(encodings) - 1; i >= 0; i-- {
+			switch encoding := strings.ToLower(strings.TrimSpace(encodings[i])); encoding {
+			case "", "identity":
+			case "gzip", "x-gzip":
+				gzipReader, err := gzip.NewReader(reader)
+				if err != nil {
+					return nil, err
+				}
+				reader = gzipReader
+			case "deflate":
+				reader = flate.NewReader(reader)
+			default:
+				return nil, &alloha.UnsupportedContentEncodingError{Encoding: encoding}
+			}
+		}
+	}
+
+	return io.ReadAll(reader)
+}
+
+// scrubURL returns the URL with the API token replaced
+func scrubURL(u *url.URL) string {
+	scrubbed := *u
+	queryValues := u.Query()
+	if _, found := queryValues["token"]; found {
+		queryValues.Set("token", ScrubbedToken)
+		scrubbed.RawQuery = queryValues.Encode()
+	}
+
+	return scrubbed.String()
+}
+
+// scrub replaces the API token in the text
+func scrub(text, token string) string {
+	if len(token) <= 0 {
+		return text
+	}
+
+	return strings.ReplaceAll(text, token, ScrubbedToken)
+}
+
+// queryWithoutToken returns the encoded query of the URL without the API token
+func queryWithoutToken(u *url.URL) string {
+	queryValues := u.Query()
+	queryValues.Del("token")
+
+	return queryValues.Encode()
+}
+
+//endregion
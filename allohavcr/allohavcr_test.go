@@ -0,0 +1,125 @@
+package allohavcr
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/electromystyle/alloha-sdk-go/alloha"
+	"github.com/electromystyle/alloha-sdk-go/allohatest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecorderReplayer(t *testing.T) {
+	server := allohatest.NewServer(allohatest.WithMovies(&alloha.MovieData{Name: "Бригада", IDKp: 77044}))
+	defer server.Close()
+	server.SetContentEncoding("gzip")
+
+	path := filepath.Join(t.TempDir(), "cassettes", "find.json")
+
+	// Записываем взаимодействия с тестовым сервером
+	recordingClient, err := alloha.NewAPIClient(NewRecorder(server.Client(), path), allohatest.DefaultToken, server.URL)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	server.FailNext(1, http.StatusServiceUnavailable)
+	_, err = recordingClient.FindByKPId(context.Background(), 77044)
+	assert.Error(t, err)
+
+	recorded, err := recordingClient.FindByKPId(context.Background(), 77044)
+	assert.NoError(t, err)
+	assert.Equal(t, "Бригада", recorded.Data.Name)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read cassette: %v", err)
+	}
+	assert.NotContains(t, string(data), allohatest.DefaultToken)
+	assert.NotContains(t, string(data), "Content-Encoding")
+	assert.Contains(t, string(data), "Бригада")
+
+	// Воспроизводим взаимодействия без сервера
+	server.Close()
+
+	replayer, err := NewReplayer(path)
+	if err != nil {
+		t.Fatalf("failed to create replayer: %v", err)
+	}
+
+	client, err := alloha.NewAPIClient(replayer, "another-token", "https://api.alloha.tv")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	_, err = client.FindByKPId(context.Background(), 77044)
+	assert.EqualError(t, err, "unexpected server response with a status code: 503")
+
+	replayed, err := client.FindByKPId(context.Background(), 77044)
+	assert.NoError(t, err)
+	assert.Equal(t, recorded.Data.Name, replayed.Data.Name)
+	assert.Empty(t, replayer.Unused())
+
+	// Последнее взаимодействие повторяется
+	repeated, err := client.FindByKPId(context.Background(), 77044)
+	assert.NoError(t, err)
+	assert.Equal(t, 77044, repeated.Data.IDKp)
+}
+
+func TestReplayer_Unmatched(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	cassette := `{"interactions":[{"operation":"FindByKPId","request":{"method":"GET","url":"https://api.alloha.tv/?kp=77044&token=REDACTED"},"response":{"status_code":200,"header":{},"body":"{\"status\":\"success\",\"data\":{\"id_kp\":77044}}"}}]}`
+	if err := os.WriteFile(path, []byte(cassette), 0o644); err != nil {
+		t.Fatalf("failed to write cassette: %v", err)
+	}
+
+	replayer, err := NewReplayer(path)
+	if err != nil {
+		t.Fatalf("failed to create replayer: %v", err)
+	}
+
+	client, err := alloha.NewAPIClient(replayer, "test-api-key", "https://api.alloha.tv")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	_, errTMDb := client.FindByTMDbId(context.Background(), 77044)
+	_, errKP := client.FindByKPId(context.Background(), 1)
+
+	// Проверяем результат
+	var unmatchedErr *UnmatchedRequestError
+	if assert.ErrorAs(t, errTMDb, &unmatchedErr) {
+		assert.Equal(t, alloha.OperationFindByTMDbId, unmatchedErr.Operation)
+		assert.Equal(t, "tmdb=77044", unmatchedErr.Query)
+	}
+	assert.ErrorAs(t, errKP, &unmatchedErr)
+	assert.Len(t, replayer.Unused(), 1)
+}
+
+func TestOpen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	client, err := Open(http.DefaultClient, path)
+	assert.NoError(t, err)
+	assert.IsType(t, &Recorder{}, client)
+
+	assert.NoError(t, os.WriteFile(path, []byte("{\"interactions\":[]}"), 0o644))
+
+	client, err = Open(http.DefaultClient, path)
+	assert.NoError(t, err)
+	assert.IsType(t, &Replayer{}, client)
+}
+
+func TestNewReplayer_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	assert.NoError(t, os.WriteFile(path, []byte("{"), 0o644))
+
+	_, err := NewReplayer(path)
+
+	// Проверяем результат
+	assert.Error(t, err)
+	assert.True(t, strings.HasPrefix(err.Error(), "allohavcr: invalid cassette"))
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/electromystyle/alloha-sdk-go/alloha"
+)
+
+// coalescingTimeout is the maximum duration of the shared upstream call
+const coalescingTimeout = 30 * time.Second
+
+//region - Coalescing Decorator
+
+// coalescingAPI is an API decorator that executes the identical concurrent calls once and shares the response between
+// the callers. The shared call does not depend on the contexts of the callers and ends after the timeout only, every
+// caller stops waiting for it when its own context is done. The stream calls are passed to the decorated API as is.
+// The shared responses must not be modified.
+type coalescingAPI struct {
+	api       alloha.API
+	timeout   time.Duration
+	mu        sync.Mutex
+	calls     map[string]*coalescedCall
+	coalesced int64
+}
+
+// coalescedCall is an in-flight call shared by the callers
+type coalescedCall struct {
+	done  chan struct{}
+	value interface{}
+	err   error
+}
+
+// detachedContext keeps the values of the parent context but is never canceled, the deadline is set on top of it
+type detachedContext struct {
+	context.Context
+}
+
+var _ alloha.API = (*coalescingAPI)(nil)
+
+// newCoalescingAPI creates a new coalescingAPI instance
+func newCoalescingAPI(api alloha.API) *coalescingAPI {
+	return &coalescingAPI{api: api, timeout: coalescingTimeout, calls: make(map[string]*coalescedCall)}
+}
+
+// Coalesced returns the number of the calls served by the calls of other callers
+func (a *coalescingAPI) Coalesced() int64 {
+	return atomic.LoadInt64(&a.coalesced)
+}
+
+// FindByIMDbId implements the API interface
+func (a *coalescingAPI) FindByIMDbId(ctx context.Context, imdbId string) (*alloha.FindOneResponse, error) {
+	value, err := a.do(ctx, coalescingKey(alloha.OperationFindByIMDbId, imdbId), func(ctx context.Context) (interface{}, error) {
+		return a.api.FindByIMDbId(ctx, imdbId)
+	})
+	response, _ := value.(*alloha.FindOneResponse)
+
+	return response, err
+}
+
+// FindByKPId implements the API interface
+func (a *coalescingAPI) FindByKPId(ctx context.Context, kpId int) (*alloha.FindOneResponse, error) {
+	value, err := a.do(ctx, coalescingKey(alloha.OperationFindByKPId, strconv.Itoa(kpId)), func(ctx context.Context) (interface{}, error) {
+		return a.api.FindByKPId(ctx, kpId)
+	})
+	response, _ := value.(*alloha.FindOneResponse)
+
+	return response, err
+}
+
+// FindByTMDbId implements the API interface
+func (a *coalescingAPI) FindByTMDbId(ctx context.Context, tmdbId int) (*alloha.FindOneResponse, error) {
+	value, err := a.do(ctx, coalescingKey(alloha.OperationFindByTMDbId, strconv.Itoa(tmdbId)), func(ctx context.Context) (interface{}, error) {
+		return a.api.FindByTMDbId(ctx, tmdbId)
+	})
+	response, _ := value.(*alloha.FindOneResponse)
+
+	return response, err
+}
+
+// GetListOfLatestSeries implements the API interface
+func (a *coalescingAPI) GetListOfLatestSeries(ctx context.Context, pageNum int) (*alloha.ListOfLatestSeriesResponse, error) {
+	value, err := a.do(ctx, coalescingKey(alloha.OperationGetListOfLatestSeries, strconv.Itoa(pageNum)), func(ctx context.Context) (interface{}, error) {
+		return a.api.GetListOfLatestSeries(ctx, pageNum)
+	})
+	response, _ := value.(*alloha.ListOfLatestSeriesResponse)
+
+	return response, err
+}
+
+// SearchForOneByName implements the API interface
+func (a *coalescingAPI) SearchForOneByName(ctx context.Context, movieName string) (*alloha.FindOneResponse, error) {
+	value, err := a.do(ctx, coalescingKey(alloha.OperationSearchForOneByName, movieName), func(ctx context.Context) (interface{}, error) {
+		return a.api.SearchForOneByName(ctx, movieName)
+	})
+	response, _ := value.(*alloha.FindOneResponse)
+
+	return response, err
+}
+
+// SearchListByName implements the API interface
+func (a *coalescingAPI) SearchListByName(ctx context.Context, movieName string) (*alloha.FindListResponse, error) {
+	value, err := a.do(ctx, coalescingKey(alloha.OperationSearchListByName, movieName), func(ctx context.Context) (interface{}, error) {
+		return a.api.SearchListByName(ctx, movieName)
+	})
+	response, _ := value.(*alloha.FindListResponse)
+
+	return response, err
+}
+
+// StreamListByName implements the API interface, the call is passed to the decorated API as is
+func (a *coalescingAPI) StreamListByName(ctx context.Context, movieName string, fn func(movie *alloha.MovieSearchData) error) (*alloha.ListPageInfo, error) {
+	return a.api.StreamListByName(ctx, movieName, fn)
+}
+
+// StreamListOfLatestSeries implements the API interface, the call is passed to the decorated API as is
+func (a *coalescingAPI) StreamListOfLatestSeries(ctx context.Context, pageNum int, fn func(series *alloha.SeriesData) error) (*alloha.ListPageInfo, error) {
+	return a.api.StreamListOfLatestSeries(ctx, pageNum, fn)
+}
+
+// do executes the call or waits for the identical in-flight call
+func (a *coalescingAPI) do(ctx context.Context, key string, fn func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	a.mu.Lock()
+	call, found := a.calls[key]
+	if !found {
+		call = &coalescedCall{done: make(chan struct{})}
+		a.calls[key] = call
+
+		callCtx, cancel := context.WithTimeout(detachedContext{ctx}, a.timeout)
+
+		go func() {
+			defer cancel()
+			call.value, call.err = fn(callCtx)
+
+			a.mu.Lock()
+			delete(a.calls, key)
+			a.mu.Unlock()
+			close(call.done)
+		}()
+	} else {
+		atomic.AddInt64(&a.coalesced, 1)
+	}
+	a.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.value, call.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// coalescingKey builds the key of the identical calls
+func coalescingKey(operation, param string) string {
+	return operation + ":" + param
+}
+
+// Deadline implements the context.Context interface, the detached context has no deadline
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+// Done implements the context.Context interface, the detached context is never canceled
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+// Err implements the context.Context interface, the detached context is never canceled
+func (detachedContext) Err() error {
+	return nil
+}
+
+//endregion
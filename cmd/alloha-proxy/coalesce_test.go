@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/electromystyle/alloha-sdk-go/alloha"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newReleasedServer starts a test server answering every request after the release channel is closed
+func newReleasedServer(t *testing.T, release chan struct{}, requests *int32) *alloha.APIClient {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		select {
+		case <-release:
+		case <-r.Context().Done():
+			return
+		}
+
+		// Возвращаем тестовые данные
+		_, _ = io.WriteString(w, "{\"status\":\"success\",\"data\":{\"name\":\"Бригада\",\"id_kp\":77044}}")
+	}))
+	t.Cleanup(ts.Close)
+
+	// Создаем клиент с тестовым сервером
+	client, err := alloha.NewAPIClient(ts.Client(), "test-api-key", ts.URL)
+	require.NoError(t, err)
+
+	return client
+}
+
+func TestCoalescingAPI(t *testing.T) {
+	release := make(chan struct{})
+	requests := int32(0)
+	api := newCoalescingAPI(newReleasedServer(t, release, &requests))
+
+	// Ожидающий вызывающий отменяет запрос, не прерывая общий вызов
+	canceledCtx, cancel := context.WithCancel(t.Context())
+	canceled := make(chan error, 1)
+	go func() {
+		_, errCanceled := api.FindByKPId(canceledCtx, 77044)
+		canceled <- errCanceled
+	}()
+
+	results := make(chan *alloha.FindOneResponse, 3)
+	for i := 0; i < 3; i++ {
+		go func() {
+			movie, errMovie := api.FindByKPId(t.Context(), 77044)
+			assert.NoError(t, errMovie)
+			results <- movie
+		}()
+	}
+
+	assert.Eventually(t, func() bool { return api.Coalesced() == 3 }, time.Second, time.Millisecond)
+	cancel()
+	assert.ErrorIs(t, <-canceled, context.Canceled)
+	close(release)
+
+	// Проверяем результат
+	first := <-results
+	assert.Equal(t, "Бригада", first.Data.Name)
+	assert.Same(t, first, <-results)
+	assert.Same(t, first, <-results)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+}
+
+func TestCoalescingAPI_Deadline(t *testing.T) {
+	release := make(chan struct{})
+	requests := int32(0)
+	api := newCoalescingAPI(newReleasedServer(t, release, &requests))
+
+	// Первый вызывающий с коротким сроком начинает общий вызов
+	ctx, cancel := context.WithTimeout(t.Context(), 20*time.Millisecond)
+	defer cancel()
+	first := make(chan error, 1)
+	go func() {
+		_, errFirst := api.FindByKPId(ctx, 77044)
+		first <- errFirst
+	}()
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&requests) == 1 }, time.Second, time.Millisecond)
+
+	// Второй вызывающий без срока присоединяется к общему вызову
+	second := make(chan *alloha.FindOneResponse, 1)
+	go func() {
+		movie, errMovie := api.FindByKPId(t.Context(), 77044)
+		assert.NoError(t, errMovie)
+		second <- movie
+	}()
+	assert.Eventually(t, func() bool { return api.Coalesced() == 1 }, time.Second, time.Millisecond)
+
+	// Проверяем, что срок первого вызывающего не прерывает общий вызов
+	assert.ErrorIs(t, <-first, context.DeadlineExceeded)
+	close(release)
+	assert.Equal(t, "Бригада", (<-second).Data.Name)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+}
+
+func TestCoalescingAPI_Timeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	requests := int32(0)
+	api := newCoalescingAPI(newReleasedServer(t, release, &requests))
+	api.timeout = 50 * time.Millisecond
+
+	_, err := api.FindByKPId(t.Context(), 77044)
+
+	// Проверяем, что общий вызов завершается по тайм-ауту
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Eventually(t, func() bool {
+		api.mu.Lock()
+		defer api.mu.Unlock()
+		return len(api.calls) == 0
+	}, time.Second, time.Millisecond)
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/electromystyle/alloha-sdk-go/alloha"
+	"gopkg.in/yaml.v3"
+)
+
+// Environment variables read by the command
+const (
+	envToken   = "ALLOHA_TOKEN"
+	envBaseURL = "ALLOHA_BASE_URL"
+	envConfig  = "ALLOHA_PROXY_CONFIG"
+)
+
+// Default settings of the proxy
+const (
+	defaultListen   = ":8080"
+	defaultTimeout  = 15 * time.Second
+	defaultCacheTTL = 10 * time.Minute
+)
+
+// config is the content of the config file
+type config struct {
+	// Listen address of the proxy
+	Listen string `yaml:"listen"`
+	// Upstream API token and base URL
+	Token   string `yaml:"token"`
+	BaseURL string `yaml:"base_url"`
+	// Upstream request timeout
+	Timeout time.Duration `yaml:"timeout"`
+	// Time to keep the successful upstream responses in the shared cache
+	CacheTTL time.Duration `yaml:"cache_ttl"`
+	// Maximum number of the responses in the shared cache
+	CacheSize int `yaml:"cache_size"`
+	// Clients allowed to use the proxy
+	Clients []clientConfig `yaml:"clients"`
+}
+
+// clientConfig is a client of the proxy
+type clientConfig struct {
+	Name string `yaml:"name"`
+	// Key passed by the client in the "token" query parameter instead of the upstream token
+	Key string `yaml:"key"`
+	// Rate limit of the client, zero disables the limit
+	RequestsPerSecond float64 `yaml:"requests_per_second"`
+	Burst             int     `yaml:"burst"`
+}
+
+// loadConfig reads the config file and applies the environment variables and the defaults
+func loadConfig(path string, getenv func(key string) string) (*config, error) {
+	if len(path) <= 0 {
+		return nil, errors.New("config file is not set, use -config or " + envConfig)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read config file: %w", err)
+	}
+
+	cfg := &config{}
+	if err = yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("couldn't parse config file %s: %w", path, err)
+	}
+
+	cfg.Token = firstNonEmpty(getenv(envToken), cfg.Token)
+	cfg.BaseURL = firstNonEmpty(getenv(envBaseURL), cfg.BaseURL)
+	cfg.Listen = firstNonEmpty(cfg.Listen, defaultListen)
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	if cfg.CacheTTL <= 0 {
+		cfg.CacheTTL = defaultCacheTTL
+	}
+	if cfg.CacheSize <= 0 {
+		cfg.CacheSize = alloha.DefaultMemoryCacheSize
+	}
+
+	return cfg, cfg.validate()
+}
+
+// validate checks the required settings
+func (c *config) validate() error {
+	if len(c.Token) <= 0 {
+		return errors.New("api token is not set, use " + envToken + " or the config file")
+	}
+	if len(c.BaseURL) <= 0 {
+		return errors.New("base url is not set, use " + envBaseURL + " or the config file")
+	}
+	if len(c.Clients) <= 0 {
+		return errors.New("no clients are configured")
+	}
+
+	keys := make(map[string]bool, len(c.Clients))
+	for i, client := range c.Clients {
+		if len(client.Name) <= 0 || len(client.Key) <= 0 {
+			return fmt.Errorf("client %d has no name or key", i+1)
+		}
+		if keys[client.Key] {
+			return fmt.Errorf("client %s has a duplicate key", client.Name)
+		}
+		keys[client.Key] = true
+	}
+
+	return nil
+}
+
+// firstNonEmpty returns the first non-empty value
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if len(value) > 0 {
+			return value
+		}
+	}
+
+	return ""
+}
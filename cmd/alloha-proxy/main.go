@@ -0,0 +1,80 @@
+// Command alloha-proxy is a caching reverse proxy in front of the Alloha API. It serves the same query interface
+// (?kp=, ?imdb=, ?tmdb=, ?name=, ?name=&list=1, ?last=&page=) to several applications, keeps the upstream token
+// server-side and hands out its own per-client keys, which the clients pass in the "token" parameter.
+//
+// Usage:
+//
+//	alloha-proxy -config proxy.yaml
+//
+// The responses are kept in a shared cache, the identical concurrent requests are sent upstream once and every
+// client has its own rate limit. The /healthz endpoint reports that the proxy is alive and the /stats endpoint
+// reports the counters of the proxy and the clients.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// shutdownTimeout is the time given to the in-flight requests on shutdown
+const shutdownTimeout = 10 * time.Second
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := run(ctx, os.Args[1:], os.Getenv, os.Stderr); err != nil {
+		fmt.Fprintf(os.Stderr, "alloha-proxy: %s\n", err.Error())
+		os.Exit(1)
+	}
+}
+
+// run starts the proxy and serves the requests until the context is done
+func run(ctx context.Context, args []string, getenv func(key string) string, stderr io.Writer) error {
+	fs := flag.NewFlagSet("alloha-proxy", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	configPath := fs.String("config", "", "config file (env "+envConfig+")")
+	listen := fs.String("listen", "", "listen address, overrides the config file")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	cfg, err := loadConfig(firstNonEmpty(*configPath, getenv(envConfig)), getenv)
+	if err != nil {
+		return err
+	}
+	cfg.Listen = firstNonEmpty(*listen, cfg.Listen)
+
+	p, err := newProxy(cfg, &http.Client{Timeout: cfg.Timeout})
+	if err != nil {
+		return err
+	}
+
+	server := &http.Server{Addr: cfg.Listen, Handler: p, ReadHeaderTimeout: 10 * time.Second}
+	errs := make(chan error, 1)
+	go func() {
+		errs <- server.ListenAndServe()
+	}()
+	fmt.Fprintf(stderr, "alloha-proxy: listening on %s\n", cfg.Listen)
+
+	select {
+	case err = <-errs:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		return server.Shutdown(shutdownCtx)
+	}
+}
@@ -0,0 +1,347 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/electromystyle/alloha-sdk-go/alloha"
+)
+
+// proxy serves the Alloha query interface to the clients through the shared cache
+type proxy struct {
+	api        alloha.API
+	coalescing *coalescingAPI
+	clients    map[string]*client
+	stats      *stats
+	started    time.Time
+	mux        *http.ServeMux
+}
+
+// client is a client of the proxy with its counters
+type client struct {
+	name      string
+	limiter   *alloha.RateLimiter
+	requests  int64
+	throttled int64
+}
+
+// stats are the counters of the proxy. It implements the alloha.MetricsRecorder interface to count the upstream
+// requests and the cache hits.
+type stats struct {
+	requests         int64
+	unauthorized     int64
+	throttled        int64
+	badRequests      int64
+	upstreamRequests int64
+	upstreamErrors   int64
+	cacheHits        int64
+	cacheMisses      int64
+}
+
+// statsOutput is the response of the stats endpoint
+type statsOutput struct {
+	UptimeSeconds    int64                  `json:"uptime_seconds"`
+	Requests         int64                  `json:"requests"`
+	Unauthorized     int64                  `json:"unauthorized"`
+	Throttled        int64                  `json:"throttled"`
+	BadRequests      int64                  `json:"bad_requests"`
+	UpstreamRequests int64                  `json:"upstream_requests"`
+	UpstreamErrors   int64                  `json:"upstream_errors"`
+	CacheHits        int64                  `json:"cache_hits"`
+	CacheMisses      int64                  `json:"cache_misses"`
+	Coalesced        int64                  `json:"coalesced"`
+	Clients          map[string]clientStats `json:"clients"`
+}
+
+// clientStats are the counters of a client in the stats endpoint response
+type clientStats struct {
+	Requests  int64 `json:"requests"`
+	Throttled int64 `json:"throttled"`
+}
+
+// errorOutput is the error response in the format of the Alloha API
+type errorOutput struct {
+	Status    string `json:"status"`
+	ErrorInfo string `json:"error_info"`
+}
+
+// rawResponse is an API response keeping its original JSON body
+type rawResponse interface {
+	Raw() json.RawMessage
+}
+
+var _ alloha.MetricsRecorder = (*stats)(nil)
+
+// newProxy creates the proxy from the config
+func newProxy(cfg *config, httpClient alloha.HttpClient) (*proxy, error) {
+	apiClient, err := alloha.NewAPIClient(httpClient, cfg.Token, cfg.BaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &proxy{
+		clients: make(map[string]*client, len(cfg.Clients)),
+		stats:   &stats{},
+		started: time.Now(),
+		mux:     http.NewServeMux(),
+	}
+	apiClient.SetMetricsRecorder(p.stats)
+	// The responses are relayed to the clients as received
+	apiClient.SetKeepRawResponse(true)
+
+	p.coalescing = newCoalescingAPI(apiClient)
+	cache := alloha.NewMemoryCache()
+	if cfg.CacheSize > 0 {
+		if err = cache.SetMaxEntries(cfg.CacheSize); err != nil {
+			return nil, fmt.Errorf("cache size: %w", err)
+		}
+	}
+	caching := alloha.NewCachingAPI(p.coalescing, cache, cfg.CacheTTL)
+	caching.SetMetricsRecorder(p.stats)
+	p.api = caching
+
+	for _, clientCfg := range cfg.Clients {
+		c := &client{name: clientCfg.Name}
+		if clientCfg.RequestsPerSecond > 0 {
+			burst := clientCfg.Burst
+			if burst <= 0 {
+				burst = int(math.Ceil(clientCfg.RequestsPerSecond))
+			}
+			if c.limiter, err = alloha.NewRateLimiter(clientCfg.RequestsPerSecond, burst); err != nil {
+				return nil, fmt.Errorf("client %s: %w", clientCfg.Name, err)
+			}
+		}
+		p.clients[clientCfg.Key] = c
+	}
+
+	p.mux.HandleFunc("/", p.serveAPI)
+	p.mux.HandleFunc("/healthz", p.serveHealth)
+	p.mux.HandleFunc("/stats", p.serveStats)
+
+	return p, nil
+}
+
+// ServeHTTP implements the http.Handler interface
+func (p *proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.mux.ServeHTTP(w, r)
+}
+
+// serveAPI serves the Alloha query interface: kp, imdb, tmdb, name with the optional list and last with page
+func (p *proxy) serveAPI(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		writeJSON(w, http.StatusMethodNotAllowed, &errorOutput{Status: "error", ErrorInfo: "method not allowed"})
+		return
+	}
+	atomic.AddInt64(&p.stats.requests, 1)
+
+	query := r.URL.Query()
+	c, found := p.clients[query.Get("token")]
+	if !found {
+		atomic.AddInt64(&p.stats.unauthorized, 1)
+		writeJSON(w, http.StatusUnauthorized, &errorOutput{Status: "error", ErrorInfo: "not valid token"})
+		return
+	}
+	atomic.AddInt64(&c.requests, 1)
+
+	if c.limiter != nil && !c.limiter.Allow() {
+		atomic.AddInt64(&p.stats.throttled, 1)
+		atomic.AddInt64(&c.throttled, 1)
+		w.Header().Set("Retry-After", "1")
+		writeJSON(w, http.StatusTooManyRequests, &errorOutput{Status: "error", ErrorInfo: "too many requests"})
+		return
+	}
+
+	response, err := p.call(r.Context(), query)
+	if err != nil {
+		p.writeError(w, err)
+		return
+	}
+
+	if raw, ok := response.(rawResponse); ok && len(raw.Raw()) > 0 {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(raw.Raw())
+		return
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// serveHealth reports that the proxy is alive
+func (p *proxy) serveHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"status":         "ok",
+		"uptime_seconds": int64(time.Since(p.started).Seconds()),
+	})
+}
+
+// serveStats reports the counters of the proxy and the clients
+func (p *proxy) serveStats(w http.ResponseWriter, r *http.Request) {
+	output := &statsOutput{
+		UptimeSeconds:    int64(time.Since(p.started).Seconds()),
+		Requests:         atomic.LoadInt64(&p.stats.requests),
+		Unauthorized:     atomic.LoadInt64(&p.stats.unauthorized),
+		Throttled:        atomic.LoadInt64(&p.stats.throttled),
+		BadRequests:      atomic.LoadInt64(&p.stats.badRequests),
+		UpstreamRequests: atomic.LoadInt64(&p.stats.upstreamRequests),
+		UpstreamErrors:   atomic.LoadInt64(&p.stats.upstreamErrors),
+		CacheHits:        atomic.LoadInt64(&p.stats.cacheHits),
+		CacheMisses:      atomic.LoadInt64(&p.stats.cacheMisses),
+		Coalesced:        p.coalescing.Coalesced(),
+		Clients:          make(map[string]clientStats, len(p.clients)),
+	}
+	for _, c := range p.clients {
+		output.Clients[c.name] = clientStats{
+			Requests:  atomic.LoadInt64(&c.requests),
+			Throttled: atomic.LoadInt64(&c.throttled),
+		}
+	}
+
+	writeJSON(w, http.StatusOK, output)
+}
+
+// call executes the API call selected by the query
+func (p *proxy) call(ctx context.Context, query url.Values) (interface{}, error) {
+	switch {
+	case len(query.Get("kp")) > 0:
+		id, err := strconv.Atoi(query.Get("kp"))
+		if err != nil || id <= 0 {
+			return nil, &badRequestError{message: "kp must be a positive number"}
+		}
+		return p.api.FindByKPId(ctx, id)
+	case len(query.Get("imdb")) > 0:
+		return p.api.FindByIMDbId(ctx, query.Get("imdb"))
+	case len(query.Get("tmdb")) > 0:
+		id, err := strconv.Atoi(query.Get("tmdb"))
+		if err != nil || id <= 0 {
+			return nil, &badRequestError{message: "tmdb must be a positive number"}
+		}
+		return p.api.FindByTMDbId(ctx, id)
+	}
+
+	list, err := boolParam(query, "list", "")
+	if err != nil {
+		return nil, err
+	}
+	last, err := boolParam(query, "last", "serial")
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case len(query.Get("name")) > 0 && list:
+		return p.api.SearchListByName(ctx, query.Get("name"))
+	case len(query.Get("name")) > 0:
+		return p.api.SearchForOneByName(ctx, query.Get("name"))
+	case last:
+		page := 1
+		if len(query.Get("page")) > 0 {
+			var err error
+			if page, err = strconv.Atoi(query.Get("page")); err != nil || page <= 0 {
+				return nil, &badRequestError{message: "page must be a positive number"}
+			}
+		}
+		return p.api.GetListOfLatestSeries(ctx, page)
+	default:
+		return nil, &badRequestError{message: "one of the kp, imdb, tmdb, name or last parameters is required"}
+	}
+}
+
+// boolParam parses the boolean query parameter, e.g. "list=1". The keyword of the API, e.g. "serial" of
+// "last=serial", means true too. The missing parameter means false.
+func boolParam(query url.Values, name, keyword string) (bool, error) {
+	value := query.Get(name)
+	if len(value) <= 0 {
+		return false, nil
+	}
+	if len(keyword) > 0 && value == keyword {
+		return true, nil
+	}
+
+	set, err := strconv.ParseBool(value)
+	if err != nil {
+		if len(keyword) > 0 {
+			return false, &badRequestError{message: fmt.Sprintf("%s must be %s or a boolean", name, keyword)}
+		}
+		return false, &badRequestError{message: name + " must be a boolean"}
+	}
+
+	return set, nil
+}
+
+// writeError writes the error response. The upstream errors are not passed to the client as is, because they can
+// contain the upstream token.
+func (p *proxy) writeError(w http.ResponseWriter, err error) {
+	var badRequestErr *badRequestError
+	var statusCodeErr *alloha.UnexpectedStatusCodeError
+
+	switch {
+	case errors.As(err, &badRequestErr):
+		atomic.AddInt64(&p.stats.badRequests, 1)
+		writeJSON(w, http.StatusBadRequest, &errorOutput{Status: "error", ErrorInfo: badRequestErr.message})
+	case errors.Is(err, context.Canceled):
+		// The client has gone away, there is nobody to respond to
+	case errors.As(err, &statusCodeErr):
+		atomic.AddInt64(&p.stats.upstreamErrors, 1)
+		writeJSON(w, http.StatusBadGateway, &errorOutput{
+			Status:    "error",
+			ErrorInfo: fmt.Sprintf("upstream responded with the status code %d", statusCodeErr.StatusCode),
+		})
+	case errors.Is(err, context.DeadlineExceeded):
+		atomic.AddInt64(&p.stats.upstreamErrors, 1)
+		writeJSON(w, http.StatusGatewayTimeout, &errorOutput{Status: "error", ErrorInfo: "upstream timeout"})
+	default:
+		atomic.AddInt64(&p.stats.upstreamErrors, 1)
+		writeJSON(w, http.StatusBadGateway, &errorOutput{Status: "error", ErrorInfo: "upstream request failed"})
+	}
+}
+
+// writeJSON writes the JSON response
+func writeJSON(w http.ResponseWriter, statusCode int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+// badRequestError represents an invalid query of the client
+type badRequestError struct {
+	message string
+}
+
+// Error implements the error interface
+func (e *badRequestError) Error() string {
+	return e.message
+}
+
+// ObserveRequest implements the alloha.MetricsRecorder interface
+func (s *stats) ObserveRequest(operation, outcome string, duration time.Duration, responseBytes int64) {
+	atomic.AddInt64(&s.upstreamRequests, 1)
+}
+
+// IncRetry implements the alloha.MetricsRecorder interface
+func (s *stats) IncRetry(operation string) {}
+
+// IncCacheHit implements the alloha.MetricsRecorder interface
+func (s *stats) IncCacheHit(operation string) {
+	atomic.AddInt64(&s.cacheHits, 1)
+}
+
+// IncCacheMiss implements the alloha.MetricsRecorder interface
+func (s *stats) IncCacheMiss(operation string) {
+	atomic.AddInt64(&s.cacheMisses, 1)
+}
+
+// ObserveRateLimitWait implements the alloha.MetricsRecorder interface
+func (s *stats) ObserveRateLimitWait(operation string, wait time.Duration) {}
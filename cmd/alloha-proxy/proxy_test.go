@@ -0,0 +1,217 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/electromystyle/alloha-sdk-go/alloha"
+	"github.com/electromystyle/alloha-sdk-go/allohatest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestProxy creates a proxy in front of a fake API server
+func newTestProxy(t *testing.T, clients ...clientConfig) (*proxy, *allohatest.Server) {
+	server := allohatest.NewServer(
+		allohatest.WithMovies(&alloha.MovieData{Name: "Бригада", IDKp: 77044, IDImdb: "tt0330013"}),
+		allohatest.WithSeries(&alloha.SeriesData{Name: "Бригада", IDKp: 77044, Season: 1, Episode: 2}),
+	)
+	t.Cleanup(server.Close)
+
+	if len(clients) <= 0 {
+		clients = []clientConfig{{Name: "web", Key: "web-key"}}
+	}
+
+	p, err := newProxy(&config{
+		Token:    allohatest.DefaultToken,
+		BaseURL:  server.URL,
+		CacheTTL: time.Minute,
+		Clients:  clients,
+	}, server.Client())
+	require.NoError(t, err)
+
+	return p, server
+}
+
+// get executes the request to the proxy
+func get(p *proxy, target string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+
+	return w
+}
+
+// proxyStats returns the stats of the proxy
+func proxyStats(t *testing.T, p *proxy) *statsOutput {
+	output := &statsOutput{}
+	require.NoError(t, json.Unmarshal(get(p, "/stats").Body.Bytes(), output))
+
+	return output
+}
+
+func TestProxy(t *testing.T) {
+	p, server := newTestProxy(t)
+
+	// Проверяем результат
+	first := get(p, "/?token=web-key&kp=77044")
+	assert.Equal(t, http.StatusOK, first.Code)
+	assert.Contains(t, first.Body.String(), "\"id_kp\":77044")
+
+	second := get(p, "/?token=web-key&kp=77044")
+	assert.Equal(t, first.Body.String(), second.Body.String())
+	assert.Equal(t, 1, server.RequestCount())
+
+	for _, target := range []string{
+		"/?token=web-key&imdb=tt0330013",
+		"/?token=web-key&name=Бригада",
+		"/?token=web-key&name=Бригада&list=1",
+		"/?token=web-key&last=serial&page=1",
+	} {
+		w := get(p, target)
+		assert.Equal(t, http.StatusOK, w.Code, target)
+		assert.Contains(t, w.Body.String(), "\"status\":\"success\"", target)
+	}
+
+	stats := proxyStats(t, p)
+	assert.Equal(t, int64(6), stats.Requests)
+	assert.Equal(t, int64(5), stats.UpstreamRequests)
+	assert.Equal(t, int64(1), stats.CacheHits)
+	assert.Equal(t, int64(6), stats.Clients["web"].Requests)
+}
+
+func TestProxy_Errors(t *testing.T) {
+	p, server := newTestProxy(t, clientConfig{Name: "web", Key: "web-key", RequestsPerSecond: 0.001, Burst: 2})
+
+	tests := []struct {
+		name   string
+		target string
+		code   int
+	}{
+		{name: "unknown key", target: "/?token=other&kp=77044", code: http.StatusUnauthorized},
+		{name: "bad query", target: "/?token=web-key&kp=abc", code: http.StatusBadRequest},
+		{name: "upstream failure", target: "/?token=web-key&kp=77044", code: http.StatusBadGateway},
+		{name: "rate limit", target: "/?token=web-key&kp=77044", code: http.StatusTooManyRequests},
+		{name: "unknown path", target: "/titles", code: http.StatusNotFound},
+	}
+
+	server.FailNext(1, http.StatusInternalServerError)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := get(p, tt.target)
+
+			// Проверяем результат
+			assert.Equal(t, tt.code, w.Code)
+			assert.NotContains(t, w.Body.String(), allohatest.DefaultToken)
+		})
+	}
+
+	stats := proxyStats(t, p)
+	assert.Equal(t, int64(1), stats.Unauthorized)
+	assert.Equal(t, int64(1), stats.BadRequests)
+	assert.Equal(t, int64(1), stats.UpstreamErrors)
+	assert.Equal(t, int64(1), stats.Throttled)
+	assert.Equal(t, clientStats{Requests: 3, Throttled: 1}, stats.Clients["web"])
+}
+
+func TestProxy_Coalescing(t *testing.T) {
+	p, server := newTestProxy(t)
+	server.SetLatency(50 * time.Millisecond)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.Equal(t, http.StatusOK, get(p, "/?token=web-key&kp=77044").Code)
+		}()
+	}
+	wg.Wait()
+
+	// Проверяем результат
+	assert.Equal(t, 1, server.RequestCount())
+}
+
+func TestProxy_Health(t *testing.T) {
+	p, _ := newTestProxy(t)
+
+	w := get(p, "/healthz")
+
+	// Проверяем результат
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "\"status\":\"ok\"")
+}
+
+func Test_boolParam(t *testing.T) {
+	tests := []struct {
+		query   string
+		want    bool
+		wantErr bool
+	}{
+		{query: "", want: false},
+		{query: "list=1", want: true},
+		{query: "list=true", want: true},
+		{query: "list=0", want: false},
+		{query: "list=false", want: false},
+		{query: "list=yes", wantErr: true},
+		{query: "last=serial", want: true},
+		{query: "last=movie", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			query, _ := url.ParseQuery(tt.query)
+			name, keyword := "list", ""
+			if strings.HasPrefix(tt.query, "last") {
+				name, keyword = "last", "serial"
+			}
+
+			got, err := boolParam(query, name, keyword)
+
+			// Проверяем результат
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "proxy.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(strings.Join([]string{
+		"token: file-token",
+		"base_url: https://alloha-api-domain.local",
+		"cache_ttl: 5m",
+		"cache_size: 500",
+		"clients:",
+		"  - name: web",
+		"    key: web-key",
+		"    requests_per_second: 2.5",
+	}, "\n")), 0o644))
+
+	cfg, err := loadConfig(path, func(key string) string {
+		if key == envToken {
+			return "env-token"
+		}
+		return ""
+	})
+
+	// Проверяем результат
+	require.NoError(t, err)
+	assert.Equal(t, "env-token", cfg.Token)
+	assert.Equal(t, defaultListen, cfg.Listen)
+	assert.Equal(t, 5*time.Minute, cfg.CacheTTL)
+	assert.Equal(t, 500, cfg.CacheSize)
+	assert.Equal(t, defaultTimeout, cfg.Timeout)
+	assert.Equal(t, []clientConfig{{Name: "web", Key: "web-key", RequestsPerSecond: 2.5}}, cfg.Clients)
+
+	require.NoError(t, os.WriteFile(path, []byte("token: t\nbase_url: u\nclients:\n  - {name: a, key: k}\n  - {name: b, key: k}\n"), 0o644))
+	_, err = loadConfig(path, func(string) string { return "" })
+	assert.ErrorContains(t, err, "duplicate key")
+}
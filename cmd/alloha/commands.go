@@ -0,0 +1,371 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/electromystyle/alloha-sdk-go/alloha"
+	"github.com/electromystyle/alloha-sdk-go/export"
+	"github.com/electromystyle/alloha-sdk-go/internal/strutil"
+)
+
+// idFlags are the flags selecting a title by one of its IDs
+type idFlags struct {
+	kp   int
+	imdb string
+	tmdb int
+}
+
+// episodeRow is a single episode of a series
+type episodeRow struct {
+	Season       int      `json:"season"`
+	Episode      int      `json:"episode"`
+	Translations []string `json:"translations"`
+	Iframe       string   `json:"iframe"`
+}
+
+// runFind executes the "find" command
+func runFind(ctx context.Context, env *environment, args []string) error {
+	fs, opts := newFlagSet("find", env)
+	ids := &idFlags{}
+	ids.register(fs)
+
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	client, err := opts.newClient(env)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, opts.timeout)
+	defer cancel()
+
+	movie, err := ids.find(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	return newPrinter(env.stdout, opts.format).movie(movie)
+}
+
+// runSearch executes the "search" command
+func runSearch(ctx context.Context, env *environment, args []string) error {
+	fs, opts := newFlagSet("search", env)
+	list := fs.Bool("list", false, "return all matching titles")
+
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) <= 0 {
+		return &usageError{message: "the title name is required, e.g. alloha search \"Бригада\""}
+	}
+	name := strings.Join(positional, " ")
+
+	client, err := opts.newClient(env)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, opts.timeout)
+	defer cancel()
+
+	if !*list {
+		response, err := client.SearchForOneByName(ctx, name)
+		if err != nil {
+			return err
+		}
+		movie, err := movieData(response)
+		if err != nil {
+			return err
+		}
+
+		return newPrinter(env.stdout, opts.format).movie(movie)
+	}
+
+	response, err := client.SearchListByName(ctx, name)
+	if err != nil {
+		return err
+	}
+	if err = response.Err(); err != nil {
+		return err
+	}
+
+	return newPrinter(env.stdout, opts.format).movieList(response.Data)
+}
+
+// runLatest executes the "latest" command
+func runLatest(ctx context.Context, env *environment, args []string) error {
+	fs, opts := newFlagSet("latest", env)
+	page := fs.Int("page", 1, "page number")
+
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *page <= 0 {
+		return &usageError{message: "the page number must be positive"}
+	}
+
+	client, err := opts.newClient(env)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, opts.timeout)
+	defer cancel()
+
+	response, err := client.GetListOfLatestSeries(ctx, *page)
+	if err != nil {
+		return err
+	}
+	if err = response.Err(); err != nil {
+		return err
+	}
+
+	return newPrinter(env.stdout, opts.format).latestSeries(response)
+}
+
+// runEpisodes executes the "episodes" command
+func runEpisodes(ctx context.Context, env *environment, args []string) error {
+	fs, opts := newFlagSet("episodes", env)
+	ids := &idFlags{}
+	ids.register(fs)
+	season := fs.Int("season", 0, "show only the episodes of the season")
+
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	client, err := opts.newClient(env)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, opts.timeout)
+	defer cancel()
+
+	movie, err := ids.find(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	return newPrinter(env.stdout, opts.format).episodes(episodeRows(movie, *season))
+}
+
+// runExport executes the "export" command
+func runExport(ctx context.Context, env *environment, args []string) error {
+	fs, opts := newFlagSet("export", env)
+	to := fs.String("to", string(export.FormatCSV), "export format: csv, jsonl or columnar")
+	out := fs.String("out", ".", "output directory")
+	kp := fs.String("kp", "", "comma-separated Kinopoisk IDs of the titles to export")
+	latest := fs.Int("latest", 0, "number of the latest series pages to export")
+	details := fs.Bool("details", false, "export the titles of the latest series too")
+	columns := fs.String("columns", "", "comma-separated columns of the titles table: "+strings.Join(export.MovieColumns(), ", "))
+	seriesColumns := fs.String("series-columns", "", "comma-separated columns of the series table: "+strings.Join(export.SeriesColumns(), ", "))
+	noChildren := fs.Bool("no-children", false, "do not export the seasons, episodes and translations tables")
+
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	ids, err := parseIDList(*kp)
+	if err != nil {
+		return err
+	}
+	if len(ids) <= 0 && *latest <= 0 {
+		return &usageError{message: "the --kp or --latest flag is required"}
+	}
+
+	exporter, err := export.NewDirExporter(*out, export.Format(*to),
+		export.WithMovieColumns(strutil.SplitList(*columns)...),
+		export.WithSeriesColumns(strutil.SplitList(*seriesColumns)...),
+		export.WithChildTables(!*noChildren),
+	)
+	if err != nil {
+		return &usageError{message: err.Error()}
+	}
+
+	client, err := opts.newClient(env)
+	if err != nil {
+		return err
+	}
+
+	titles, series, err := exportTitles(ctx, client, exporter, ids, *latest, *details)
+	if closeErr := exporter.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(env.stdout, "exported %d titles and %d series episodes to %s\n", titles, series, *out)
+
+	return nil
+}
+
+// exportTitles exports the titles by the IDs and the latest series pages, the titles are exported once
+func exportTitles(ctx context.Context, client *alloha.APIClient, exporter *export.Exporter, ids []int, latest int, details bool) (int, int, error) {
+	seen := make(map[int]bool)
+	titles, series := 0, 0
+
+	exportTitle := func(id int) error {
+		if seen[id] {
+			return nil
+		}
+		seen[id] = true
+
+		response, err := client.FindByKPId(ctx, id)
+		if err != nil {
+			return err
+		}
+		movie, err := movieData(response)
+		if err != nil {
+			return err
+		}
+		titles++
+
+		return exporter.WriteMovie(movie)
+	}
+
+	for _, id := range ids {
+		if err := exportTitle(id); err != nil {
+			return titles, series, err
+		}
+	}
+
+	var latestIDs []int
+	for page := 1; page <= latest; page++ {
+		info, err := client.StreamListOfLatestSeries(ctx, page, func(s *alloha.SeriesData) error {
+			series++
+			latestIDs = append(latestIDs, s.IDKp)
+			return exporter.WriteSeries(s)
+		})
+		if err == nil {
+			err = info.Err()
+		}
+		if err != nil {
+			return titles, series, err
+		}
+		if !info.NextPage.Valid {
+			break
+		}
+	}
+
+	if details {
+		for _, id := range latestIDs {
+			if err := exportTitle(id); err != nil && !alloha.IsNotFound(err) {
+				return titles, series, err
+			}
+		}
+	}
+
+	return titles, series, nil
+}
+
+// parseIDList parses the comma-separated IDs
+func parseIDList(value string) ([]int, error) {
+	var ids []int
+	for _, item := range strutil.SplitList(value) {
+		id, err := strconv.Atoi(item)
+		if err != nil || id <= 0 {
+			return nil, &usageError{message: fmt.Sprintf("invalid Kinopoisk ID %q", item)}
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// register registers the ID flags in the flag set
+func (f *idFlags) register(fs *flag.FlagSet) {
+	fs.IntVar(&f.kp, "kp", 0, "Kinopoisk ID")
+	fs.StringVar(&f.imdb, "imdb", "", "IMDb ID, e.g. tt0110912")
+	fs.IntVar(&f.tmdb, "tmdb", 0, "TMDb ID")
+}
+
+// find finds the title by the specified ID
+func (f *idFlags) find(ctx context.Context, client *alloha.APIClient) (*alloha.MovieData, error) {
+	var response *alloha.FindOneResponse
+	var err error
+
+	switch {
+	case f.kp > 0:
+		response, err = client.FindByKPId(ctx, f.kp)
+	case len(f.imdb) > 0:
+		response, err = client.FindByIMDbId(ctx, f.imdb)
+	case f.tmdb > 0:
+		response, err = client.FindByTMDbId(ctx, f.tmdb)
+	default:
+		return nil, &usageError{message: "one of the --kp, --imdb or --tmdb flags is required"}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return movieData(response)
+}
+
+// movieData returns the title of the response, the not found error is returned when the response has no title
+func movieData(response *alloha.FindOneResponse) (*alloha.MovieData, error) {
+	if err := response.Err(); err != nil {
+		return nil, err
+	}
+	if response.Data == nil {
+		return nil, &alloha.APIError{ErrorInfo: alloha.ErrorInfoNotFound}
+	}
+
+	return response.Data, nil
+}
+
+// episodeRows returns the episodes of the series ordered by season and episode
+func episodeRows(movie *alloha.MovieData, season int) []episodeRow {
+	rows := make([]episodeRow, 0)
+	if movie == nil {
+		return rows
+	}
+
+	for _, seasonIframe := range movie.Seasons {
+		if season > 0 && seasonIframe.Season != season {
+			continue
+		}
+
+		for _, episodeIframe := range seasonIframe.Episodes {
+			translations := make([]string, 0, len(episodeIframe.Translation))
+			for _, translation := range episodeIframe.Translation {
+				translations = append(translations, translation.Name)
+			}
+			sort.Strings(translations)
+
+			rows = append(rows, episodeRow{
+				Season:       seasonIframe.Season,
+				Episode:      episodeIframe.Episode,
+				Translations: translations,
+				Iframe:       episodeIframe.Iframe,
+			})
+		}
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Season != rows[j].Season {
+			return rows[i].Season < rows[j].Season
+		}
+		return rows[i].Episode < rows[j].Episode
+	})
+
+	return rows
+}
+
+// formatNullInt32 formats the nullable number for the table output
+func formatNullInt32(n alloha.NullInt32) string {
+	if !n.Valid {
+		return ""
+	}
+
+	return strconv.Itoa(int(n.Int32))
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/electromystyle/alloha-sdk-go/alloha"
+	"gopkg.in/yaml.v3"
+)
+
+// Environment variables read by the command
+const (
+	envToken   = "ALLOHA_TOKEN"
+	envBaseURL = "ALLOHA_BASE_URL"
+	envConfig  = "ALLOHA_CONFIG"
+)
+
+// config is the content of the config file
+type config struct {
+	Token   string `yaml:"token"`
+	BaseURL string `yaml:"base_url"`
+}
+
+// options are the flags shared by all commands
+type options struct {
+	token   string
+	baseURL string
+	config  string
+	format  string
+	timeout time.Duration
+}
+
+// newFlagSet creates the flag set of the command with the shared flags
+func newFlagSet(name string, env *environment) (*flag.FlagSet, *options) {
+	opts := &options{}
+
+	fs := flag.NewFlagSet("alloha "+name, flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	fs.StringVar(&opts.token, "token", "", "API token (env "+envToken+")")
+	fs.StringVar(&opts.baseURL, "base-url", "", "API base URL (env "+envBaseURL+")")
+	fs.StringVar(&opts.config, "config", "", "config file (env "+envConfig+", default <user config dir>/alloha/config.yaml)")
+	fs.StringVar(&opts.format, "format", formatTable, "output format: table, json or yaml")
+	fs.DurationVar(&opts.timeout, "timeout", 15*time.Second, "request timeout")
+
+	return fs, opts
+}
+
+// parseFlags parses the flags placed before and after the positional arguments and returns the positional ones
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+
+	for {
+		if err := fs.Parse(args); err != nil {
+			if err == flag.ErrHelp {
+				return nil, err
+			}
+			return nil, &usageError{message: err.Error()}
+		}
+
+		args = fs.Args()
+		if len(args) <= 0 {
+			return positional, nil
+		}
+
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// newClient creates the API client from the flags, the environment variables and the config file
+func (o *options) newClient(env *environment) (*alloha.APIClient, error) {
+	if !isValidFormat(o.format) {
+		return nil, &usageError{message: fmt.Sprintf("unknown output format %q", o.format)}
+	}
+
+	cfg, err := o.loadConfig(env)
+	if err != nil {
+		return nil, err
+	}
+
+	token := firstNonEmpty(o.token, env.getenv(envToken), cfg.Token)
+	if len(token) <= 0 {
+		return nil, &usageError{message: "api token is not set, use --token, " + envToken + " or the config file"}
+	}
+	baseURL := firstNonEmpty(o.baseURL, env.getenv(envBaseURL), cfg.BaseURL)
+	if len(baseURL) <= 0 {
+		return nil, &usageError{message: "base url is not set, use --base-url, " + envBaseURL + " or the config file"}
+	}
+
+	client, err := alloha.NewAPIClient(&http.Client{Timeout: o.timeout}, token, baseURL)
+	if err != nil {
+		return nil, &usageError{message: err.Error()}
+	}
+
+	return client, nil
+}
+
+// loadConfig reads the config file. A missing default config file is not an error.
+func (o *options) loadConfig(env *environment) (*config, error) {
+	cfg := &config{}
+
+	path := firstNonEmpty(o.config, env.getenv(envConfig))
+	explicit := len(path) > 0
+	if !explicit {
+		configDir, err := os.UserConfigDir()
+		if err != nil {
+			return cfg, nil
+		}
+		path = filepath.Join(configDir, "alloha", "config.yaml")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) && !explicit {
+			return cfg, nil
+		}
+		return nil, &usageError{message: fmt.Sprintf("couldn't read config file: %s", err.Error())}
+	}
+
+	if err = yaml.Unmarshal(data, cfg); err != nil {
+		return nil, &usageError{message: fmt.Sprintf("couldn't parse config file %s: %s", path, err.Error())}
+	}
+
+	return cfg, nil
+}
+
+// firstNonEmpty returns the first non-empty value
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if len(value) > 0 {
+			return value
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,144 @@
+// Command alloha looks up titles in the Alloha API from the command line.
+//
+// Usage:
+//
+//	alloha find --kp 77044
+//	alloha search "Бригада" --list --format json
+//	alloha latest --page 2
+//	alloha episodes --kp 77044 --season 1
+//	alloha export --latest 5 --details --to csv --out ./export
+//
+// The token and the base URL are taken from the --token and --base-url flags, the ALLOHA_TOKEN and ALLOHA_BASE_URL
+// environment variables or the config file, in that order.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"sort"
+
+	"github.com/electromystyle/alloha-sdk-go/alloha"
+)
+
+// Exit codes of the command
+const (
+	exitOK             = 0
+	exitError          = 1
+	exitUsage          = 2
+	exitNotFound       = 3
+	exitAPIError       = 4
+	exitTransportError = 5
+)
+
+// command is a subcommand of the tool
+type command struct {
+	description string
+	run         func(ctx context.Context, env *environment, args []string) error
+}
+
+// commands are the subcommands of the tool by name
+var commands = map[string]command{
+	"find":     {description: "finds a title by the --kp, --imdb or --tmdb ID", run: runFind},
+	"search":   {description: "searches a title by name, --list returns all matching titles", run: runSearch},
+	"latest":   {description: "lists the latest series episodes, --page selects the page", run: runLatest},
+	"episodes": {description: "lists the episodes of a series found by the --kp, --imdb or --tmdb ID", run: runEpisodes},
+	"export":   {description: "exports the --kp titles and the --latest series to CSV, JSONL or columnar files", run: runExport},
+}
+
+// environment holds the process dependencies of the command
+type environment struct {
+	stdout io.Writer
+	stderr io.Writer
+	getenv func(key string) string
+}
+
+// usageError represents an error in the command line arguments or the configuration
+type usageError struct {
+	message string
+}
+
+// Error implements the error interface
+func (e *usageError) Error() string {
+	return e.message
+}
+
+func main() {
+	os.Exit(run(context.Background(), os.Args[1:], &environment{stdout: os.Stdout, stderr: os.Stderr, getenv: os.Getenv}))
+}
+
+// run executes the command line and returns the exit code
+func run(ctx context.Context, args []string, env *environment) int {
+	if len(args) <= 0 {
+		printUsage(env.stderr)
+		return exitUsage
+	}
+	if args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		printUsage(env.stdout)
+		return exitOK
+	}
+
+	cmd, found := commands[args[0]]
+	if !found {
+		fmt.Fprintf(env.stderr, "alloha: unknown command %q\n\n", args[0])
+		printUsage(env.stderr)
+		return exitUsage
+	}
+
+	err := cmd.run(ctx, env, args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	}
+	if err != nil {
+		fmt.Fprintf(env.stderr, "alloha: %s\n", err.Error())
+		return exitCode(err)
+	}
+
+	return exitOK
+}
+
+// exitCode maps the error to the exit code
+func exitCode(err error) int {
+	var usageErr *usageError
+	var apiErr *alloha.APIError
+	var statusCodeErr *alloha.UnexpectedStatusCodeError
+	var urlErr *url.Error
+
+	switch {
+	case errors.As(err, &usageErr):
+		return exitUsage
+	case alloha.IsNotFound(err):
+		return exitNotFound
+	case errors.As(err, &apiErr) || errors.As(err, &statusCodeErr):
+		return exitAPIError
+	case errors.As(err, &urlErr) || errors.Is(err, alloha.EmptyHttpResponseError) ||
+		errors.Is(err, context.DeadlineExceeded):
+		return exitTransportError
+	default:
+		return exitError
+	}
+}
+
+// printUsage prints the help message
+func printUsage(w io.Writer) {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(w, "Usage: alloha <command> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-9s %s\n", name, commands[name].description)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run 'alloha <command> --help' for the flags of the command.")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Exit codes: 0 success, 1 error, 2 usage error, 3 not found, 4 API error, 5 transport error.")
+}
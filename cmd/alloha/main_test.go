@@ -0,0 +1,256 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/electromystyle/alloha-sdk-go/alloha"
+	"github.com/electromystyle/alloha-sdk-go/allohatest"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestServer creates a fake API server seeded with the test fixtures
+func newTestServer(t *testing.T) *allohatest.Server {
+	server := allohatest.NewServer(
+		allohatest.WithMovies(&alloha.MovieData{
+			Name:         "Бригада",
+			OriginalName: "Brigada",
+			Year:         2002,
+			IDKp:         77044,
+			IDImdb:       "tt0330013",
+			IDTmdb:       alloha.NullInt32{Int32: 40096, Valid: true},
+			Seasons: map[string]alloha.SeasonIframe{
+				"1": {Season: 1, Episodes: map[string]alloha.EpisodeIframe{
+					"2": {Episode: 2, Iframe: "https://example.com/1/2", Translation: map[string]alloha.TranslationIframe{
+						"66": {Name: "Оригинал"},
+					}},
+					"1": {Episode: 1, Iframe: "https://example.com/1/1"},
+				}},
+			},
+		}),
+		allohatest.WithSeries(&alloha.SeriesData{Name: "Бригада", IDKp: 77044, Season: 1, Episode: 2, Date: "2024-01-02"}),
+	)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// runCommand runs the command line with the environment of the test server
+func runCommand(server *allohatest.Server, env map[string]string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+
+	code := run(context.Background(), args, &environment{
+		stdout: &stdout,
+		stderr: &stderr,
+		getenv: func(key string) string {
+			if value, found := env[key]; found {
+				return value
+			}
+			switch key {
+			case envToken:
+				return allohatest.DefaultToken
+			case envBaseURL:
+				return server.URL
+			case envConfig:
+				return ""
+			}
+			return ""
+		},
+	})
+
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRun(t *testing.T) {
+	server := newTestServer(t)
+
+	tests := []struct {
+		name       string
+		args       []string
+		wantCode   int
+		wantStdout []string
+		wantStderr string
+	}{
+		{
+			name:       "find by kp",
+			args:       []string{"find", "--kp", "77044"},
+			wantCode:   exitOK,
+			wantStdout: []string{"Name:           Бригада\n", "TMDb ID:        40096\n"},
+		},
+		{
+			name:       "find by imdb as json",
+			args:       []string{"find", "--imdb", "tt0330013", "--format", "json"},
+			wantCode:   exitOK,
+			wantStdout: []string{"\"id_kp\": 77044", "\"id_tmdb\": 40096"},
+		},
+		{
+			name:       "find by tmdb as yaml",
+			args:       []string{"find", "--tmdb", "40096", "--format", "yaml"},
+			wantCode:   exitOK,
+			wantStdout: []string{"name: Бригада\noriginal_name: Brigada\n", "alternative_id_kp: null\n"},
+		},
+		{
+			name:       "not found",
+			args:       []string{"find", "--kp", "1"},
+			wantCode:   exitNotFound,
+			wantStderr: "alloha: api responded with an error: not movie\n",
+		},
+		{
+			name:       "search one with flags after name",
+			args:       []string{"search", "бригада", "--format", "json"},
+			wantCode:   exitOK,
+			wantStdout: []string{"\"name\": \"Бригада\""},
+		},
+		{
+			name:       "search list",
+			args:       []string{"search", "--list", "Бриг"},
+			wantCode:   exitOK,
+			wantStdout: []string{"KP ID  NAME     ORIGINAL NAME  YEAR  CATEGORY  QUALITY\n77044  Бригада  Brigada        2002  0         \n"},
+		},
+		{
+			name:       "latest",
+			args:       []string{"latest", "--page", "1", "--format", "json"},
+			wantCode:   exitOK,
+			wantStdout: []string{"\"episode\": 2", "\"next_page\": null"},
+		},
+		{
+			name:       "episodes",
+			args:       []string{"episodes", "--kp", "77044"},
+			wantCode:   exitOK,
+			wantStdout: []string{"SEASON  EPISODE  TRANSLATIONS  IFRAME\n1       1                      https://example.com/1/1\n1       2        Оригинал      https://example.com/1/2\n"},
+		},
+		{
+			name:       "missing id",
+			args:       []string{"find"},
+			wantCode:   exitUsage,
+			wantStderr: "alloha: one of the --kp, --imdb or --tmdb flags is required\n",
+		},
+		{
+			name:     "unknown format",
+			args:     []string{"find", "--kp", "77044", "--format", "xml"},
+			wantCode: exitUsage,
+		},
+		{
+			name:     "unknown command",
+			args:     []string{"watch"},
+			wantCode: exitUsage,
+		},
+		{
+			name:       "help",
+			args:       []string{"help"},
+			wantCode:   exitOK,
+			wantStdout: []string{"Usage: alloha <command> [flags]\n"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, stdout, stderr := runCommand(server, nil, tt.args...)
+
+			// Проверяем результат
+			assert.Equal(t, tt.wantCode, code, stderr)
+			for _, want := range tt.wantStdout {
+				assert.Contains(t, stdout, want)
+			}
+			if len(tt.wantStderr) > 0 {
+				assert.Equal(t, tt.wantStderr, stderr)
+			}
+		})
+	}
+}
+
+func TestRun_ExitCodes(t *testing.T) {
+	server := newTestServer(t)
+
+	server.FailNext(1, http.StatusInternalServerError)
+	code, _, _ := runCommand(server, nil, "find", "--kp", "77044")
+	assert.Equal(t, exitAPIError, code)
+
+	code, _, _ = runCommand(server, map[string]string{envToken: "wrong-token"}, "find", "--kp", "77044")
+	assert.Equal(t, exitAPIError, code)
+
+	code, _, _ = runCommand(server, map[string]string{envBaseURL: "http://127.0.0.1:1"}, "find", "--kp", "77044")
+	assert.Equal(t, exitTransportError, code)
+}
+
+func TestRun_EmptyData(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"success","data":null}`))
+	}))
+	defer server.Close()
+
+	env := map[string]string{envBaseURL: server.URL}
+
+	// Проверяем результат
+	code, _, stderr := runCommand(nil, env, "find", "--kp", "77044")
+	assert.Equal(t, exitNotFound, code, stderr)
+	code, _, stderr = runCommand(nil, env, "search", "Бригада")
+	assert.Equal(t, exitNotFound, code, stderr)
+}
+
+func TestRun_Config(t *testing.T) {
+	server := newTestServer(t)
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	config := "token: " + allohatest.DefaultToken + "\nbase_url: " + server.URL + "\n"
+	if err := os.WriteFile(path, []byte(config), 0o644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	env := map[string]string{envToken: "", envBaseURL: ""}
+
+	code, stdout, stderr := runCommand(server, env, "find", "--kp", "77044", "--config", path)
+	assert.Equal(t, exitOK, code, stderr)
+	assert.Contains(t, stdout, "Бригада")
+
+	// Флаг имеет приоритет над файлом конфигурации
+	code, _, _ = runCommand(server, env, "find", "--kp", "77044", "--config", path, "--token", "wrong-token")
+	assert.Equal(t, exitAPIError, code)
+
+	code, _, stderr = runCommand(server, env, "find", "--kp", "77044", "--config", filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, "couldn't read config file")
+}
+
+func TestRun_Export(t *testing.T) {
+	server := newTestServer(t)
+	dir := t.TempDir()
+
+	code, stdout, stderr := runCommand(server, nil, "export", "--latest", "1", "--details", "--kp", "77044",
+		"--columns", "id_kp,name,year", "--out", dir)
+
+	// Проверяем результат
+	assert.Equal(t, exitOK, code, stderr)
+	assert.Equal(t, "exported 1 titles and 1 series episodes to "+dir+"\n", stdout)
+
+	titles, err := os.ReadFile(filepath.Join(dir, "titles.csv"))
+	assert.NoError(t, err)
+	assert.Equal(t, "id_kp,name,year\n77044,Бригада,2002\n", string(titles))
+
+	episodes, err := os.ReadFile(filepath.Join(dir, "episodes.csv"))
+	assert.NoError(t, err)
+	assert.Equal(t, "id_kp,season,episode,translations_count,iframe\n"+
+		"77044,1,1,0,https://example.com/1/1\n"+
+		"77044,1,2,1,https://example.com/1/2\n", string(episodes))
+
+	for _, file := range []string{"series.csv", "seasons.csv", "translations.csv"} {
+		assert.FileExists(t, filepath.Join(dir, file))
+	}
+
+	// Проверяем ошибки использования
+	code, _, stderr = runCommand(server, nil, "export", "--out", dir)
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, "the --kp or --latest flag is required")
+
+	code, _, stderr = runCommand(server, nil, "export", "--kp", "77044", "--to", "xlsx", "--out", dir)
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, "unknown export format: xlsx")
+
+	code, _, _ = runCommand(server, nil, "export", "--kp", "1", "--to", "jsonl", "--out", dir)
+	assert.Equal(t, exitNotFound, code)
+}
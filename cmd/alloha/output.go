@@ -0,0 +1,212 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/electromystyle/alloha-sdk-go/alloha"
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats of the command
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatYAML  = "yaml"
+)
+
+// printer writes the command results in the selected format
+type printer struct {
+	w      io.Writer
+	format string
+}
+
+// latestSeriesOutput is the structured output of the "latest" command
+type latestSeriesOutput struct {
+	Data     []*alloha.SeriesData `json:"data"`
+	NextPage alloha.NullInt32     `json:"next_page"`
+	PrevPage alloha.NullInt32     `json:"prev_page"`
+}
+
+// newPrinter creates a new printer instance
+func newPrinter(w io.Writer, format string) *printer {
+	return &printer{w: w, format: format}
+}
+
+// isValidFormat reports whether the output format is supported
+func isValidFormat(format string) bool {
+	return format == formatTable || format == formatJSON || format == formatYAML
+}
+
+// movie prints a single title
+func (p *printer) movie(movie *alloha.MovieData) error {
+	if p.format != formatTable {
+		return p.structured(movie)
+	}
+
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	rows := [][2]string{
+		{"Name", movie.Name},
+		{"Original name", movie.OriginalName},
+		{"Year", strconv.Itoa(movie.Year)},
+		{"Category", strconv.Itoa(movie.Category)},
+		{"KP ID", strconv.Itoa(movie.IDKp)},
+		{"IMDb ID", movie.IDImdb},
+		{"TMDb ID", formatNullInt32(movie.IDTmdb)},
+		{"Country", movie.Country},
+		{"Genre", movie.Genre},
+		{"Quality", movie.Quality},
+		{"Translation", movie.Translation},
+		{"Rating KP", strconv.FormatFloat(movie.RatingKp, 'f', -1, 64)},
+		{"Rating IMDb", strconv.FormatFloat(movie.RatingImdb, 'f', -1, 64)},
+		{"Seasons", strconv.Itoa(movie.SeasonsCount)},
+		{"Iframe", movie.Iframe},
+	}
+	for _, row := range rows {
+		fmt.Fprintf(tw, "%s:\t%s\n", row[0], row[1])
+	}
+
+	return tw.Flush()
+}
+
+// movieList prints the list of titles
+func (p *printer) movieList(movies []*alloha.MovieSearchData) error {
+	if p.format != formatTable {
+		return p.structured(movies)
+	}
+
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "KP ID\tNAME\tORIGINAL NAME\tYEAR\tCATEGORY\tQUALITY")
+	for _, movie := range movies {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%d\t%d\t%s\n",
+			movie.IDKp, movie.Name, movie.OriginalName, movie.Year, movie.CategoryId, movie.Quality)
+	}
+
+	return tw.Flush()
+}
+
+// latestSeries prints the page of the latest series episodes
+func (p *printer) latestSeries(response *alloha.ListOfLatestSeriesResponse) error {
+	if p.format != formatTable {
+		return p.structured(&latestSeriesOutput{
+			Data:     response.Data,
+			NextPage: response.NextPage,
+			PrevPage: response.PrevPage,
+		})
+	}
+
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "KP ID\tNAME\tSEASON\tEPISODE\tTRANSLATION\tQUALITY\tDATE")
+	for _, series := range response.Data {
+		fmt.Fprintf(tw, "%d\t%s\t%d\t%d\t%d\t%s\t%s\n",
+			series.IDKp, series.Name, series.Season, series.Episode, series.Translation, series.Quality, series.Date)
+	}
+	if response.NextPage.Valid {
+		fmt.Fprintf(tw, "\nNext page: %d\n", response.NextPage.Int32)
+	}
+
+	return tw.Flush()
+}
+
+// episodes prints the episodes of a series
+func (p *printer) episodes(rows []episodeRow) error {
+	if p.format != formatTable {
+		return p.structured(rows)
+	}
+
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SEASON\tEPISODE\tTRANSLATIONS\tIFRAME")
+	for _, row := range rows {
+		fmt.Fprintf(tw, "%d\t%d\t%s\t%s\n", row.Season, row.Episode, strings.Join(row.Translations, ", "), row.Iframe)
+	}
+
+	return tw.Flush()
+}
+
+// structured prints the value as JSON or YAML. YAML is produced from the JSON representation, so that both formats
+// use the same field names and order.
+func (p *printer) structured(value interface{}) error {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(value); err != nil {
+		return err
+	}
+
+	if p.format == formatJSON {
+		_, err := p.w.Write(buf.Bytes())
+		return err
+	}
+
+	decoder := json.NewDecoder(&buf)
+	decoder.UseNumber()
+	node, err := yamlNode(decoder)
+	if err != nil {
+		return err
+	}
+
+	yamlEncoder := yaml.NewEncoder(p.w)
+	yamlEncoder.SetIndent(2)
+	if err = yamlEncoder.Encode(node); err != nil {
+		return err
+	}
+
+	return yamlEncoder.Close()
+}
+
+// yamlNode converts the next JSON value of the decoder to a YAML node keeping the order of the object keys
+func yamlNode(decoder *json.Decoder) (*yaml.Node, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch value := token.(type) {
+	case json.Delim:
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		if value == '{' {
+			node = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		}
+
+		for decoder.More() {
+			if node.Kind == yaml.MappingNode {
+				key, err := decoder.Token()
+				if err != nil {
+					return nil, err
+				}
+				node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key.(string)})
+			}
+
+			child, err := yamlNode(decoder)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, child)
+		}
+
+		// Read the closing delimiter
+		if _, err = decoder.Token(); err != nil {
+			return nil, err
+		}
+
+		return node, nil
+	case string:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}, nil
+	case json.Number:
+		tag := "!!int"
+		if strings.ContainsAny(value.String(), ".eE") {
+			tag = "!!float"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: value.String()}, nil
+	case bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(value)}, nil
+	default:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}, nil
+	}
+}
@@ -0,0 +1,12 @@
+module github.com/electromystyle/alloha-sdk-go/cmd
+
+go 1.16
+
+require (
+	github.com/electromystyle/alloha-sdk-go v0.0.0-00010101000000-000000000000
+	github.com/stretchr/testify v1.10.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+// The commands are developed together with the SDK in the same repository
+replace github.com/electromystyle/alloha-sdk-go => ../
@@ -0,0 +1,23 @@
+// Package brotli provides the brotli content decoder for the Alloha API client. It is shipped as a separate module,
+// so that the core SDK module stays dependency-free.
+package brotli
+
+import (
+	"io"
+
+	"github.com/andybalholm/brotli"
+	"github.com/electromystyle/alloha-sdk-go/alloha"
+)
+
+// Encoding is the name of the brotli content coding
+const Encoding = "br"
+
+// Decoder decodes the response body encoded with the "br" content coding
+func Decoder(r io.Reader) (io.ReadCloser, error) {
+	return io.NopCloser(brotli.NewReader(r)), nil
+}
+
+// Register registers the brotli content decoder in the API client
+func Register(client *alloha.APIClient) error {
+	return client.RegisterContentDecoder(Encoding, Decoder)
+}
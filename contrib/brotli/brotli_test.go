@@ -0,0 +1,48 @@
+package brotli
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/electromystyle/alloha-sdk-go/alloha"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegister(t *testing.T) {
+	var body bytes.Buffer
+	writer := brotli.NewWriter(&body)
+	_, _ = io.WriteString(writer, "{\"status\":\"success\",\"data\":{\"name\":\"Бригада\",\"id_kp\":77044}}")
+	_ = writer.Close()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Проверяем заголовки запроса
+		assert.Equal(t, "gzip, deflate, br", r.Header.Get("Accept-Encoding"))
+
+		// Возвращаем тестовые данные
+		w.Header().Set("Content-Encoding", "br")
+		w.WriteHeader(http.StatusOK)
+		_, errWrite := w.Write(body.Bytes())
+		if errWrite != nil {
+			t.Errorf("failed to write data to response: %v", errWrite)
+		}
+	}))
+	defer ts.Close()
+
+	// Создаем клиент с тестовым сервером
+	client, err := alloha.NewAPIClient(ts.Client(), "test-api-key", ts.URL)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	assert.NoError(t, Register(client))
+
+	movie, errMovie := client.FindByKPId(context.Background(), 77044)
+
+	// Проверяем результат
+	assert.NoError(t, errMovie)
+	assert.Equal(t, "Бригада", movie.Data.Name)
+}
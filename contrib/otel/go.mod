@@ -0,0 +1,14 @@
+module github.com/electromystyle/alloha-sdk-go/contrib/otel
+
+go 1.16
+
+require (
+	github.com/electromystyle/alloha-sdk-go v0.0.0-00010101000000-000000000000
+	github.com/stretchr/testify v1.10.0
+	go.opentelemetry.io/otel v1.7.0
+	go.opentelemetry.io/otel/sdk v1.7.0
+	go.opentelemetry.io/otel/trace v1.7.0
+)
+
+// The module is developed together with the SDK in the same repository
+replace github.com/electromystyle/alloha-sdk-go => ../../
@@ -0,0 +1,108 @@
+// Package otel provides the OpenTelemetry tracer for the Alloha API client. It is shipped as a separate module, so
+// that the core SDK module stays dependency-free.
+package otel
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/electromystyle/alloha-sdk-go/alloha"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentationName is the name of the OpenTelemetry tracer used by the SDK
+const InstrumentationName = "github.com/electromystyle/alloha-sdk-go"
+
+var _ alloha.Tracer = (*Tracer)(nil)
+
+// Tracer implements the alloha.Tracer interface with OpenTelemetry
+type Tracer struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+}
+
+// NewTracer creates a new Tracer instance. The global tracer provider and propagator are used when nil is passed.
+func NewTracer(provider trace.TracerProvider, propagator propagation.TextMapPropagator) *Tracer {
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+	if propagator == nil {
+		propagator = otel.GetTextMapPropagator()
+	}
+
+	return &Tracer{
+		tracer:     provider.Tracer(InstrumentationName),
+		propagator: propagator,
+	}
+}
+
+// Register sets the OpenTelemetry tracer with the global tracer provider and propagator in the API client
+func Register(client *alloha.APIClient) {
+	client.SetTracer(NewTracer(nil, nil))
+}
+
+// Start implements the alloha.Tracer interface
+func (t *Tracer) Start(ctx context.Context, operation string) (context.Context, alloha.Span) {
+	name := "alloha"
+	if len(operation) > 0 {
+		name += "." + operation
+	}
+
+	ctx, span := t.tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient))
+
+	return ctx, &otelSpan{span: span}
+}
+
+// Inject implements the alloha.Tracer interface
+func (t *Tracer) Inject(ctx context.Context, header http.Header) {
+	t.propagator.Inject(ctx, propagation.HeaderCarrier(header))
+}
+
+// otelSpan adapts trace.Span to the alloha.Span interface
+type otelSpan struct {
+	span trace.Span
+}
+
+// SetAttributes implements the alloha.Span interface
+func (s *otelSpan) SetAttributes(attributes ...alloha.Attribute) {
+	keyValues := make([]attribute.KeyValue, 0, len(attributes))
+	for _, attr := range attributes {
+		keyValues = append(keyValues, keyValue(attr))
+	}
+
+	s.span.SetAttributes(keyValues...)
+}
+
+// RecordError implements the alloha.Span interface
+func (s *otelSpan) RecordError(err error) {
+	s.span.RecordError(err)
+	s.span.SetStatus(codes.Error, err.Error())
+}
+
+// End implements the alloha.Span interface
+func (s *otelSpan) End() {
+	s.span.End()
+}
+
+// keyValue converts the SDK attribute to the OpenTelemetry one
+func keyValue(attr alloha.Attribute) attribute.KeyValue {
+	switch value := attr.Value.(type) {
+	case string:
+		return attribute.String(attr.Key, value)
+	case int:
+		return attribute.Int(attr.Key, value)
+	case int64:
+		return attribute.Int64(attr.Key, value)
+	case float64:
+		return attribute.Float64(attr.Key, value)
+	case bool:
+		return attribute.Bool(attr.Key, value)
+	default:
+		return attribute.String(attr.Key, fmt.Sprint(value))
+	}
+}
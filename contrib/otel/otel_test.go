@@ -0,0 +1,92 @@
+package otel
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/electromystyle/alloha-sdk-go/alloha"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracer(t *testing.T) {
+	var traceparent string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("Traceparent")
+
+		// Возвращаем тестовые данные
+		w.WriteHeader(http.StatusOK)
+		_, errWrite := io.WriteString(w, "{\"status\":\"success\",\"data\":{\"name\":\"Бригада\",\"id_kp\":77044}}")
+		if errWrite != nil {
+			t.Errorf("failed to write data to response: %v", errWrite)
+		}
+	}))
+	defer ts.Close()
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	// Создаем клиент с тестовым сервером
+	client, err := alloha.NewAPIClient(ts.Client(), "test-api-key", ts.URL)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	client.SetTracer(NewTracer(provider, propagation.TraceContext{}))
+
+	_, errMovie := client.FindByKPId(context.Background(), 77044)
+
+	// Проверяем результат
+	assert.NoError(t, errMovie)
+
+	spans := recorder.Ended()
+	if assert.Len(t, spans, 1) {
+		span := spans[0]
+		assert.Equal(t, "alloha.FindByKPId", span.Name())
+		assert.Equal(t, codes.Unset, span.Status().Code)
+		assert.Contains(t, span.Attributes(), attribute.String(alloha.AttributeOperation, alloha.OperationFindByKPId))
+		assert.Contains(t, span.Attributes(), attribute.String(alloha.AttributeIDKind, alloha.IDKindKP))
+		assert.Contains(t, span.Attributes(), attribute.Int(alloha.AttributeStatusCode, http.StatusOK))
+		assert.Contains(t, span.Attributes(), attribute.Int(alloha.AttributeRetries, 0))
+		assert.Contains(t, span.Attributes(), attribute.Bool(alloha.AttributeCacheHit, false))
+		assert.Equal(t, "00-"+span.SpanContext().TraceID().String()+"-"+span.SpanContext().SpanID().String()+"-01", traceparent)
+	}
+}
+
+func TestTracer_Error(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	// Создаем клиент с тестовым сервером
+	client, err := alloha.NewAPIClient(ts.Client(), "test-api-key", ts.URL)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	client.SetTracer(NewTracer(provider, propagation.TraceContext{}))
+
+	_, errList := client.GetListOfLatestSeries(context.Background(), 2)
+
+	// Проверяем результат
+	assert.Error(t, errList)
+
+	spans := recorder.Ended()
+	if assert.Len(t, spans, 1) {
+		span := spans[0]
+		assert.Equal(t, codes.Error, span.Status().Code)
+		assert.Equal(t, errList.Error(), span.Status().Description)
+		assert.Contains(t, span.Attributes(), attribute.Int(alloha.AttributePage, 2))
+		assert.Contains(t, span.Attributes(), attribute.Int(alloha.AttributeStatusCode, http.StatusInternalServerError))
+	}
+}
@@ -0,0 +1,12 @@
+module github.com/electromystyle/alloha-sdk-go/contrib/yamlpolicy
+
+go 1.16
+
+require (
+	github.com/electromystyle/alloha-sdk-go v0.0.0-00010101000000-000000000000
+	github.com/stretchr/testify v1.10.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+// The module is developed together with the SDK in the same repository
+replace github.com/electromystyle/alloha-sdk-go => ../../
@@ -0,0 +1,36 @@
+// Package yamlpolicy loads the content policy config in the YAML format. It is shipped as a separate module,
+// so that the core SDK module stays dependency-free.
+package yamlpolicy
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/electromystyle/alloha-sdk-go/policy"
+	"gopkg.in/yaml.v3"
+)
+
+// Load reads the config in the YAML or JSON format, the unknown fields are rejected
+func Load(r io.Reader) (*policy.Config, error) {
+	config := &policy.Config{}
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(config); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("couldn't parse the policy config: %w", err)
+	}
+
+	return config, config.Validate()
+}
+
+// LoadFile reads the config file in the YAML or JSON format
+func LoadFile(path string) (*policy.Config, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+
+	return Load(file)
+}
@@ -0,0 +1,53 @@
+package yamlpolicy
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/electromystyle/alloha-sdk-go/policy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	config, err := Load(strings.NewReader(`
+policies:
+  - name: kids
+    max_age: 12
+    deny_unknown_age: true
+    deny_mpaa: [r, nc17]
+  - name: ru
+    mode: translation
+    deny_lgbt: true
+    deny_adv: true
+    allow_countries: [Россия]
+`))
+	require.NoError(t, err)
+
+	// Проверяем результат
+	kids, found := config.Get("kids")
+	require.True(t, found)
+	assert.Equal(t, &policy.Policy{Name: "kids", MaxAge: 12, DenyUnknownAge: true, DenyMpaa: []string{"r", "nc17"}}, kids)
+	ru, found := config.Get("ru")
+	require.True(t, found)
+	assert.Equal(t, policy.ModeTranslation, ru.Mode)
+	assert.Equal(t, []string{"Россия"}, ru.AllowCountries)
+
+	// JSON является подмножеством YAML
+	path := filepath.Join(t.TempDir(), "policies.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"policies": [{"name": "us", "deny_countries": ["Россия"]}]}`), 0o600))
+	config, err = LoadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, []*policy.Policy{{Name: "us", DenyCountries: []string{"Россия"}}}, config.Policies)
+
+	_, err = Load(strings.NewReader("policies:\n  - name: kids\n    max_ages: 12\n"))
+	assert.ErrorContains(t, err, "field max_ages not found")
+	_, err = Load(strings.NewReader("policies:\n  - name: kids\n    mode: episode\n"))
+	assert.Equal(t, &policy.InvalidPolicyError{Name: "kids", Reason: `unknown mode "episode"`}, err)
+
+	config, err = Load(strings.NewReader(""))
+	require.NoError(t, err)
+	assert.Empty(t, config.Policies)
+}
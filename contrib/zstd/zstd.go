@@ -0,0 +1,28 @@
+// Package zstd provides the zstd content decoder for the Alloha API client. It is shipped as a separate module,
+// so that the core SDK module stays dependency-free.
+package zstd
+
+import (
+	"io"
+
+	"github.com/electromystyle/alloha-sdk-go/alloha"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Encoding is the name of the zstd content coding
+const Encoding = "zstd"
+
+// Decoder decodes the response body encoded with the "zstd" content coding
+func Decoder(r io.Reader) (io.ReadCloser, error) {
+	decoder, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+	if err != nil {
+		return nil, err
+	}
+
+	return decoder.IOReadCloser(), nil
+}
+
+// Register registers the zstd content decoder in the API client
+func Register(client *alloha.APIClient) error {
+	return client.RegisterContentDecoder(Encoding, Decoder)
+}
@@ -0,0 +1,58 @@
+package zstd
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/electromystyle/alloha-sdk-go/alloha"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegister(t *testing.T) {
+	// The body is encoded with gzip first and then with zstd
+	var gzipBody bytes.Buffer
+	gzipWriter := gzip.NewWriter(&gzipBody)
+	_, _ = io.WriteString(gzipWriter, "{\"status\":\"success\",\"data\":{\"name\":\"Бригада\",\"id_kp\":77044}}")
+	_ = gzipWriter.Close()
+
+	var body bytes.Buffer
+	zstdWriter, err := zstd.NewWriter(&body)
+	if err != nil {
+		t.Fatalf("failed to create zstd writer: %v", err)
+	}
+	_, _ = zstdWriter.Write(gzipBody.Bytes())
+	_ = zstdWriter.Close()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Проверяем заголовки запроса
+		assert.Equal(t, "gzip, deflate, zstd", r.Header.Get("Accept-Encoding"))
+
+		// Возвращаем тестовые данные
+		w.Header().Set("Content-Encoding", "gzip, zstd")
+		w.WriteHeader(http.StatusOK)
+		_, errWrite := w.Write(body.Bytes())
+		if errWrite != nil {
+			t.Errorf("failed to write data to response: %v", errWrite)
+		}
+	}))
+	defer ts.Close()
+
+	// Создаем клиент с тестовым сервером
+	client, err := alloha.NewAPIClient(ts.Client(), "test-api-key", ts.URL)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	assert.NoError(t, Register(client))
+
+	movie, errMovie := client.FindByKPId(context.Background(), 77044)
+
+	// Проверяем результат
+	assert.NoError(t, errMovie)
+	assert.Equal(t, "Бригада", movie.Data.Name)
+}
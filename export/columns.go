@@ -0,0 +1,161 @@
+package export
+
+import "github.com/electromystyle/alloha-sdk-go/alloha"
+
+// movieColumn is a flattened column of the titles table
+type movieColumn struct {
+	name  string
+	value func(m *alloha.MovieData) interface{}
+}
+
+// seriesColumn is a column of the latest series table
+type seriesColumn struct {
+	name  string
+	value func(s *alloha.SeriesData) interface{}
+}
+
+// movieColumns are the available columns of the titles table, the nested seasons and translations are exported to
+// the child tables
+var movieColumns = []movieColumn{
+	{"id_kp", func(m *alloha.MovieData) interface{} { return m.IDKp }},
+	{"name", func(m *alloha.MovieData) interface{} { return m.Name }},
+	{"original_name", func(m *alloha.MovieData) interface{} { return m.OriginalName }},
+	{"alternative_name", func(m *alloha.MovieData) interface{} { return m.AlternativeName }},
+	{"year", func(m *alloha.MovieData) interface{} { return m.Year }},
+	{"category", func(m *alloha.MovieData) interface{} { return m.Category }},
+	{"alternative_id_kp", func(m *alloha.MovieData) interface{} { return m.AlternativeIDKp }},
+	{"id_imdb", func(m *alloha.MovieData) interface{} { return m.IDImdb }},
+	{"id_tmdb", func(m *alloha.MovieData) interface{} { return m.IDTmdb }},
+	{"id_world_art", func(m *alloha.MovieData) interface{} { return m.IDWorldArt }},
+	{"token_movie", func(m *alloha.MovieData) interface{} { return m.TokenMovie }},
+	{"country", func(m *alloha.MovieData) interface{} { return m.Country }},
+	{"genre", func(m *alloha.MovieData) interface{} { return m.Genre }},
+	{"actors", func(m *alloha.MovieData) interface{} { return m.Actors }},
+	{"directors", func(m *alloha.MovieData) interface{} { return m.Directors }},
+	{"producers", func(m *alloha.MovieData) interface{} { return m.Producers }},
+	{"premiere_ru", func(m *alloha.MovieData) interface{} { return m.PremiereRu }},
+	{"premiere", func(m *alloha.MovieData) interface{} { return m.Premiere }},
+	{"age_restrictions", func(m *alloha.MovieData) interface{} { return m.AgeRestrictions }},
+	{"rating_mpaa", func(m *alloha.MovieData) interface{} { return m.RatingMpaa }},
+	{"rating_kp", func(m *alloha.MovieData) interface{} { return m.RatingKp }},
+	{"rating_imdb", func(m *alloha.MovieData) interface{} { return m.RatingImdb }},
+	{"time", func(m *alloha.MovieData) interface{} { return m.Time }},
+	{"tagline", func(m *alloha.MovieData) interface{} { return m.Tagline }},
+	{"poster", func(m *alloha.MovieData) interface{} { return m.Poster }},
+	{"description", func(m *alloha.MovieData) interface{} { return m.Description }},
+	{"seasons_count", func(m *alloha.MovieData) interface{} { return m.SeasonsCount }},
+	{"quality", func(m *alloha.MovieData) interface{} { return m.Quality }},
+	{"translation", func(m *alloha.MovieData) interface{} { return m.Translation }},
+	{"translations_count", func(m *alloha.MovieData) interface{} { return len(m.TranslationIframe) }},
+	{"iframe", func(m *alloha.MovieData) interface{} { return m.Iframe }},
+	{"iframe_trailer", func(m *alloha.MovieData) interface{} { return m.IframeTrailer }},
+	{"lgbt", func(m *alloha.MovieData) interface{} { return m.Lgbt }},
+	{"uhd", func(m *alloha.MovieData) interface{} { return m.Uhd }},
+	{"available_directors_cut", func(m *alloha.MovieData) interface{} { return m.AvailableDirectorsCut }},
+}
+
+// seriesColumns are the available columns of the latest series table
+var seriesColumns = []seriesColumn{
+	{"id_kp", func(s *alloha.SeriesData) interface{} { return s.IDKp }},
+	{"name", func(s *alloha.SeriesData) interface{} { return s.Name }},
+	{"original_name", func(s *alloha.SeriesData) interface{} { return s.OriginalName }},
+	{"alternative_name", func(s *alloha.SeriesData) interface{} { return s.AlternativeName }},
+	{"year", func(s *alloha.SeriesData) interface{} { return s.Year }},
+	{"category", func(s *alloha.SeriesData) interface{} { return s.Category }},
+	{"category_id", func(s *alloha.SeriesData) interface{} { return s.CategoryId }},
+	{"season", func(s *alloha.SeriesData) interface{} { return s.Season }},
+	{"episode", func(s *alloha.SeriesData) interface{} { return s.Episode }},
+	{"translation", func(s *alloha.SeriesData) interface{} { return s.Translation }},
+	{"quality", func(s *alloha.SeriesData) interface{} { return s.Quality }},
+	{"date", func(s *alloha.SeriesData) interface{} { return s.Date }},
+	{"id_item", func(s *alloha.SeriesData) interface{} { return s.IDItem }},
+	{"alternative_id_kp", func(s *alloha.SeriesData) interface{} { return s.AlternativeIDKp }},
+	{"id_imdb", func(s *alloha.SeriesData) interface{} { return s.IDImdb }},
+	{"id_tmdb", func(s *alloha.SeriesData) interface{} { return s.IDTmdb }},
+	{"id_world_art", func(s *alloha.SeriesData) interface{} { return s.IDWorldArt }},
+	{"token_movie", func(s *alloha.SeriesData) interface{} { return s.TokenMovie }},
+	{"iframe", func(s *alloha.SeriesData) interface{} { return s.Iframe }},
+	{"iframe_last", func(s *alloha.SeriesData) interface{} { return s.IframeLast }},
+	{"iframe_trailer", func(s *alloha.SeriesData) interface{} { return s.IframeTrailer }},
+	{"adv", func(s *alloha.SeriesData) interface{} { return s.Adv }},
+	{"adv_presence", func(s *alloha.SeriesData) interface{} { return s.AdvPresence }},
+	{"lgbt", func(s *alloha.SeriesData) interface{} { return s.Lgbt }},
+	{"uhd", func(s *alloha.SeriesData) interface{} { return s.Uhd }},
+}
+
+// Columns of the child tables
+var (
+	seasonColumns      = []string{"id_kp", "season", "episodes_count", "iframe"}
+	episodeColumns     = []string{"id_kp", "season", "episode", "translations_count", "iframe"}
+	translationColumns = []string{
+		"id_kp", "season", "episode", "translation_id", "name", "quality", "iframe", "adv", "date", "lgbt", "uhd",
+	}
+)
+
+// MovieColumns returns the names of the available columns of the titles table in the default order
+func MovieColumns() []string {
+	names := make([]string, 0, len(movieColumns))
+	for _, column := range movieColumns {
+		names = append(names, column.name)
+	}
+
+	return names
+}
+
+// SeriesColumns returns the names of the available columns of the latest series table in the default order
+func SeriesColumns() []string {
+	names := make([]string, 0, len(seriesColumns))
+	for _, column := range seriesColumns {
+		names = append(names, column.name)
+	}
+
+	return names
+}
+
+// selectMovieColumns returns the columns of the titles table by the names, all columns if there are no names
+func selectMovieColumns(names []string) ([]movieColumn, error) {
+	if len(names) <= 0 {
+		return movieColumns, nil
+	}
+
+	selected := make([]movieColumn, 0, len(names))
+	for _, name := range names {
+		found := false
+		for _, column := range movieColumns {
+			if column.name == name {
+				selected = append(selected, column)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, &UnknownColumnError{Table: TableTitles, Column: name}
+		}
+	}
+
+	return selected, nil
+}
+
+// selectSeriesColumns returns the columns of the latest series table by the names, all columns if there are no names
+func selectSeriesColumns(names []string) ([]seriesColumn, error) {
+	if len(names) <= 0 {
+		return seriesColumns, nil
+	}
+
+	selected := make([]seriesColumn, 0, len(names))
+	for _, name := range names {
+		found := false
+		for _, column := range seriesColumns {
+			if column.name == name {
+				selected = append(selected, column)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, &UnknownColumnError{Table: TableSeries, Column: name}
+		}
+	}
+
+	return selected, nil
+}
@@ -0,0 +1,335 @@
+// Package export writes the titles and the latest series to CSV, JSONL and a simple columnar format for the
+// spreadsheets and the notebooks. The scalar fields of the titles are flattened into the titles table, the nested
+// seasons, episodes and translations are exploded into the child tables linked by the KP ID.
+package export
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/electromystyle/alloha-sdk-go/alloha"
+	"github.com/electromystyle/alloha-sdk-go/internal/strutil"
+)
+
+// Format is an output format
+type Format string
+
+// Output formats
+const (
+	// FormatCSV writes the tables as CSV with the header
+	FormatCSV Format = "csv"
+	// FormatJSONL writes the rows as JSON objects, one per line
+	FormatJSONL Format = "jsonl"
+	// FormatColumnar writes the table as a JSON document with the values grouped by the columns
+	FormatColumnar Format = "columnar"
+)
+
+// Tables of the export
+const (
+	// TableTitles are the titles written by WriteMovie
+	TableTitles = "titles"
+	// TableSeries are the latest series episodes written by WriteSeries
+	TableSeries = "series"
+	// TableSeasons are the seasons of the titles
+	TableSeasons = "seasons"
+	// TableEpisodes are the episodes of the seasons
+	TableEpisodes = "episodes"
+	// TableTranslations are the translations of the titles, with the zero season and episode, and of the episodes
+	TableTranslations = "translations"
+)
+
+var (
+	NilMovieParameterError  = errors.New("movie param is nil")
+	NilSeriesParameterError = errors.New("series param is nil")
+)
+
+// UnknownFormatError represents an error when the output format is not supported
+type UnknownFormatError struct {
+	Format Format
+}
+
+// Error implements the error interface
+func (e *UnknownFormatError) Error() string {
+	return fmt.Sprintf("unknown export format: %s", e.Format)
+}
+
+// UnknownColumnError represents an error when the selected column does not exist
+type UnknownColumnError struct {
+	Table  string
+	Column string
+}
+
+// Error implements the error interface
+func (e *UnknownColumnError) Error() string {
+	return fmt.Sprintf("unknown column of the %s table: %s", e.Table, e.Column)
+}
+
+// OpenFunc opens the output of the table
+type OpenFunc func(table string) (io.WriteCloser, error)
+
+// Exporter writes the titles and the latest series to the tables. The output of a table is opened on its first row.
+// It is not safe for concurrent use.
+type Exporter struct {
+	format        Format
+	open          OpenFunc
+	movieNames    []string
+	seriesNames   []string
+	childTables   bool
+	movieColumns  []movieColumn
+	seriesColumns []seriesColumn
+	tables        map[string]tableWriter
+}
+
+// Option configures the Exporter
+type Option func(e *Exporter)
+
+//region - Constructor
+
+// NewExporter creates a new Exporter instance writing the tables to the outputs opened by the function
+func NewExporter(format Format, open OpenFunc, options ...Option) (*Exporter, error) {
+	if format != FormatCSV && format != FormatJSONL && format != FormatColumnar {
+		return nil, &UnknownFormatError{Format: format}
+	}
+
+	e := &Exporter{format: format, open: open, childTables: true, tables: make(map[string]tableWriter)}
+	for _, option := range options {
+		option(e)
+	}
+
+	movieColumns, err := selectMovieColumns(e.movieNames)
+	if err != nil {
+		return nil, err
+	}
+	seriesColumns, err := selectSeriesColumns(e.seriesNames)
+	if err != nil {
+		return nil, err
+	}
+
+	e.movieColumns, e.movieNames = movieColumns, make([]string, 0, len(movieColumns))
+	for _, column := range movieColumns {
+		e.movieNames = append(e.movieNames, column.name)
+	}
+	e.seriesColumns, e.seriesNames = seriesColumns, make([]string, 0, len(seriesColumns))
+	for _, column := range seriesColumns {
+		e.seriesNames = append(e.seriesNames, column.name)
+	}
+
+	return e, nil
+}
+
+// NewDirExporter creates a new Exporter instance writing the tables to the files in the directory, e.g.
+// "titles.csv", "episodes.jsonl" or "seasons.columnar.json"
+func NewDirExporter(dir string, format Format, options ...Option) (*Exporter, error) {
+	return NewExporter(format, func(table string) (io.WriteCloser, error) {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, err
+		}
+
+		return os.Create(filepath.Join(dir, FileName(table, format)))
+	}, options...)
+}
+
+// WithMovieColumns selects the columns of the titles table and their order, see MovieColumns
+func WithMovieColumns(names ...string) Option {
+	return func(e *Exporter) {
+		e.movieNames = names
+	}
+}
+
+// WithSeriesColumns selects the columns of the latest series table and their order, see SeriesColumns
+func WithSeriesColumns(names ...string) Option {
+	return func(e *Exporter) {
+		e.seriesNames = names
+	}
+}
+
+// WithChildTables enables or disables the seasons, episodes and translations tables, they are enabled by default
+func WithChildTables(enabled bool) Option {
+	return func(e *Exporter) {
+		e.childTables = enabled
+	}
+}
+
+//endregion
+
+//region - Public Methods
+
+// FileName returns the file name of the table in the format
+func FileName(table string, format Format) string {
+	if format == FormatColumnar {
+		return table + ".columnar.json"
+	}
+
+	return table + "." + string(format)
+}
+
+// WriteMovie writes the title and its seasons, episodes and translations
+func (e *Exporter) WriteMovie(movie *alloha.MovieData) error {
+	if movie == nil {
+		return NilMovieParameterError
+	}
+
+	values := make([]interface{}, 0, len(e.movieColumns))
+	for _, column := range e.movieColumns {
+		values = append(values, column.value(movie))
+	}
+	if err := e.write(TableTitles, e.movieNames, values); err != nil {
+		return err
+	}
+
+	if !e.childTables {
+		return nil
+	}
+
+	if err := e.writeTranslations(movie.IDKp, 0, 0, movie.TranslationIframe); err != nil {
+		return err
+	}
+
+	for _, seasonKey := range seasonKeys(movie.Seasons) {
+		season := movie.Seasons[seasonKey]
+		if err := e.write(TableSeasons, seasonColumns, []interface{}{
+			movie.IDKp, season.Season, len(season.Episodes), season.Iframe,
+		}); err != nil {
+			return err
+		}
+
+		for _, episodeKey := range episodeKeys(season.Episodes) {
+			episode := season.Episodes[episodeKey]
+			if err := e.write(TableEpisodes, episodeColumns, []interface{}{
+				movie.IDKp, season.Season, episode.Episode, len(episode.Translation), episode.Iframe,
+			}); err != nil {
+				return err
+			}
+			if err := e.writeTranslations(movie.IDKp, season.Season, episode.Episode, episode.Translation); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// WriteMovies writes the titles
+func (e *Exporter) WriteMovies(movies []*alloha.MovieData) error {
+	for _, movie := range movies {
+		if err := e.WriteMovie(movie); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WriteSeries writes the latest series episode. It can be passed to the StreamListOfLatestSeries method as
+// the callback to export the stream.
+func (e *Exporter) WriteSeries(series *alloha.SeriesData) error {
+	if series == nil {
+		return NilSeriesParameterError
+	}
+
+	values := make([]interface{}, 0, len(e.seriesColumns))
+	for _, column := range e.seriesColumns {
+		values = append(values, column.value(series))
+	}
+
+	return e.write(TableSeries, e.seriesNames, values)
+}
+
+// Close flushes and closes all tables, the columnar tables are written here
+func (e *Exporter) Close() error {
+	names := make([]string, 0, len(e.tables))
+	for name := range e.tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var firstErr error
+	for _, name := range names {
+		if err := e.tables[name].Close(); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("table %s: %w", name, err)
+		}
+	}
+	e.tables = make(map[string]tableWriter)
+
+	return firstErr
+}
+
+//endregion
+
+//region - Private Methods
+
+// write writes the row to the table, opening the table on its first row
+func (e *Exporter) write(table string, columns []string, values []interface{}) error {
+	writer, found := e.tables[table]
+	if !found {
+		out, err := e.open(table)
+		if err != nil {
+			return err
+		}
+		if writer, err = newTableWriter(e.format, table, columns, out); err != nil {
+			_ = out.Close()
+			return err
+		}
+		e.tables[table] = writer
+	}
+
+	return writer.WriteRow(values)
+}
+
+// writeTranslations writes the translations ordered by the ID
+func (e *Exporter) writeTranslations(idKp, season, episode int, translations map[string]alloha.TranslationIframe) error {
+	for _, key := range translationKeys(translations) {
+		t := translations[key]
+		id, _ := strconv.Atoi(key)
+		if err := e.write(TableTranslations, translationColumns, []interface{}{
+			idKp, season, episode, id, t.Name, t.Quality, t.Iframe, t.Adv, t.Date, t.Lgbt, t.Uhd,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// seasonKeys returns the keys of the seasons in the numeric order
+func seasonKeys(seasons map[string]alloha.SeasonIframe) []string {
+	keys := make([]string, 0, len(seasons))
+	for key := range seasons {
+		keys = append(keys, key)
+	}
+
+	strutil.SortNumeric(keys)
+
+	return keys
+}
+
+// episodeKeys returns the keys of the episodes in the numeric order
+func episodeKeys(episodes map[string]alloha.EpisodeIframe) []string {
+	keys := make([]string, 0, len(episodes))
+	for key := range episodes {
+		keys = append(keys, key)
+	}
+
+	strutil.SortNumeric(keys)
+
+	return keys
+}
+
+// translationKeys returns the keys of the translations in the numeric order
+func translationKeys(translations map[string]alloha.TranslationIframe) []string {
+	keys := make([]string, 0, len(translations))
+	for key := range translations {
+		keys = append(keys, key)
+	}
+
+	strutil.SortNumeric(keys)
+
+	return keys
+}
+
+//endregion
@@ -0,0 +1,159 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/electromystyle/alloha-sdk-go/alloha"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// buffer is an in-memory output of a table
+type buffer struct {
+	bytes.Buffer
+	closed bool
+}
+
+// Close implements the io.Closer interface
+func (b *buffer) Close() error {
+	b.closed = true
+	return nil
+}
+
+// newTestExporter creates an exporter writing the tables to the buffers
+func newTestExporter(t *testing.T, format Format, options ...Option) (*Exporter, map[string]*buffer) {
+	outputs := make(map[string]*buffer)
+	exporter, err := NewExporter(format, func(table string) (io.WriteCloser, error) {
+		outputs[table] = &buffer{}
+		return outputs[table], nil
+	}, options...)
+	require.NoError(t, err)
+
+	return exporter, outputs
+}
+
+// testMovie returns a series with the seasons and the translations
+func testMovie() *alloha.MovieData {
+	return &alloha.MovieData{
+		IDKp:     77044,
+		Name:     "Бригада",
+		Year:     2002,
+		IDTmdb:   alloha.NullInt32{Int32: 8943, Valid: true},
+		RatingKp: 8.3,
+		Genre:    "драма, криминал",
+		Seasons: map[string]alloha.SeasonIframe{
+			"1": {Season: 1, Iframe: "https://example.com/s1", Episodes: map[string]alloha.EpisodeIframe{
+				"10": {Episode: 10},
+				"2": {Episode: 2, Translation: map[string]alloha.TranslationIframe{
+					"66": {Name: "Оригинал", Quality: "WEB-DL"},
+				}},
+			}},
+		},
+		TranslationIframe: map[string]alloha.TranslationIframe{
+			"66": {Name: "Оригинал", Quality: "WEB-DL"},
+			"10": {Name: "LostFilm", Adv: true},
+		},
+	}
+}
+
+func TestExporter_CSV(t *testing.T) {
+	exporter, outputs := newTestExporter(t, FormatCSV, WithMovieColumns("id_kp", "name", "id_tmdb", "id_world_art", "rating_kp", "genre", "uhd"))
+
+	require.NoError(t, exporter.WriteMovies([]*alloha.MovieData{testMovie()}))
+	require.NoError(t, exporter.Close())
+
+	// Проверяем результат
+	assert.Equal(t, ""+
+		"id_kp,name,id_tmdb,id_world_art,rating_kp,genre,uhd\n"+
+		"77044,Бригада,8943,,8.3,\"драма, криминал\",false\n",
+		outputs[TableTitles].String())
+	assert.Equal(t, ""+
+		"id_kp,season,episodes_count,iframe\n"+
+		"77044,1,2,https://example.com/s1\n",
+		outputs[TableSeasons].String())
+	assert.Equal(t, ""+
+		"id_kp,season,episode,translations_count,iframe\n"+
+		"77044,1,2,1,\n"+
+		"77044,1,10,0,\n",
+		outputs[TableEpisodes].String())
+	assert.Equal(t, ""+
+		"id_kp,season,episode,translation_id,name,quality,iframe,adv,date,lgbt,uhd\n"+
+		"77044,0,0,10,LostFilm,,,true,,false,false\n"+
+		"77044,0,0,66,Оригинал,WEB-DL,,false,,false,false\n"+
+		"77044,1,2,66,Оригинал,WEB-DL,,false,,false,false\n",
+		outputs[TableTranslations].String())
+	assert.NotContains(t, outputs, TableSeries)
+	assert.True(t, outputs[TableTitles].closed)
+}
+
+func TestExporter_JSONL(t *testing.T) {
+	exporter, outputs := newTestExporter(t, FormatJSONL,
+		WithMovieColumns("id_kp", "id_tmdb", "id_world_art"),
+		WithSeriesColumns("id_kp", "season", "episode", "adv"),
+		WithChildTables(false),
+	)
+
+	require.NoError(t, exporter.WriteMovie(testMovie()))
+	require.NoError(t, exporter.WriteSeries(&alloha.SeriesData{IDKp: 77044, Season: 1, Episode: 10}))
+	require.NoError(t, exporter.WriteSeries(&alloha.SeriesData{IDKp: 326, Season: 2, Episode: 1, Adv: true}))
+	assert.Equal(t, NilMovieParameterError, exporter.WriteMovie(nil))
+	assert.Equal(t, NilSeriesParameterError, exporter.WriteSeries(nil))
+	require.NoError(t, exporter.Close())
+
+	// Проверяем результат
+	assert.Equal(t, `{"id_kp":77044,"id_tmdb":8943,"id_world_art":null}`+"\n", outputs[TableTitles].String())
+	assert.Equal(t, ""+
+		`{"id_kp":77044,"season":1,"episode":10,"adv":false}`+"\n"+
+		`{"id_kp":326,"season":2,"episode":1,"adv":true}`+"\n",
+		outputs[TableSeries].String())
+	assert.Len(t, outputs, 2)
+}
+
+func TestExporter_Columnar(t *testing.T) {
+	exporter, outputs := newTestExporter(t, FormatColumnar, WithMovieColumns("id_kp", "name", "id_world_art", "rating_kp", "lgbt"))
+
+	second := testMovie()
+	second.IDKp = 326
+	second.IDWorldArt = alloha.NullInt32{Int32: 1, Valid: true}
+	require.NoError(t, exporter.WriteMovies([]*alloha.MovieData{testMovie(), second}))
+	require.NoError(t, exporter.Close())
+
+	// Проверяем результат
+	assert.JSONEq(t, `{"table":"titles","rows":2,"columns":[
+		{"name":"id_kp","type":"int","values":[77044,326]},
+		{"name":"name","type":"string","values":["Бригада","Бригада"]},
+		{"name":"id_world_art","type":"int","values":[null,1]},
+		{"name":"rating_kp","type":"float","values":[8.3,8.3]},
+		{"name":"lgbt","type":"bool","values":[false,false]}
+	]}`, outputs[TableTitles].String())
+
+	table := &columnarTable{}
+	require.NoError(t, json.Unmarshal(outputs[TableEpisodes].Bytes(), table))
+	assert.Equal(t, 4, table.Rows)
+}
+
+func TestNewDirExporter(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "export")
+
+	exporter, err := NewDirExporter(dir, FormatCSV, WithChildTables(false))
+	require.NoError(t, err)
+	require.NoError(t, exporter.WriteMovie(testMovie()))
+	require.NoError(t, exporter.Close())
+
+	// Проверяем результат
+	data, err := os.ReadFile(filepath.Join(dir, "titles.csv"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "77044,Бригада,")
+	assert.Equal(t, "seasons.columnar.json", FileName(TableSeasons, FormatColumnar))
+
+	_, err = NewDirExporter(dir, "xlsx")
+	assert.EqualError(t, err, "unknown export format: xlsx")
+
+	_, err = NewDirExporter(dir, FormatCSV, WithMovieColumns("id_kp", "rating"))
+	assert.Equal(t, &UnknownColumnError{Table: TableTitles, Column: "rating"}, err)
+}
@@ -0,0 +1,204 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+
+	"github.com/electromystyle/alloha-sdk-go/alloha"
+)
+
+// tableWriter writes the rows of a table
+type tableWriter interface {
+	// WriteRow writes the row with the values of the columns
+	WriteRow(values []interface{}) error
+	// Close flushes the table and closes the output
+	Close() error
+}
+
+// csvWriter writes the table in the CSV format with the header
+type csvWriter struct {
+	out    io.WriteCloser
+	writer *csv.Writer
+}
+
+// jsonlWriter writes the table as JSON objects, one per line
+type jsonlWriter struct {
+	out     io.WriteCloser
+	columns []string
+}
+
+// columnarWriter collects the values of the table by the columns and writes them on close
+type columnarWriter struct {
+	out    io.WriteCloser
+	table  string
+	names  []string
+	values [][]interface{}
+	rows   int
+}
+
+// columnarTable is the columnar format of a table
+type columnarTable struct {
+	Table   string            `json:"table"`
+	Rows    int               `json:"rows"`
+	Columns []*columnarColumn `json:"columns"`
+}
+
+// columnarColumn is a column of the columnar format
+type columnarColumn struct {
+	Name string `json:"name"`
+	// Type of the values: "int", "float", "string" or "bool"
+	Type   string        `json:"type"`
+	Values []interface{} `json:"values"`
+}
+
+// newTableWriter creates the writer of the table in the format and writes the header
+func newTableWriter(format Format, table string, columns []string, out io.WriteCloser) (tableWriter, error) {
+	switch format {
+	case FormatCSV:
+		w := &csvWriter{out: out, writer: csv.NewWriter(out)}
+		if err := w.writer.Write(columns); err != nil {
+			return nil, err
+		}
+		return w, nil
+	case FormatJSONL:
+		return &jsonlWriter{out: out, columns: columns}, nil
+	case FormatColumnar:
+		return &columnarWriter{out: out, table: table, names: columns, values: make([][]interface{}, len(columns))}, nil
+	default:
+		return nil, &UnknownFormatError{Format: format}
+	}
+}
+
+// WriteRow implements the tableWriter interface
+func (w *csvWriter) WriteRow(values []interface{}) error {
+	record := make([]string, 0, len(values))
+	for _, value := range values {
+		record = append(record, formatCSVValue(value))
+	}
+
+	return w.writer.Write(record)
+}
+
+// Close implements the tableWriter interface
+func (w *csvWriter) Close() error {
+	w.writer.Flush()
+	if err := w.writer.Error(); err != nil {
+		_ = w.out.Close()
+		return err
+	}
+
+	return w.out.Close()
+}
+
+// WriteRow implements the tableWriter interface
+func (w *jsonlWriter) WriteRow(values []interface{}) error {
+	var buffer bytes.Buffer
+	buffer.WriteByte('{')
+	for i, value := range values {
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+
+		name, err := json.Marshal(w.columns[i])
+		if err != nil {
+			return err
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+
+		buffer.Write(name)
+		buffer.WriteByte(':')
+		buffer.Write(data)
+	}
+	buffer.WriteString("}\n")
+
+	_, err := w.out.Write(buffer.Bytes())
+	return err
+}
+
+// Close implements the tableWriter interface
+func (w *jsonlWriter) Close() error {
+	return w.out.Close()
+}
+
+// WriteRow implements the tableWriter interface
+func (w *columnarWriter) WriteRow(values []interface{}) error {
+	for i, value := range values {
+		if n, ok := value.(alloha.NullInt32); ok {
+			if n.Valid {
+				value = int(n.Int32)
+			} else {
+				value = nil
+			}
+		}
+		w.values[i] = append(w.values[i], value)
+	}
+	w.rows++
+
+	return nil
+}
+
+// Close implements the tableWriter interface
+func (w *columnarWriter) Close() error {
+	table := &columnarTable{Table: w.table, Rows: w.rows, Columns: make([]*columnarColumn, 0, len(w.names))}
+	for i, name := range w.names {
+		values := w.values[i]
+		if values == nil {
+			values = make([]interface{}, 0)
+		}
+		table.Columns = append(table.Columns, &columnarColumn{Name: name, Type: columnType(values), Values: values})
+	}
+
+	if err := json.NewEncoder(w.out).Encode(table); err != nil {
+		_ = w.out.Close()
+		return err
+	}
+
+	return w.out.Close()
+}
+
+// columnType returns the type of the first non-null value, "string" for the column of nulls
+func columnType(values []interface{}) string {
+	for _, value := range values {
+		switch value.(type) {
+		case nil:
+			continue
+		case int:
+			return "int"
+		case float64:
+			return "float"
+		case bool:
+			return "bool"
+		default:
+			return "string"
+		}
+	}
+
+	return "string"
+}
+
+// formatCSVValue formats the value for the CSV cell, the null numbers are empty
+func formatCSVValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case alloha.NullInt32:
+		if !v.Valid {
+			return ""
+		}
+		return strconv.Itoa(int(v.Int32))
+	default:
+		return ""
+	}
+}
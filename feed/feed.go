@@ -0,0 +1,383 @@
+// Package feed generates the RSS 2.0 and Atom feeds and the XML sitemaps of the new series episodes from the latest
+// series list. The URLs of the episode pages are built from the templates with the placeholders, e.g.
+// "https://example.com/titles/{id_kp}/{season}/{episode}", and the Handler serves the documents with caching.
+package feed
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/electromystyle/alloha-sdk-go/alloha"
+)
+
+// Defaults of the Generator
+const (
+	// SitemapLimit is the maximum number of the URLs in a sitemap, the larger sitemaps are split into the parts
+	SitemapLimit = 50000
+	// DefaultLanguage is the default language of the feeds
+	DefaultLanguage = "ru"
+)
+
+// Placeholders of the URL templates
+const (
+	PlaceholderIDKp        = "{id_kp}"
+	PlaceholderIDImdb      = "{id_imdb}"
+	PlaceholderIDTmdb      = "{id_tmdb}"
+	PlaceholderSeason      = "{season}"
+	PlaceholderEpisode     = "{episode}"
+	PlaceholderTranslation = "{translation}"
+	// PlaceholderPart is the number of the sitemap part in the template of the sitemap URLs
+	PlaceholderPart = "{part}"
+)
+
+// DefaultLocation is the time zone of the dates of the API, Moscow time
+var DefaultLocation = time.FixedZone("MSK", 3*60*60)
+
+// NoSuchSitemapPartError represents an error when the sitemap part is out of range
+var NoSuchSitemapPartError = errors.New("no such sitemap part")
+
+// Generator generates the feeds and the sitemaps of the latest series episodes
+type Generator struct {
+	title        string
+	link         string
+	description  string
+	language     string
+	itemURL      string
+	sitemapURL   string
+	sitemapLimit int
+	location     *time.Location
+	itemTitle    func(series *alloha.SeriesData) string
+}
+
+// Option configures the Generator
+type Option func(g *Generator)
+
+// item is a latest series episode with the URL and the publication date
+type item struct {
+	series    *alloha.SeriesData
+	url       string
+	published time.Time
+}
+
+//region - Constructor
+
+// NewGenerator creates a new Generator instance of the site with the link, e.g. "https://example.com"
+func NewGenerator(link string, options ...Option) *Generator {
+	g := &Generator{
+		title:        link,
+		link:         link,
+		language:     DefaultLanguage,
+		itemURL:      strings.TrimSuffix(link, "/") + "/titles/" + PlaceholderIDKp,
+		sitemapURL:   strings.TrimSuffix(link, "/") + "/sitemap-" + PlaceholderPart + ".xml",
+		sitemapLimit: SitemapLimit,
+		location:     DefaultLocation,
+		itemTitle: func(series *alloha.SeriesData) string {
+			return fmt.Sprintf("%s S%02dE%02d", series.Name, series.Season, series.Episode)
+		},
+	}
+	for _, option := range options {
+		option(g)
+	}
+
+	return g
+}
+
+// WithTitle sets the title of the feeds, the link by default
+func WithTitle(title string) Option {
+	return func(g *Generator) {
+		g.title = title
+	}
+}
+
+// WithDescription sets the description of the feeds
+func WithDescription(description string) Option {
+	return func(g *Generator) {
+		g.description = description
+	}
+}
+
+// WithLanguage sets the language of the feeds
+func WithLanguage(language string) Option {
+	return func(g *Generator) {
+		g.language = language
+	}
+}
+
+// WithItemURL sets the template of the episode page URLs, "<link>/titles/{id_kp}" by default. The template supports
+// the {id_kp}, {id_imdb}, {id_tmdb}, {season}, {episode} and {translation} placeholders.
+func WithItemURL(template string) Option {
+	return func(g *Generator) {
+		g.itemURL = template
+	}
+}
+
+// WithSitemapURL sets the template of the sitemap part URLs in the sitemap index, "<link>/sitemap-{part}.xml" by
+// default
+func WithSitemapURL(template string) Option {
+	return func(g *Generator) {
+		g.sitemapURL = template
+	}
+}
+
+// WithSitemapLimit sets the maximum number of the URLs in a sitemap, it can not exceed SitemapLimit
+func WithSitemapLimit(limit int) Option {
+	return func(g *Generator) {
+		if limit > 0 && limit <= SitemapLimit {
+			g.sitemapLimit = limit
+		}
+	}
+}
+
+// WithLocation sets the time zone of the dates of the API, DefaultLocation by default
+func WithLocation(location *time.Location) Option {
+	return func(g *Generator) {
+		g.location = location
+	}
+}
+
+// WithItemTitle sets the function building the title of the feed items, "<name> S01E02" by default
+func WithItemTitle(itemTitle func(series *alloha.SeriesData) string) Option {
+	return func(g *Generator) {
+		g.itemTitle = itemTitle
+	}
+}
+
+//endregion
+
+//region - Public Methods
+
+// ItemURL returns the URL of the episode page
+func (g *Generator) ItemURL(series *alloha.SeriesData) string {
+	tmdb := ""
+	if series.IDTmdb.Valid {
+		tmdb = strconv.Itoa(int(series.IDTmdb.Int32))
+	}
+
+	return strings.NewReplacer(
+		PlaceholderIDKp, strconv.Itoa(series.IDKp),
+		PlaceholderIDImdb, series.IDImdb,
+		PlaceholderIDTmdb, tmdb,
+		PlaceholderSeason, strconv.Itoa(series.Season),
+		PlaceholderEpisode, strconv.Itoa(series.Episode),
+		PlaceholderTranslation, strconv.Itoa(series.Translation),
+	).Replace(g.itemURL)
+}
+
+// PublishedAt returns the publication date of the episode parsed from the Date field, the zero time if it is invalid
+func (g *Generator) PublishedAt(series *alloha.SeriesData) time.Time {
+	value := strings.TrimSpace(series.Date)
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02"} {
+		if date, err := time.ParseInLocation(layout, value, g.location); err == nil {
+			return date
+		}
+	}
+
+	return time.Time{}
+}
+
+// RSS writes the RSS 2.0 feed of the episodes, the newest first
+func (g *Generator) RSS(w io.Writer, series []*alloha.SeriesData) error {
+	items := g.feedItems(series)
+
+	channel := rssChannel{Title: g.title, Link: g.link, Description: g.description, Language: g.language}
+	if updated := lastUpdated(items); !updated.IsZero() {
+		channel.LastBuildDate = updated.Format(time.RFC1123Z)
+	}
+	for _, i := range items {
+		rssItem := rssItem{
+			Title: g.itemTitle(i.series),
+			Link:  i.url,
+			GUID:  rssGUID{IsPermaLink: false, Value: itemID(i.series)},
+		}
+		if !i.published.IsZero() {
+			rssItem.PubDate = i.published.Format(time.RFC1123Z)
+		}
+		channel.Items = append(channel.Items, rssItem)
+	}
+
+	return writeXML(w, &rssFeed{Version: "2.0", Channel: channel})
+}
+
+// Atom writes the Atom feed of the episodes, the newest first. The entries without the date are updated at the time
+// of the newest entry.
+func (g *Generator) Atom(w io.Writer, series []*alloha.SeriesData) error {
+	items := g.feedItems(series)
+
+	updated := lastUpdated(items)
+	if updated.IsZero() {
+		updated = time.Now().In(g.location)
+	}
+
+	feed := &atomFeed{
+		Title:    g.title,
+		ID:       g.link,
+		Updated:  updated.Format(time.RFC3339),
+		Subtitle: g.description,
+		Links:    []atomLink{{Href: g.link, Rel: "alternate"}},
+		Author:   &atomAuthor{Name: g.title},
+	}
+	for _, i := range items {
+		entry := atomEntry{
+			Title:   g.itemTitle(i.series),
+			ID:      itemID(i.series),
+			Updated: feed.Updated,
+			Links:   []atomLink{{Href: i.url, Rel: "alternate"}},
+		}
+		if !i.published.IsZero() {
+			entry.Updated = i.published.Format(time.RFC3339)
+			entry.Published = entry.Updated
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+
+	return writeXML(w, feed)
+}
+
+// SitemapParts returns the number of the sitemap parts of the episodes, the episode URLs are deduplicated
+func (g *Generator) SitemapParts(series []*alloha.SeriesData) int {
+	return parts(len(g.sitemapItems(series)), g.sitemapLimit)
+}
+
+// Sitemap writes the sitemap part of the episodes, the parts are numbered from 1. It returns
+// NoSuchSitemapPartError if the part is out of range.
+func (g *Generator) Sitemap(w io.Writer, series []*alloha.SeriesData, part int) error {
+	items := g.sitemapItems(series)
+	if part < 1 || part > parts(len(items), g.sitemapLimit) {
+		return NoSuchSitemapPartError
+	}
+
+	start := (part - 1) * g.sitemapLimit
+	end := start + g.sitemapLimit
+	if end > len(items) {
+		end = len(items)
+	}
+
+	set := &urlSet{URLs: make([]sitemapURL, 0, end-start)}
+	for _, i := range items[start:end] {
+		set.URLs = append(set.URLs, sitemapURL{Loc: i.url, LastMod: formatLastMod(i.published)})
+	}
+
+	return writeXML(w, set)
+}
+
+// SitemapIndex writes the sitemap index of the sitemap parts of the episodes
+func (g *Generator) SitemapIndex(w io.Writer, series []*alloha.SeriesData) error {
+	items := g.sitemapItems(series)
+
+	index := &sitemapIndex{}
+	for part := 1; part <= parts(len(items), g.sitemapLimit); part++ {
+		start := (part - 1) * g.sitemapLimit
+		end := start + g.sitemapLimit
+		if end > len(items) {
+			end = len(items)
+		}
+
+		index.Sitemaps = append(index.Sitemaps, sitemapURL{
+			Loc:     strings.ReplaceAll(g.sitemapURL, PlaceholderPart, strconv.Itoa(part)),
+			LastMod: formatLastMod(lastUpdated(items[start:end])),
+		})
+	}
+
+	return writeXML(w, index)
+}
+
+//endregion
+
+//region - Private Methods
+
+// feedItems returns the items of the episodes ordered by the date, the newest first, the items without the date go
+// last
+func (g *Generator) feedItems(series []*alloha.SeriesData) []*item {
+	items := make([]*item, 0, len(series))
+	for _, s := range series {
+		items = append(items, &item{series: s, url: g.ItemURL(s), published: g.PublishedAt(s)})
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].published.After(items[j].published)
+	})
+
+	return items
+}
+
+// sitemapItems returns the items with the unique URLs ordered by the date, the newest first, and by the URL. The date
+// of an URL is the date of its newest episode.
+func (g *Generator) sitemapItems(series []*alloha.SeriesData) []*item {
+	byURL := make(map[string]*item, len(series))
+	for _, i := range g.feedItems(series) {
+		if _, found := byURL[i.url]; !found {
+			byURL[i.url] = i
+		}
+	}
+
+	items := make([]*item, 0, len(byURL))
+	for _, i := range byURL {
+		items = append(items, i)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if !items[i].published.Equal(items[j].published) {
+			return items[i].published.After(items[j].published)
+		}
+		return items[i].url < items[j].url
+	})
+
+	return items
+}
+
+// lastUpdated returns the newest date of the items
+func lastUpdated(items []*item) time.Time {
+	var last time.Time
+	for _, i := range items {
+		if i.published.After(last) {
+			last = i.published
+		}
+	}
+
+	return last
+}
+
+// itemID returns the unique ID of the episode translation
+func itemID(series *alloha.SeriesData) string {
+	return fmt.Sprintf("urn:alloha:series:%d:%d:%d:%d", series.IDKp, series.Season, series.Episode, series.Translation)
+}
+
+// parts returns the number of the parts of the items, an empty sitemap has one part
+func parts(count, limit int) int {
+	if count <= 0 {
+		return 1
+	}
+
+	return (count + limit - 1) / limit
+}
+
+// formatLastMod formats the date in the W3C datetime format, the zero time is an empty string
+func formatLastMod(date time.Time) string {
+	if date.IsZero() {
+		return ""
+	}
+
+	return date.Format(time.RFC3339)
+}
+
+// writeXML writes the XML declaration and the document
+func writeXML(w io.Writer, document interface{}) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(document); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+//endregion
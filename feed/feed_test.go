@@ -0,0 +1,181 @@
+package feed
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/electromystyle/alloha-sdk-go/alloha"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testSeries returns the latest series episodes, the second episode has two translations
+func testSeries() []*alloha.SeriesData {
+	return []*alloha.SeriesData{
+		{Name: "Бригада", IDKp: 77044, Season: 1, Episode: 1, Translation: 66, Date: "2024-01-01 10:00:00"},
+		{Name: "Бригада", IDKp: 77044, Season: 1, Episode: 2, Translation: 66, Date: "2024-01-03 10:00:00"},
+		{Name: "Пульс & <ко>", IDKp: 326, Season: 2, Episode: 5, Translation: 10, Date: "invalid"},
+		{Name: "Бригада", IDKp: 77044, Season: 1, Episode: 2, Translation: 10, Date: "2024-01-02"},
+	}
+}
+
+func TestGenerator_ItemURL(t *testing.T) {
+	generator := NewGenerator("https://example.com/", WithItemURL("https://example.com/{id_kp}/{id_imdb}/{id_tmdb}/s{season}e{episode}/{translation}"))
+
+	url := generator.ItemURL(&alloha.SeriesData{IDKp: 77044, IDImdb: "tt0330025", Season: 1, Episode: 2, Translation: 66})
+
+	// Проверяем результат
+	assert.Equal(t, "https://example.com/77044/tt0330025//s1e2/66", url)
+	assert.Equal(t, "https://example.com/titles/326", NewGenerator("https://example.com/").ItemURL(&alloha.SeriesData{IDKp: 326}))
+
+	published := generator.PublishedAt(&alloha.SeriesData{Date: "2024-07-16 09:36:48"})
+	assert.Equal(t, time.Date(2024, 7, 16, 6, 36, 48, 0, time.UTC), published.UTC())
+	assert.True(t, generator.PublishedAt(&alloha.SeriesData{Date: "16.07.2024"}).IsZero())
+}
+
+func TestGenerator_RSS(t *testing.T) {
+	generator := NewGenerator("https://example.com",
+		WithTitle("Новые серии"),
+		WithDescription("Новые серии сериалов"),
+		WithItemURL("https://example.com/titles/{id_kp}/{season}/{episode}"),
+	)
+
+	var buffer bytes.Buffer
+	require.NoError(t, generator.RSS(&buffer, testSeries()))
+
+	// Проверяем результат
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0">
+  <channel>
+    <title>Новые серии</title>
+    <link>https://example.com</link>
+    <description>Новые серии сериалов</description>
+    <language>ru</language>
+    <lastBuildDate>Wed, 03 Jan 2024 10:00:00 +0300</lastBuildDate>
+    <item>
+      <title>Бригада S01E02</title>
+      <link>https://example.com/titles/77044/1/2</link>
+      <guid isPermaLink="false">urn:alloha:series:77044:1:2:66</guid>
+      <pubDate>Wed, 03 Jan 2024 10:00:00 +0300</pubDate>
+    </item>
+    <item>
+      <title>Бригада S01E02</title>
+      <link>https://example.com/titles/77044/1/2</link>
+      <guid isPermaLink="false">urn:alloha:series:77044:1:2:10</guid>
+      <pubDate>Tue, 02 Jan 2024 00:00:00 +0300</pubDate>
+    </item>
+    <item>
+      <title>Бригада S01E01</title>
+      <link>https://example.com/titles/77044/1/1</link>
+      <guid isPermaLink="false">urn:alloha:series:77044:1:1:66</guid>
+      <pubDate>Mon, 01 Jan 2024 10:00:00 +0300</pubDate>
+    </item>
+    <item>
+      <title>Пульс &amp; &lt;ко&gt; S02E05</title>
+      <link>https://example.com/titles/326/2/5</link>
+      <guid isPermaLink="false">urn:alloha:series:326:2:5:10</guid>
+    </item>
+  </channel>
+</rss>
+`, buffer.String())
+}
+
+func TestGenerator_Atom(t *testing.T) {
+	generator := NewGenerator("https://example.com", WithTitle("Новые серии"), WithLocation(time.UTC))
+
+	var buffer bytes.Buffer
+	require.NoError(t, generator.Atom(&buffer, testSeries()[:3]))
+
+	// Проверяем результат
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>Новые серии</title>
+  <id>https://example.com</id>
+  <updated>2024-01-03T10:00:00Z</updated>
+  <link href="https://example.com" rel="alternate"></link>
+  <author>
+    <name>Новые серии</name>
+  </author>
+  <entry>
+    <title>Бригада S01E02</title>
+    <id>urn:alloha:series:77044:1:2:66</id>
+    <updated>2024-01-03T10:00:00Z</updated>
+    <published>2024-01-03T10:00:00Z</published>
+    <link href="https://example.com/titles/77044" rel="alternate"></link>
+  </entry>
+  <entry>
+    <title>Бригада S01E01</title>
+    <id>urn:alloha:series:77044:1:1:66</id>
+    <updated>2024-01-01T10:00:00Z</updated>
+    <published>2024-01-01T10:00:00Z</published>
+    <link href="https://example.com/titles/77044" rel="alternate"></link>
+  </entry>
+  <entry>
+    <title>Пульс &amp; &lt;ко&gt; S02E05</title>
+    <id>urn:alloha:series:326:2:5:10</id>
+    <updated>2024-01-03T10:00:00Z</updated>
+    <link href="https://example.com/titles/326" rel="alternate"></link>
+  </entry>
+</feed>
+`, buffer.String())
+}
+
+func TestGenerator_Sitemap(t *testing.T) {
+	generator := NewGenerator("https://example.com",
+		WithItemURL("https://example.com/titles/{id_kp}/{season}/{episode}"),
+		WithSitemapLimit(2),
+	)
+	series := testSeries()
+
+	// Проверяем результат
+	require.Equal(t, 2, generator.SitemapParts(series))
+
+	var buffer bytes.Buffer
+	require.NoError(t, generator.Sitemap(&buffer, series, 1))
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url>
+    <loc>https://example.com/titles/77044/1/2</loc>
+    <lastmod>2024-01-03T10:00:00+03:00</lastmod>
+  </url>
+  <url>
+    <loc>https://example.com/titles/77044/1/1</loc>
+    <lastmod>2024-01-01T10:00:00+03:00</lastmod>
+  </url>
+</urlset>
+`, buffer.String())
+
+	buffer.Reset()
+	require.NoError(t, generator.Sitemap(&buffer, series, 2))
+	assert.Contains(t, buffer.String(), "<url>\n    <loc>https://example.com/titles/326/2/5</loc>\n  </url>")
+
+	buffer.Reset()
+	require.NoError(t, generator.SitemapIndex(&buffer, series))
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <sitemap>
+    <loc>https://example.com/sitemap-1.xml</loc>
+    <lastmod>2024-01-03T10:00:00+03:00</lastmod>
+  </sitemap>
+  <sitemap>
+    <loc>https://example.com/sitemap-2.xml</loc>
+  </sitemap>
+</sitemapindex>
+`, buffer.String())
+
+	assert.ErrorIs(t, generator.Sitemap(&buffer, series, 3), NoSuchSitemapPartError)
+	assert.ErrorIs(t, generator.Sitemap(&buffer, series, 0), NoSuchSitemapPartError)
+}
+
+func TestGenerator_SitemapLimit(t *testing.T) {
+	series := make([]*alloha.SeriesData, 0, SitemapLimit+1)
+	for i := 0; i <= SitemapLimit; i++ {
+		series = append(series, &alloha.SeriesData{IDKp: i + 1})
+	}
+
+	// Проверяем результат
+	assert.Equal(t, 2, NewGenerator("https://example.com", WithSitemapLimit(SitemapLimit+1)).SitemapParts(series))
+	assert.Equal(t, 1, NewGenerator("https://example.com").SitemapParts(series[:SitemapLimit]))
+	assert.Equal(t, 1, NewGenerator("https://example.com").SitemapParts(nil))
+}
@@ -0,0 +1,356 @@
+package feed
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/electromystyle/alloha-sdk-go/alloha"
+)
+
+// Defaults of the Handler
+const (
+	// DefaultPages is the default number of the latest series pages loaded by the Handler
+	DefaultPages = 5
+	// DefaultCacheTTL is the default time to live of the loaded episodes and the rendered documents
+	DefaultCacheTTL = 15 * time.Minute
+	// DefaultLoadTimeout is the default timeout of loading the episodes from the API
+	DefaultLoadTimeout = 30 * time.Second
+)
+
+// retryInterval is the maximum interval between the failed load and the next one
+const retryInterval = time.Minute
+
+// Content types of the documents
+const (
+	contentTypeRSS  = "application/rss+xml; charset=utf-8"
+	contentTypeAtom = "application/atom+xml; charset=utf-8"
+	contentTypeXML  = "application/xml; charset=utf-8"
+)
+
+// Handler serves the feeds and the sitemaps of the latest series episodes:
+//
+//	GET /rss.xml
+//	GET /atom.xml
+//	GET /sitemap.xml
+//	GET /sitemap-{part}.xml
+//
+// The sitemap.xml is the sitemap itself if there is a single part and the sitemap index otherwise. The episodes are
+// loaded from the API once per the cache TTL and the rendered documents are cached until the next load. The concurrent
+// requests share one load, which is not canceled when the requests give up. The stale documents are served if the API
+// fails, and the failed load is retried after a minute or the cache TTL, whichever is shorter. The Handler can be
+// mounted under a prefix with http.StripPrefix.
+type Handler struct {
+	api         alloha.API
+	generator   *Generator
+	pages       int
+	ttl         time.Duration
+	loadTimeout time.Duration
+	onError     func(r *http.Request, err error)
+
+	mutex     sync.Mutex
+	series    []*alloha.SeriesData
+	loadedAt  time.Time
+	documents map[string]*document
+	// loading is the in-flight load shared by the requests
+	loading *loadCall
+	// loadErr is the error of the last failed load, which is not retried until retryAt
+	loadErr error
+	retryAt time.Time
+}
+
+// HandlerOption configures the Handler
+type HandlerOption func(h *Handler)
+
+// loadCall is a load of the episodes shared by the requests
+type loadCall struct {
+	done chan struct{}
+	err  error
+}
+
+// document is a rendered document
+type document struct {
+	body         []byte
+	etag         string
+	contentType  string
+	lastModified time.Time
+}
+
+var _ http.Handler = (*Handler)(nil)
+
+//region - Constructor
+
+// NewHandler creates a new Handler instance serving the documents of the generator
+func NewHandler(api alloha.API, generator *Generator, options ...HandlerOption) *Handler {
+	h := &Handler{
+		api:         api,
+		generator:   generator,
+		pages:       DefaultPages,
+		ttl:         DefaultCacheTTL,
+		loadTimeout: DefaultLoadTimeout,
+	}
+	for _, option := range options {
+		option(h)
+	}
+
+	return h
+}
+
+// WithPages sets the number of the latest series pages loaded from the API
+func WithPages(pages int) HandlerOption {
+	return func(h *Handler) {
+		if pages > 0 {
+			h.pages = pages
+		}
+	}
+}
+
+// WithCacheTTL sets the time to live of the loaded episodes, it is also the max-age of the Cache-Control header.
+// Zero disables the cache: the episodes are loaded on every request and the documents are served with no-cache.
+// The negative values are ignored.
+func WithCacheTTL(ttl time.Duration) HandlerOption {
+	return func(h *Handler) {
+		if ttl >= 0 {
+			h.ttl = ttl
+		}
+	}
+}
+
+// WithLoadTimeout sets the timeout of loading the episodes from the API
+func WithLoadTimeout(timeout time.Duration) HandlerOption {
+	return func(h *Handler) {
+		if timeout > 0 {
+			h.loadTimeout = timeout
+		}
+	}
+}
+
+// WithErrorHandler sets the handler of the API errors, e.g. for logging
+func WithErrorHandler(onError func(r *http.Request, err error)) HandlerOption {
+	return func(h *Handler) {
+		h.onError = onError
+	}
+}
+
+//endregion
+
+//region - Public Methods
+
+// ServeHTTP implements the http.Handler interface
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !isRoute(r.URL.Path) {
+		http.NotFound(w, r)
+		return
+	}
+
+	doc, err := h.document(r)
+	if errors.Is(err, NoSuchSitemapPartError) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		if h.onError != nil {
+			h.onError(r, err)
+		}
+		w.Header().Set("Cache-Control", "no-store")
+		http.Error(w, "upstream error", http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", doc.contentType)
+	w.Header().Set("ETag", doc.etag)
+	if h.ttl > 0 {
+		w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int64(h.ttl/time.Second)))
+	} else {
+		w.Header().Set("Cache-Control", "no-cache")
+	}
+	http.ServeContent(w, r, "", doc.lastModified, bytes.NewReader(doc.body))
+}
+
+//endregion
+
+//region - Private Methods
+
+// document returns the cached document of the path, it renders the document on the first request after the load.
+// The error handler receives the load error after the lock is released.
+func (h *Handler) document(r *http.Request) (*document, error) {
+	var staleErr error
+	defer func() {
+		if staleErr != nil && h.onError != nil {
+			h.onError(r, staleErr)
+		}
+	}()
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	stale := h.series == nil || time.Since(h.loadedAt) >= h.ttl
+	switch {
+	case stale && time.Now().Before(h.retryAt):
+		if h.series == nil {
+			return nil, h.loadErr
+		}
+	case stale:
+		call := h.loading
+		if call == nil {
+			call = &loadCall{done: make(chan struct{})}
+			h.loading = call
+			go h.reload(call)
+		}
+
+		h.mutex.Unlock()
+		select {
+		case <-call.done:
+		case <-r.Context().Done():
+			h.mutex.Lock()
+			return nil, r.Context().Err()
+		}
+		h.mutex.Lock()
+
+		switch {
+		case call.err == nil:
+		case h.series == nil:
+			return nil, call.err
+		default:
+			// The stale documents are served until the API recovers
+			staleErr = call.err
+		}
+	}
+
+	if doc, found := h.documents[r.URL.Path]; found {
+		return doc, nil
+	}
+
+	doc, err := h.render(r.URL.Path)
+	if err != nil {
+		return nil, err
+	}
+	h.documents[r.URL.Path] = doc
+
+	return doc, nil
+}
+
+// reload loads the episodes with the load timeout, the failed load is retried after the retry interval or the cache
+// TTL, whichever is shorter
+func (h *Handler) reload(call *loadCall) {
+	ctx, cancel := context.WithTimeout(context.Background(), h.loadTimeout)
+	defer cancel()
+
+	series, err := h.load(ctx)
+
+	h.mutex.Lock()
+	if err == nil {
+		h.series, h.loadedAt, h.documents = series, time.Now(), make(map[string]*document)
+	} else {
+		interval := retryInterval
+		if h.ttl < interval {
+			interval = h.ttl
+		}
+		h.loadErr, h.retryAt = err, time.Now().Add(interval)
+	}
+	h.loading, call.err = nil, err
+	h.mutex.Unlock()
+
+	close(call.done)
+}
+
+// load loads the episodes of the latest series pages
+func (h *Handler) load(ctx context.Context) ([]*alloha.SeriesData, error) {
+	series := make([]*alloha.SeriesData, 0)
+	for page := 1; page <= h.pages; page++ {
+		response, err := h.api.GetListOfLatestSeries(ctx, page)
+		if err == nil {
+			err = response.Err()
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		series = append(series, response.Data...)
+		if !response.NextPage.Valid {
+			break
+		}
+	}
+
+	return series, nil
+}
+
+// render renders the document of the path
+func (h *Handler) render(path string) (*document, error) {
+	var buffer bytes.Buffer
+	var err error
+	contentType := contentTypeXML
+
+	switch path {
+	case "/rss.xml":
+		contentType = contentTypeRSS
+		err = h.generator.RSS(&buffer, h.series)
+	case "/atom.xml":
+		contentType = contentTypeAtom
+		err = h.generator.Atom(&buffer, h.series)
+	case "/sitemap.xml":
+		err = h.writeSitemap(&buffer)
+	default:
+		part, _ := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(path, "/sitemap-"), ".xml"))
+		err = h.generator.Sitemap(&buffer, h.series, part)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var lastModified time.Time
+	for _, s := range h.series {
+		if published := h.generator.PublishedAt(s); published.After(lastModified) {
+			lastModified = published
+		}
+	}
+
+	sum := sha256.Sum256(buffer.Bytes())
+	return &document{
+		body:         buffer.Bytes(),
+		etag:         `"` + hex.EncodeToString(sum[:16]) + `"`,
+		contentType:  contentType,
+		lastModified: lastModified,
+	}, nil
+}
+
+// writeSitemap writes the sitemap if there is a single part and the sitemap index otherwise
+func (h *Handler) writeSitemap(w io.Writer) error {
+	if h.generator.SitemapParts(h.series) <= 1 {
+		return h.generator.Sitemap(w, h.series, 1)
+	}
+
+	return h.generator.SitemapIndex(w, h.series)
+}
+
+// isRoute reports whether the path is a document of the Handler
+func isRoute(path string) bool {
+	switch path {
+	case "/rss.xml", "/atom.xml", "/sitemap.xml":
+		return true
+	}
+
+	part := strings.TrimSuffix(strings.TrimPrefix(path, "/sitemap-"), ".xml")
+	if len(part) == len(path) || !strings.HasPrefix(path, "/sitemap-") || !strings.HasSuffix(path, ".xml") {
+		return false
+	}
+	number, err := strconv.Atoi(part)
+
+	return err == nil && number > 0 && strconv.Itoa(number) == part
+}
+
+//endregion
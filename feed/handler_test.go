@@ -0,0 +1,169 @@
+package feed
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/electromystyle/alloha-sdk-go/alloha"
+	"github.com/electromystyle/alloha-sdk-go/allohatest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestHandler creates a handler with the test server of the latest series
+func newTestHandler(t *testing.T, options ...HandlerOption) (http.Handler, *allohatest.Server) {
+	server := allohatest.NewServer(allohatest.WithSeries(testSeries()...), allohatest.WithPageSize(2))
+	t.Cleanup(server.Close)
+
+	client, err := server.NewClient()
+	require.NoError(t, err)
+
+	generator := NewGenerator("https://example.com",
+		WithItemURL("https://example.com/titles/{id_kp}/{season}/{episode}"),
+		WithSitemapLimit(2),
+	)
+
+	mux := http.NewServeMux()
+	mux.Handle("/feeds/", http.StripPrefix("/feeds", NewHandler(client, generator, options...)))
+
+	return mux, server
+}
+
+// get executes the request to the handler
+func get(handler http.Handler, target string, header http.Header) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, target, nil)
+	for key, values := range header {
+		r.Header[key] = values
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	return w
+}
+
+func TestHandler(t *testing.T) {
+	handler, server := newTestHandler(t)
+
+	w := get(handler, "/feeds/rss.xml", nil)
+
+	// Проверяем результат
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/rss+xml; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, "public, max-age=900", w.Header().Get("Cache-Control"))
+	assert.Equal(t, "Wed, 03 Jan 2024 07:00:00 GMT", w.Header().Get("Last-Modified"))
+	assert.Contains(t, w.Body.String(), "<guid isPermaLink=\"false\">urn:alloha:series:77044:1:2:10</guid>")
+	assert.Equal(t, 2, server.RequestCount())
+
+	w = get(handler, "/feeds/atom.xml", nil)
+	assert.Equal(t, "application/atom+xml; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), "<feed xmlns=\"http://www.w3.org/2005/Atom\">")
+
+	w = get(handler, "/feeds/sitemap.xml", nil)
+	assert.Equal(t, "application/xml; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), "<loc>https://example.com/sitemap-2.xml</loc>")
+
+	w = get(handler, "/feeds/sitemap-2.xml", nil)
+	assert.Contains(t, w.Body.String(), "<loc>https://example.com/titles/326/2/5</loc>")
+
+	// The documents are cached
+	etag := w.Header().Get("ETag")
+	w = get(handler, "/feeds/sitemap-2.xml", http.Header{"If-None-Match": {etag}})
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Equal(t, 2, server.RequestCount())
+
+	assert.Equal(t, http.StatusNotFound, get(handler, "/feeds/sitemap-3.xml", nil).Code)
+	assert.Equal(t, http.StatusNotFound, get(handler, "/feeds/sitemap-01.xml", nil).Code)
+	assert.Equal(t, http.StatusNotFound, get(handler, "/feeds/feed.xml", nil).Code)
+
+	r := httptest.NewRequest(http.MethodPost, "/feeds/rss.xml", nil)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+}
+
+func TestHandler_Errors(t *testing.T) {
+	var errs []error
+	handler, server := newTestHandler(t, WithCacheTTL(0), WithPages(1), WithErrorHandler(func(r *http.Request, err error) {
+		errs = append(errs, err)
+	}))
+
+	server.FailNext(1, http.StatusInternalServerError)
+	w := get(handler, "/feeds/rss.xml", nil)
+
+	// Проверяем результат
+	assert.Equal(t, http.StatusBadGateway, w.Code)
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+	require.Len(t, errs, 1)
+
+	w = get(handler, "/feeds/rss.xml", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "no-cache", w.Header().Get("Cache-Control"))
+
+	// The stale documents are served when the API fails
+	server.AddSeries(&alloha.SeriesData{Name: "Бригада", IDKp: 77044, Season: 1, Episode: 3, Date: time.Now().Format("2006-01-02")})
+	server.FailNext(1, http.StatusInternalServerError)
+	w = get(handler, "/feeds/rss.xml", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "S01E03")
+	assert.Len(t, errs, 2)
+}
+
+func TestHandler_SlowErrorHandler(t *testing.T) {
+	entered, release := make(chan struct{}), make(chan struct{})
+	handler, server := newTestHandler(t, WithCacheTTL(0), WithPages(1), WithErrorHandler(func(r *http.Request, err error) {
+		close(entered)
+		<-release
+	}))
+	require.Equal(t, http.StatusOK, get(handler, "/feeds/rss.xml", nil).Code)
+
+	// Обработчик ошибок блокируется при отдаче устаревшего документа
+	server.FailNext(1, http.StatusInternalServerError)
+	stale := make(chan int, 1)
+	go func() {
+		stale <- get(handler, "/feeds/rss.xml", nil).Code
+	}()
+	<-entered
+
+	// Проверяем, что остальные запросы не ждут обработчик ошибок
+	assert.Equal(t, http.StatusOK, get(handler, "/feeds/atom.xml", nil).Code)
+	close(release)
+	assert.Equal(t, http.StatusOK, <-stale)
+}
+
+func TestHandler_SharedLoad(t *testing.T) {
+	handler, server := newTestHandler(t, WithPages(1))
+	server.SetLatency(50 * time.Millisecond)
+
+	var wg sync.WaitGroup
+	codes := make(chan int, 5)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes <- get(handler, "/feeds/rss.xml", nil).Code
+		}()
+	}
+	wg.Wait()
+	close(codes)
+
+	// Проверяем результат
+	for code := range codes {
+		assert.Equal(t, http.StatusOK, code)
+	}
+	assert.Equal(t, 1, server.RequestCount())
+}
+
+func TestHandler_RetryBackoff(t *testing.T) {
+	handler, server := newTestHandler(t, WithPages(1))
+
+	server.FailNext(1, http.StatusInternalServerError)
+	assert.Equal(t, http.StatusBadGateway, get(handler, "/feeds/rss.xml", nil).Code)
+
+	// Проверяем результат: повторная загрузка откладывается
+	assert.Equal(t, http.StatusBadGateway, get(handler, "/feeds/atom.xml", nil).Code)
+	assert.Equal(t, 1, server.RequestCount())
+}
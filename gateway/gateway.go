@@ -0,0 +1,382 @@
+// Package gateway serves the Alloha API as resource-style REST/JSON endpoints with the normalized models:
+//
+//	GET /titles/kp/{id}
+//	GET /titles/imdb/{id}
+//	GET /titles/{kp id}/seasons/{season}/episodes
+//	GET /search?q={name}
+//	GET /latest/series?page={page}
+//
+// The Handler can be mounted on any http.ServeMux, under a prefix with http.StripPrefix.
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/electromystyle/alloha-sdk-go/alloha"
+)
+
+// DefaultMaxAge is the default max-age of the Cache-Control header of the successful responses
+const DefaultMaxAge = 5 * time.Minute
+
+// Codes of the error responses
+const (
+	CodeBadRequest       = "bad_request"
+	CodeNotFound         = "not_found"
+	CodeMethodNotAllowed = "method_not_allowed"
+	CodeUpstreamError    = "upstream_error"
+	CodeUpstreamTimeout  = "upstream_timeout"
+)
+
+// ErrorResponse is the body of the error responses
+type ErrorResponse struct {
+	Error ErrorBody `json:"error"`
+}
+
+// ErrorBody describes the error
+type ErrorBody struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Handler is the REST gateway
+type Handler struct {
+	api     alloha.API
+	maxAge  time.Duration
+	onError func(r *http.Request, err error)
+}
+
+// Option is the Handler option
+type Option func(h *Handler)
+
+// statusError is an error with the HTTP status code of the response
+type statusError struct {
+	status  int
+	code    string
+	message string
+}
+
+var _ http.Handler = (*Handler)(nil)
+
+//region - Constructor
+
+// NewHandler creates a new Handler instance
+func NewHandler(api alloha.API, options ...Option) *Handler {
+	h := &Handler{api: api, maxAge: DefaultMaxAge}
+	for _, option := range options {
+		option(h)
+	}
+
+	return h
+}
+
+// WithMaxAge sets the max-age of the Cache-Control header of the successful responses, 0 disables caching
+func WithMaxAge(maxAge time.Duration) Option {
+	return func(h *Handler) {
+		h.maxAge = maxAge
+	}
+}
+
+// WithErrorHandler sets the handler of the upstream errors, e.g. for logging
+func WithErrorHandler(onError func(r *http.Request, err error)) Option {
+	return func(h *Handler) {
+		h.onError = onError
+	}
+}
+
+//endregion
+
+//region - Public Methods
+
+// ServeHTTP implements the http.Handler interface
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		h.writeError(w, r, &statusError{
+			status:  http.StatusMethodNotAllowed,
+			code:    CodeMethodNotAllowed,
+			message: "method not allowed",
+		})
+		return
+	}
+
+	value, err := h.route(r)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	h.write(w, r, value)
+}
+
+//endregion
+
+//region - Private Methods
+
+// route executes the request matching the path
+func (h *Handler) route(r *http.Request) (interface{}, error) {
+	ctx := r.Context()
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+
+	switch {
+	case len(segments) == 3 && segments[0] == "titles" && segments[1] == "kp":
+		id, err := parseID("kp id", segments[2])
+		if err != nil {
+			return nil, err
+		}
+		return h.title(ctx, id)
+	case len(segments) == 3 && segments[0] == "titles" && segments[1] == "imdb":
+		return h.titleByIMDbId(ctx, segments[2])
+	case len(segments) == 5 && segments[0] == "titles" && segments[2] == "seasons" && segments[4] == "episodes":
+		id, err := parseID("kp id", segments[1])
+		if err != nil {
+			return nil, err
+		}
+		season, err := parseID("season", segments[3])
+		if err != nil {
+			return nil, err
+		}
+		return h.episodes(ctx, id, season)
+	case len(segments) == 1 && segments[0] == "search":
+		return h.search(ctx, strings.TrimSpace(r.URL.Query().Get("q")))
+	case len(segments) == 2 && segments[0] == "latest" && segments[1] == "series":
+		page := 1
+		if value := r.URL.Query().Get("page"); len(value) > 0 {
+			var err error
+			if page, err = parseID("page", value); err != nil {
+				return nil, err
+			}
+		}
+		return h.latestSeries(ctx, page)
+	default:
+		return nil, &statusError{status: http.StatusNotFound, code: CodeNotFound, message: "resource not found"}
+	}
+}
+
+// title returns the title by the Kinopoisk ID
+func (h *Handler) title(ctx context.Context, id int) (*Title, error) {
+	movie, err := h.findByKPId(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewTitle(movie), nil
+}
+
+// titleByIMDbId returns the title by the IMDb ID
+func (h *Handler) titleByIMDbId(ctx context.Context, id string) (*Title, error) {
+	movie, err := movieData(h.api.FindByIMDbId(ctx, id))
+	if err != nil {
+		return nil, err
+	}
+
+	return NewTitle(movie), nil
+}
+
+// episodes returns the episodes of the season of the series
+func (h *Handler) episodes(ctx context.Context, id, season int) ([]*Episode, error) {
+	movie, err := h.findByKPId(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	episodes, found := NewEpisodes(movie, season)
+	if !found {
+		return nil, &statusError{status: http.StatusNotFound, code: CodeNotFound, message: "season not found"}
+	}
+
+	return episodes, nil
+}
+
+// search returns the titles matching the name, the empty list if there are no such titles
+func (h *Handler) search(ctx context.Context, query string) (*Page, error) {
+	if len(query) <= 0 {
+		return nil, &statusError{status: http.StatusBadRequest, code: CodeBadRequest, message: "q param is required"}
+	}
+
+	response, err := h.api.SearchListByName(ctx, query)
+	if err == nil {
+		err = response.Err()
+	}
+	if alloha.IsNotFound(err) {
+		return &Page{Items: []*Title{}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	titles := make([]*Title, 0, len(response.Data))
+	for _, movie := range response.Data {
+		if movie != nil {
+			titles = append(titles, NewSearchTitle(movie))
+		}
+	}
+
+	return &Page{Items: titles, NextPage: intPtr(response.NextPage), PrevPage: intPtr(response.PrevPage)}, nil
+}
+
+// latestSeries returns the page of the latest series list
+func (h *Handler) latestSeries(ctx context.Context, page int) (*Page, error) {
+	response, err := h.api.GetListOfLatestSeries(ctx, page)
+	if err == nil {
+		err = response.Err()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	episodes := make([]*LatestEpisode, 0, len(response.Data))
+	for _, series := range response.Data {
+		if series != nil {
+			episodes = append(episodes, NewLatestEpisode(series))
+		}
+	}
+
+	return &Page{Items: episodes, NextPage: intPtr(response.NextPage), PrevPage: intPtr(response.PrevPage)}, nil
+}
+
+// findByKPId returns the title data by the Kinopoisk ID
+func (h *Handler) findByKPId(ctx context.Context, id int) (*alloha.MovieData, error) {
+	return movieData(h.api.FindByKPId(ctx, id))
+}
+
+// write writes the successful response with the ETag and the Cache-Control headers. It responds with the 304 status
+// code if the client has the same representation.
+func (h *Handler) write(w http.ResponseWriter, r *http.Request, value interface{}) {
+	body, err := json.Marshal(value)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	w.Header().Set("ETag", etag)
+	if h.maxAge > 0 {
+		w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int64(h.maxAge/time.Second)))
+	} else {
+		w.Header().Set("Cache-Control", "no-cache")
+	}
+
+	if matchETag(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)+1))
+	w.WriteHeader(http.StatusOK)
+	if r.Method != http.MethodHead {
+		_, _ = w.Write(append(body, '\n'))
+	}
+}
+
+// writeError writes the error response. The bad request and not found errors are mapped to the 400 and 404 status
+// codes, the timeouts to 504 and the other upstream errors to 502.
+func (h *Handler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	var statusErr *statusError
+	if !errors.As(err, &statusErr) {
+		statusErr = upstreamError(err)
+		if statusErr == nil {
+			// The client has gone away, there is nobody to respond to
+			return
+		}
+		if h.onError != nil && statusErr.status >= http.StatusInternalServerError {
+			h.onError(r, err)
+		}
+	}
+
+	var body bytes.Buffer
+	_ = json.NewEncoder(&body).Encode(&ErrorResponse{Error: ErrorBody{Code: statusErr.code, Message: statusErr.message}})
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(statusErr.status)
+	if r.Method != http.MethodHead {
+		_, _ = w.Write(body.Bytes())
+	}
+}
+
+// upstreamError maps the SDK error to the response, it returns nil if the request was canceled
+func upstreamError(err error) *statusError {
+	var statusCodeErr *alloha.UnexpectedStatusCodeError
+
+	switch {
+	case alloha.IsNotFound(err):
+		return &statusError{status: http.StatusNotFound, code: CodeNotFound, message: "title not found"}
+	case errors.Is(err, alloha.EmptyIMDbIdParameterError),
+		errors.Is(err, alloha.InvalidKPIdParameterError),
+		errors.Is(err, alloha.InvalidPageNumberParameterError):
+		return &statusError{status: http.StatusBadRequest, code: CodeBadRequest, message: err.Error()}
+	case errors.Is(err, context.Canceled):
+		return nil
+	case errors.Is(err, context.DeadlineExceeded):
+		return &statusError{status: http.StatusGatewayTimeout, code: CodeUpstreamTimeout, message: "upstream timeout"}
+	case errors.As(err, &statusCodeErr):
+		return &statusError{
+			status:  http.StatusBadGateway,
+			code:    CodeUpstreamError,
+			message: fmt.Sprintf("upstream responded with the status code %d", statusCodeErr.StatusCode),
+		}
+	default:
+		return &statusError{status: http.StatusBadGateway, code: CodeUpstreamError, message: "upstream request failed"}
+	}
+}
+
+// movieData returns the title data of the response, the response without the title is reported as not found
+func movieData(response *alloha.FindOneResponse, err error) (*alloha.MovieData, error) {
+	if err == nil {
+		err = response.Err()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if response.Data == nil {
+		return nil, &statusError{status: http.StatusNotFound, code: CodeNotFound, message: "title not found"}
+	}
+
+	return response.Data, nil
+}
+
+// parseID parses the positive number of the path or the query
+func parseID(name, value string) (int, error) {
+	id, err := strconv.Atoi(value)
+	if err != nil || id <= 0 {
+		return 0, &statusError{
+			status:  http.StatusBadRequest,
+			code:    CodeBadRequest,
+			message: name + " must be a positive number",
+		}
+	}
+
+	return id, nil
+}
+
+// matchETag reports whether the If-None-Match header contains the ETag
+func matchETag(header, etag string) bool {
+	for _, value := range strings.Split(header, ",") {
+		value = strings.TrimPrefix(strings.TrimSpace(value), "W/")
+		if value == etag || value == "*" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Error implements the error interface
+func (e *statusError) Error() string {
+	return e.message
+}
+
+//endregion
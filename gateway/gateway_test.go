@@ -0,0 +1,204 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/electromystyle/alloha-sdk-go/alloha"
+	"github.com/electromystyle/alloha-sdk-go/allohamock"
+	"github.com/electromystyle/alloha-sdk-go/allohatest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestHandler creates a gateway in front of a fake API server, mounted on the mux under the /api prefix
+func newTestHandler(t *testing.T, options ...Option) *http.ServeMux {
+	server := allohatest.NewServer(
+		allohatest.WithMovies(&alloha.MovieData{
+			Name:            "Бригада",
+			IDKp:            77044,
+			IDImdb:          "tt0330013",
+			IDTmdb:          alloha.NullInt32{Int32: 8943, Valid: true},
+			Year:            2002,
+			Category:        2,
+			Country:         "Россия",
+			Genre:           "драма, криминал",
+			AgeRestrictions: alloha.NullInt32{Int32: 18, Valid: true},
+			RatingKp:        8.3,
+			Seasons: map[string]alloha.SeasonIframe{
+				"1": {Season: 1, Episodes: map[string]alloha.EpisodeIframe{
+					"10": {Episode: 10, Translation: map[string]alloha.TranslationIframe{"66": {Name: "Оригинал"}}},
+					"2":  {Episode: 2},
+				}},
+			},
+			TranslationIframe: map[string]alloha.TranslationIframe{
+				"66": {Name: "Оригинал", Quality: "WEB-DL"},
+				"10": {Name: "LostFilm"},
+			},
+		}),
+		allohatest.WithSeries(&alloha.SeriesData{Name: "Бригада", IDKp: 77044, Season: 1, Episode: 10, Translation: 66}),
+	)
+	t.Cleanup(server.Close)
+
+	client, err := server.NewClient()
+	require.NoError(t, err)
+
+	mux := http.NewServeMux()
+	mux.Handle("/api/", http.StripPrefix("/api", NewHandler(client, options...)))
+
+	return mux
+}
+
+// get executes the request to the handler
+func get(handler http.Handler, target string, header http.Header) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, target, nil)
+	for key, values := range header {
+		r.Header[key] = values
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	return w
+}
+
+func TestHandler_Title(t *testing.T) {
+	mux := newTestHandler(t, WithMaxAge(time.Minute))
+
+	w := get(mux, "/api/titles/kp/77044", nil)
+
+	// Проверяем результат
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "public, max-age=60", w.Header().Get("Cache-Control"))
+	assert.NotEmpty(t, w.Header().Get("ETag"))
+
+	title := &Title{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), title))
+	assert.Equal(t, 77044, title.IDs.Kp)
+	assert.Equal(t, 8943, *title.IDs.Tmdb)
+	assert.Nil(t, title.IDs.WorldArt)
+	assert.Equal(t, []string{"драма", "криминал"}, title.Genres)
+	assert.Equal(t, []string{"Россия"}, title.Countries)
+	assert.Equal(t, 18, *title.AgeRestriction)
+	assert.Equal(t, []*Season{{Number: 1, EpisodesCount: 2}}, title.Seasons)
+	assert.Equal(t, []*Translation{{ID: 10, Name: "LostFilm"}, {ID: 66, Name: "Оригинал", Quality: "WEB-DL"}}, title.Translations)
+
+	// Проверяем повторный запрос с ETag
+	notModified := get(mux, "/api/titles/kp/77044", http.Header{"If-None-Match": {w.Header().Get("ETag")}})
+	assert.Equal(t, http.StatusNotModified, notModified.Code)
+	assert.Empty(t, notModified.Body.String())
+
+	byIMDb := get(mux, "/api/titles/imdb/tt0330013", nil)
+	assert.Equal(t, http.StatusOK, byIMDb.Code)
+	assert.Equal(t, w.Header().Get("ETag"), byIMDb.Header().Get("ETag"))
+}
+
+func TestHandler_Lists(t *testing.T) {
+	mux := newTestHandler(t)
+
+	// Проверяем серии сезона
+	w := get(mux, "/api/titles/77044/seasons/1/episodes", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "public, max-age=300", w.Header().Get("Cache-Control"))
+
+	var episodes []*Episode
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &episodes))
+	require.Len(t, episodes, 2)
+	assert.Equal(t, 2, episodes[0].Number)
+	assert.Equal(t, []*Translation{}, episodes[0].Translations)
+	assert.Equal(t, 10, episodes[1].Number)
+
+	// Проверяем поиск
+	w = get(mux, "/api/search?q=бригада", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"items":[{"ids":{"kp":77044`)
+	assert.Contains(t, w.Body.String(), `"next_page":null`)
+
+	w = get(mux, "/api/search?q=unknown", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"items":[],"next_page":null,"prev_page":null}`, w.Body.String())
+
+	// Проверяем последние серии
+	w = get(mux, "/api/latest/series?page=1", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"season":1,"episode":10,"translation":66`)
+}
+
+func TestHandler_Errors(t *testing.T) {
+	mux := newTestHandler(t)
+
+	tests := []struct {
+		name   string
+		target string
+		status int
+		code   string
+	}{
+		{name: "unknown title", target: "/api/titles/kp/1", status: http.StatusNotFound, code: CodeNotFound},
+		{name: "unknown season", target: "/api/titles/77044/seasons/5/episodes", status: http.StatusNotFound, code: CodeNotFound},
+		{name: "unknown path", target: "/api/titles", status: http.StatusNotFound, code: CodeNotFound},
+		{name: "invalid id", target: "/api/titles/kp/abc", status: http.StatusBadRequest, code: CodeBadRequest},
+		{name: "invalid page", target: "/api/latest/series?page=0", status: http.StatusBadRequest, code: CodeBadRequest},
+		{name: "empty query", target: "/api/search?q=", status: http.StatusBadRequest, code: CodeBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := get(mux, tt.target, nil)
+
+			// Проверяем результат
+			assert.Equal(t, tt.status, w.Code)
+			assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+
+			response := &ErrorResponse{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), response))
+			assert.Equal(t, tt.code, response.Error.Code)
+		})
+	}
+}
+
+func TestHandler_UpstreamErrors(t *testing.T) {
+	upstreamErr := errors.New("connection refused")
+	var handled []error
+
+	mock := &allohamock.Mock{
+		FindByKPIdFunc: func(ctx context.Context, kpId int) (*alloha.FindOneResponse, error) {
+			switch kpId {
+			case 1:
+				return nil, &alloha.UnexpectedStatusCodeError{StatusCode: http.StatusServiceUnavailable}
+			case 2:
+				return nil, context.DeadlineExceeded
+			case 4:
+				return &alloha.FindOneResponse{Status: "success"}, nil
+			default:
+				return nil, upstreamErr
+			}
+		},
+	}
+	handler := NewHandler(mock, WithErrorHandler(func(r *http.Request, err error) {
+		handled = append(handled, err)
+	}))
+
+	// Проверяем результат
+	assert.Equal(t, http.StatusBadGateway, get(handler, "/titles/kp/1", nil).Code)
+	assert.Equal(t, http.StatusGatewayTimeout, get(handler, "/titles/kp/2", nil).Code)
+
+	w := get(handler, "/titles/kp/3", nil)
+	assert.Equal(t, http.StatusBadGateway, w.Code)
+	assert.NotContains(t, w.Body.String(), upstreamErr.Error())
+	assert.Len(t, handled, 3)
+
+	// Ответ без данных о тайтле
+	assert.Equal(t, http.StatusNotFound, get(handler, "/titles/kp/4", nil).Code)
+	assert.Equal(t, http.StatusNotFound, get(handler, "/titles/4/seasons/1/episodes", nil).Code)
+
+	r := httptest.NewRequest(http.MethodPost, "/titles/kp/3", nil)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	assert.Equal(t, "GET, HEAD", w.Header().Get("Allow"))
+}
@@ -0,0 +1,300 @@
+package gateway
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/electromystyle/alloha-sdk-go/alloha"
+	"github.com/electromystyle/alloha-sdk-go/internal/strutil"
+)
+
+// IDs are the identifiers of a title in the external databases
+type IDs struct {
+	Kp            int    `json:"kp"`
+	AlternativeKp *int   `json:"alternative_kp,omitempty"`
+	Imdb          string `json:"imdb,omitempty"`
+	Tmdb          *int   `json:"tmdb,omitempty"`
+	WorldArt      *int   `json:"world_art,omitempty"`
+}
+
+// Ratings are the ratings of a title
+type Ratings struct {
+	Kp   float64 `json:"kp"`
+	Imdb float64 `json:"imdb"`
+	Mpaa string  `json:"mpaa,omitempty"`
+}
+
+// Title is a movie or a series
+type Title struct {
+	IDs             IDs      `json:"ids"`
+	Name            string   `json:"name"`
+	OriginalName    string   `json:"original_name,omitempty"`
+	AlternativeName string   `json:"alternative_name,omitempty"`
+	Year            int      `json:"year,omitempty"`
+	Category        int      `json:"category"`
+	Countries       []string `json:"countries"`
+	Genres          []string `json:"genres"`
+	Actors          []string `json:"actors"`
+	Directors       []string `json:"directors"`
+	Producers       []string `json:"producers"`
+	Premiere        string   `json:"premiere,omitempty"`
+	PremiereRu      string   `json:"premiere_ru,omitempty"`
+	AgeRestriction  *int     `json:"age_restriction,omitempty"`
+	Ratings         Ratings  `json:"ratings"`
+	Duration        string   `json:"duration,omitempty"`
+	Tagline         string   `json:"tagline,omitempty"`
+	Poster          string   `json:"poster,omitempty"`
+	Description     string   `json:"description,omitempty"`
+	Quality         string   `json:"quality,omitempty"`
+	// Number of the seasons (only for the series)
+	SeasonsCount int `json:"seasons_count,omitempty"`
+	// Seasons ordered by the number, without the episodes (only for the series)
+	Seasons []*Season `json:"seasons,omitempty"`
+	// Last season and episode (only for the search results of the series)
+	LastSeason   *int           `json:"last_season,omitempty"`
+	LastEpisode  *int           `json:"last_episode,omitempty"`
+	Translations []*Translation `json:"translations"`
+	Iframe       string         `json:"iframe,omitempty"`
+	Trailer      string         `json:"trailer,omitempty"`
+	Lgbt         bool           `json:"lgbt"`
+	Uhd          bool           `json:"uhd"`
+	DirectorsCut bool           `json:"directors_cut"`
+}
+
+// Season is a season of a series
+type Season struct {
+	Number        int    `json:"number"`
+	EpisodesCount int    `json:"episodes_count"`
+	Iframe        string `json:"iframe,omitempty"`
+}
+
+// Episode is an episode of a series
+type Episode struct {
+	Season       int            `json:"season"`
+	Number       int            `json:"number"`
+	Iframe       string         `json:"iframe,omitempty"`
+	Translations []*Translation `json:"translations"`
+}
+
+// Translation is a voice-over of a title or an episode
+type Translation struct {
+	ID      int    `json:"id"`
+	Name    string `json:"name"`
+	Quality string `json:"quality,omitempty"`
+	Iframe  string `json:"iframe,omitempty"`
+	Date    string `json:"date,omitempty"`
+	Adv     bool   `json:"adv"`
+	Lgbt    bool   `json:"lgbt"`
+	Uhd     bool   `json:"uhd"`
+}
+
+// LatestEpisode is an item of the latest series list
+type LatestEpisode struct {
+	IDs          IDs    `json:"ids"`
+	Name         string `json:"name"`
+	OriginalName string `json:"original_name,omitempty"`
+	Year         int    `json:"year,omitempty"`
+	Category     int    `json:"category"`
+	Season       int    `json:"season"`
+	Episode      int    `json:"episode"`
+	Translation  int    `json:"translation"`
+	Quality      string `json:"quality,omitempty"`
+	Date         string `json:"date,omitempty"`
+	Iframe       string `json:"iframe,omitempty"`
+	Adv          bool   `json:"adv"`
+	Lgbt         bool   `json:"lgbt"`
+	Uhd          bool   `json:"uhd"`
+}
+
+// Page is a page of a list
+type Page struct {
+	Items    interface{} `json:"items"`
+	NextPage *int        `json:"next_page"`
+	PrevPage *int        `json:"prev_page"`
+}
+
+// NewTitle converts the title data to the normalized model, nil is returned for the nil title
+func NewTitle(movie *alloha.MovieData) *Title {
+	if movie == nil {
+		return nil
+	}
+
+	return &Title{
+		IDs:             newIDs(movie.IDKp, movie.AlternativeIDKp, movie.IDImdb, movie.IDTmdb, movie.IDWorldArt),
+		Name:            movie.Name,
+		OriginalName:    movie.OriginalName,
+		AlternativeName: movie.AlternativeName,
+		Year:            movie.Year,
+		Category:        movie.Category,
+		Countries:       strutil.SplitList(movie.Country),
+		Genres:          strutil.SplitList(movie.Genre),
+		Actors:          strutil.SplitList(movie.Actors),
+		Directors:       strutil.SplitList(movie.Directors),
+		Producers:       strutil.SplitList(movie.Producers),
+		Premiere:        movie.Premiere,
+		PremiereRu:      movie.PremiereRu,
+		AgeRestriction:  intPtr(movie.AgeRestrictions),
+		Ratings:         Ratings{Kp: movie.RatingKp, Imdb: movie.RatingImdb, Mpaa: movie.RatingMpaa},
+		Duration:        movie.Time,
+		Tagline:         movie.Tagline,
+		Poster:          movie.Poster,
+		Description:     movie.Description,
+		Quality:         movie.Quality,
+		SeasonsCount:    movie.SeasonsCount,
+		Seasons:         newSeasons(movie.Seasons),
+		Translations:    newTranslations(movie.TranslationIframe),
+		Iframe:          movie.Iframe,
+		Trailer:         movie.IframeTrailer,
+		Lgbt:            movie.Lgbt,
+		Uhd:             movie.Uhd,
+		DirectorsCut:    movie.AvailableDirectorsCut,
+	}
+}
+
+// NewSearchTitle converts the search result to the normalized model
+func NewSearchTitle(movie *alloha.MovieSearchData) *Title {
+	return &Title{
+		IDs:             newIDs(movie.IDKp, movie.AlternativeIDKp, movie.IDImdb, movie.IDTmdb, movie.IDWorldArt),
+		Name:            movie.Name,
+		OriginalName:    movie.OriginalName,
+		AlternativeName: movie.AlternativeName,
+		Year:            movie.Year,
+		Category:        movie.CategoryId,
+		Countries:       strutil.SplitList(movie.Country),
+		Genres:          strutil.SplitList(movie.Genre),
+		Actors:          strutil.SplitList(movie.Actors),
+		Directors:       strutil.SplitList(movie.Directors),
+		Producers:       strutil.SplitList(movie.Producers),
+		Premiere:        movie.Premiere,
+		PremiereRu:      movie.PremiereRu,
+		AgeRestriction:  intPtr(movie.AgeRestrictions),
+		Ratings:         Ratings{Kp: movie.RatingKp, Imdb: movie.RatingImdb, Mpaa: movie.RatingMpaa},
+		Duration:        movie.Time,
+		Tagline:         movie.Tagline,
+		Poster:          movie.Poster,
+		Description:     movie.Description,
+		Quality:         movie.Quality,
+		SeasonsCount:    movie.SeasonsCount,
+		Seasons:         newSeasons(movie.Seasons),
+		LastSeason:      intPtr(movie.LastSeason),
+		LastEpisode:     intPtr(movie.LastEpisode),
+		Translations:    newTranslations(movie.TranslationIframe),
+		Iframe:          movie.Iframe,
+		Trailer:         movie.IframeTrailer,
+		Lgbt:            movie.Lgbt,
+		Uhd:             movie.Uhd,
+		DirectorsCut:    movie.AvailableDirectorsCut,
+	}
+}
+
+// NewEpisodes returns the episodes of the season ordered by the number, it reports false if there is no such season
+// or the title is nil
+func NewEpisodes(movie *alloha.MovieData, season int) ([]*Episode, bool) {
+	if movie == nil {
+		return nil, false
+	}
+
+	for _, s := range movie.Seasons {
+		if s.Season != season {
+			continue
+		}
+
+		episodes := make([]*Episode, 0, len(s.Episodes))
+		for _, e := range s.Episodes {
+			episodes = append(episodes, &Episode{
+				Season:       season,
+				Number:       e.Episode,
+				Iframe:       e.Iframe,
+				Translations: newTranslations(e.Translation),
+			})
+		}
+		sort.Slice(episodes, func(i, j int) bool {
+			return episodes[i].Number < episodes[j].Number
+		})
+
+		return episodes, true
+	}
+
+	return nil, false
+}
+
+// NewLatestEpisode converts the latest series list item to the normalized model
+func NewLatestEpisode(series *alloha.SeriesData) *LatestEpisode {
+	return &LatestEpisode{
+		IDs:          newIDs(series.IDKp, series.AlternativeIDKp, series.IDImdb, series.IDTmdb, series.IDWorldArt),
+		Name:         series.Name,
+		OriginalName: series.OriginalName,
+		Year:         series.Year,
+		Category:     series.CategoryId,
+		Season:       series.Season,
+		Episode:      series.Episode,
+		Translation:  series.Translation,
+		Quality:      series.Quality,
+		Date:         series.Date,
+		Iframe:       series.Iframe,
+		Adv:          series.Adv,
+		Lgbt:         series.Lgbt,
+		Uhd:          series.Uhd,
+	}
+}
+
+// newIDs builds the identifiers of a title
+func newIDs(kp int, alternativeKp alloha.NullInt32, imdb string, tmdb, worldArt alloha.NullInt32) IDs {
+	return IDs{
+		Kp:            kp,
+		AlternativeKp: intPtr(alternativeKp),
+		Imdb:          imdb,
+		Tmdb:          intPtr(tmdb),
+		WorldArt:      intPtr(worldArt),
+	}
+}
+
+// newSeasons returns the seasons ordered by the number
+func newSeasons(seasons map[string]alloha.SeasonIframe) []*Season {
+	if len(seasons) <= 0 {
+		return nil
+	}
+
+	result := make([]*Season, 0, len(seasons))
+	for _, s := range seasons {
+		result = append(result, &Season{Number: s.Season, EpisodesCount: len(s.Episodes), Iframe: s.Iframe})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Number < result[j].Number
+	})
+
+	return result
+}
+
+// newTranslations returns the translations ordered by the ID
+func newTranslations(translations map[string]alloha.TranslationIframe) []*Translation {
+	result := make([]*Translation, 0, len(translations))
+	for key, t := range translations {
+		id, _ := strconv.Atoi(key)
+		result = append(result, &Translation{
+			ID:      id,
+			Name:    t.Name,
+			Quality: t.Quality,
+			Iframe:  t.Iframe,
+			Date:    t.Date,
+			Adv:     t.Adv,
+			Lgbt:    t.Lgbt,
+			Uhd:     t.Uhd,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID < result[j].ID
+	})
+
+	return result
+}
+
+// intPtr returns the pointer to the value or nil if it is null
+func intPtr(value alloha.NullInt32) *int {
+	if !value.Valid {
+		return nil
+	}
+
+	v := int(value.Int32)
+	return &v
+}
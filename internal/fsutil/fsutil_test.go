@@ -0,0 +1,46 @@
+package fsutil
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteFunc(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "state", "data.json")
+
+	require.NoError(t, WriteFile(path, []byte("first")))
+	writeErr := errors.New("disk is full")
+	err := WriteFunc(path, func(w io.Writer) error {
+		_, _ = w.Write([]byte("partial"))
+		return writeErr
+	})
+
+	// Проверяем результат
+	assert.ErrorIs(t, err, writeErr)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "first", string(data))
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestAppendFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "data.jsonl")
+
+	require.NoError(t, AppendFile(path, []byte("first\n")))
+	require.NoError(t, AppendFile(path, []byte("second\n")))
+
+	// Проверяем результат
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "first\nsecond\n", string(data))
+}
@@ -0,0 +1,38 @@
+// Package strutil provides the string helpers shared by the packages of the SDK
+package strutil
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SplitList splits the comma-separated list of the API, e.g. "Россия, США", trims the items and drops the empty
+// ones. It returns the empty non-nil slice for the empty list.
+func SplitList(value string) []string {
+	result := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); len(item) > 0 {
+			result = append(result, item)
+		}
+	}
+
+	return result
+}
+
+// SortNumeric sorts the keys of the seasons, episodes and translations in the numeric order in place, the non-numeric
+// keys go last in the lexical order, e.g. "2", "10", "a"
+func SortNumeric(keys []string) {
+	sort.Slice(keys, func(i, j int) bool {
+		a, errA := strconv.Atoi(keys[i])
+		b, errB := strconv.Atoi(keys[j])
+		switch {
+		case errA == nil && errB == nil:
+			return a < b
+		case errA == nil || errB == nil:
+			return errA == nil
+		default:
+			return keys[i] < keys[j]
+		}
+	})
+}
@@ -0,0 +1,24 @@
+package strutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitList(t *testing.T) {
+	// Проверяем разбиение списка с пробелами и пустыми элементами
+	assert.Equal(t, []string{"Россия", "США"}, SplitList(" Россия, ,США, "))
+
+	// Проверяем, что для пустого списка возвращается пустой срез, а не nil
+	assert.Equal(t, []string{}, SplitList(""))
+}
+
+func TestSortNumeric(t *testing.T) {
+	keys := []string{"10", "b", "2", "a", "1"}
+
+	SortNumeric(keys)
+
+	// Проверяем числовой порядок и нечисловые ключи в конце
+	assert.Equal(t, []string{"1", "2", "10", "a", "b"}, keys)
+}
@@ -0,0 +1,111 @@
+package policy
+
+import (
+	"context"
+
+	"github.com/electromystyle/alloha-sdk-go/alloha"
+)
+
+//region - Policy Decorator
+
+// FilteredAPI is an API decorator that filters the responses by the policy, so that the paging through the latest
+// series and the search results, the stream calls and the watcher see the allowed content only. The denied items are
+// removed from the list pages and the page numbers are kept, the denied title is removed from the single title
+// response, which is then treated as not found. The responses are copied, the decorated responses are not modified.
+type FilteredAPI struct {
+	api    alloha.API
+	policy *Policy
+}
+
+var _ alloha.API = (*FilteredAPI)(nil)
+
+// NewFilteredAPI creates a new FilteredAPI instance
+func NewFilteredAPI(api alloha.API, policy *Policy) *FilteredAPI {
+	return &FilteredAPI{api: api, policy: policy}
+}
+
+// FindByIMDbId implements the API interface
+func (a *FilteredAPI) FindByIMDbId(ctx context.Context, imdbId string) (*alloha.FindOneResponse, error) {
+	response, err := a.api.FindByIMDbId(ctx, imdbId)
+
+	return a.filterOne(response), err
+}
+
+// FindByKPId implements the API interface
+func (a *FilteredAPI) FindByKPId(ctx context.Context, kpId int) (*alloha.FindOneResponse, error) {
+	response, err := a.api.FindByKPId(ctx, kpId)
+
+	return a.filterOne(response), err
+}
+
+// FindByTMDbId implements the API interface
+func (a *FilteredAPI) FindByTMDbId(ctx context.Context, tmdbId int) (*alloha.FindOneResponse, error) {
+	response, err := a.api.FindByTMDbId(ctx, tmdbId)
+
+	return a.filterOne(response), err
+}
+
+// GetListOfLatestSeries implements the API interface
+func (a *FilteredAPI) GetListOfLatestSeries(ctx context.Context, pageNum int) (*alloha.ListOfLatestSeriesResponse, error) {
+	response, err := a.api.GetListOfLatestSeries(ctx, pageNum)
+	if response == nil || response.Data == nil {
+		return response, err
+	}
+
+	filtered := *response
+	filtered.Data = a.policy.FilterSeriesList(response.Data)
+
+	return &filtered, err
+}
+
+// SearchForOneByName implements the API interface
+func (a *FilteredAPI) SearchForOneByName(ctx context.Context, movieName string) (*alloha.FindOneResponse, error) {
+	response, err := a.api.SearchForOneByName(ctx, movieName)
+
+	return a.filterOne(response), err
+}
+
+// SearchListByName implements the API interface
+func (a *FilteredAPI) SearchListByName(ctx context.Context, movieName string) (*alloha.FindListResponse, error) {
+	response, err := a.api.SearchListByName(ctx, movieName)
+	if response == nil || response.Data == nil {
+		return response, err
+	}
+
+	filtered := *response
+	filtered.Data = a.policy.FilterSearchMovies(response.Data)
+
+	return &filtered, err
+}
+
+// StreamListByName implements the API interface, the callback receives the allowed titles only
+func (a *FilteredAPI) StreamListByName(ctx context.Context, movieName string, fn func(movie *alloha.MovieSearchData) error) (*alloha.ListPageInfo, error) {
+	if fn == nil {
+		return a.api.StreamListByName(ctx, movieName, fn)
+	}
+
+	return a.api.StreamListByName(ctx, movieName, a.policy.FilterSearchFunc(fn))
+}
+
+// StreamListOfLatestSeries implements the API interface, the callback receives the allowed episodes only
+func (a *FilteredAPI) StreamListOfLatestSeries(ctx context.Context, pageNum int, fn func(series *alloha.SeriesData) error) (*alloha.ListPageInfo, error) {
+	if fn == nil {
+		return a.api.StreamListOfLatestSeries(ctx, pageNum, fn)
+	}
+
+	return a.api.StreamListOfLatestSeries(ctx, pageNum, a.policy.FilterSeriesFunc(fn))
+}
+
+// filterOne returns the copy of the response with the filtered title, the denied title is removed
+func (a *FilteredAPI) filterOne(response *alloha.FindOneResponse) *alloha.FindOneResponse {
+	if response == nil || response.Data == nil {
+		return response
+	}
+
+	filtered := *response
+	filtered.Data, _ = a.policy.FilterMovie(response.Data)
+
+	return &filtered
+}
+
+//endregion
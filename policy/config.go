@@ -0,0 +1,124 @@
+package policy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Config is a set of the policies by the name, e.g.
+//
+//	{
+//	  "policies": [
+//	    {"name": "kids", "max_age": 12, "deny_unknown_age": true, "deny_mpaa": ["r", "nc17"]},
+//	    {"name": "ru", "mode": "translation", "deny_lgbt": true, "deny_adv": true}
+//	  ]
+//	}
+//
+// The YAML config is loaded by the optional contrib/yamlpolicy module.
+type Config struct {
+	Policies []*Policy `json:"policies" yaml:"policies"`
+}
+
+// DeniedError represents an error when the content is denied by the policy
+type DeniedError struct {
+	Reasons []Reason
+}
+
+// InvalidPolicyError represents an error in the policy config
+type InvalidPolicyError struct {
+	Name   string
+	Reason string
+}
+
+// Error implements the error interface
+func (e *DeniedError) Error() string {
+	reasons := make([]string, 0, len(e.Reasons))
+	for _, reason := range e.Reasons {
+		reasons = append(reasons, reason.String())
+	}
+
+	return "denied by the policy: " + strings.Join(reasons, "; ")
+}
+
+// Error implements the error interface
+func (e *InvalidPolicyError) Error() string {
+	return fmt.Sprintf("invalid policy %q: %s", e.Name, e.Reason)
+}
+
+//region - Constructor
+
+// Load reads the config in the JSON format, the unknown fields are rejected
+func Load(r io.Reader) (*Config, error) {
+	config := &Config{}
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(config); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("couldn't parse the policy config: %w", err)
+	}
+
+	return config, config.Validate()
+}
+
+// LoadFile reads the config file in the JSON format
+func LoadFile(path string) (*Config, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+
+	return Load(file)
+}
+
+//endregion
+
+//region - Public Methods
+
+// Get returns the policy by the name
+func (c *Config) Get(name string) (*Policy, bool) {
+	for _, policy := range c.Policies {
+		if policy.Name == name {
+			return policy, true
+		}
+	}
+
+	return nil, false
+}
+
+// Validate checks the names, the modes and the maximum ages of the policies
+func (c *Config) Validate() error {
+	names := make(map[string]bool, len(c.Policies))
+	for i, policy := range c.Policies {
+		if policy == nil || len(policy.Name) <= 0 {
+			return &InvalidPolicyError{Name: fmt.Sprintf("#%d", i+1), Reason: "name is empty"}
+		}
+		if names[policy.Name] {
+			return &InvalidPolicyError{Name: policy.Name, Reason: "duplicate name"}
+		}
+		names[policy.Name] = true
+
+		if err := policy.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Validate checks the mode and the maximum age of the policy
+func (p *Policy) Validate() error {
+	if len(p.Mode) > 0 && p.Mode != ModeTitle && p.Mode != ModeTranslation {
+		return &InvalidPolicyError{Name: p.Name, Reason: fmt.Sprintf("unknown mode %q", p.Mode)}
+	}
+	if p.MaxAge < 0 {
+		return &InvalidPolicyError{Name: p.Name, Reason: "max age is negative"}
+	}
+
+	return nil
+}
+
+//endregion
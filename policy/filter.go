@@ -0,0 +1,147 @@
+package policy
+
+import "github.com/electromystyle/alloha-sdk-go/alloha"
+
+//region - Public Methods
+
+// FilterMovie returns the allowed title or nil with the decision. In the translation mode the returned title is
+// a copy without the denied translations and without the episodes left with no allowed translations.
+func (p *Policy) FilterMovie(movie *alloha.MovieData) (*alloha.MovieData, *Decision) {
+	decision := p.EvaluateMovie(movie)
+	if !decision.Allowed {
+		return nil, decision
+	}
+	if p.Mode != ModeTranslation {
+		return movie, decision
+	}
+
+	filtered := *movie
+	filtered.TranslationIframe = p.filterTranslations(movie.TranslationIframe)
+	filtered.Seasons = p.filterSeasons(movie.Seasons)
+
+	return &filtered, decision
+}
+
+// FilterSearchMovie returns the allowed title of the search results or nil with the decision, see FilterMovie
+func (p *Policy) FilterSearchMovie(movie *alloha.MovieSearchData) (*alloha.MovieSearchData, *Decision) {
+	decision := p.EvaluateSearchMovie(movie)
+	if !decision.Allowed {
+		return nil, decision
+	}
+	if p.Mode != ModeTranslation {
+		return movie, decision
+	}
+
+	filtered := *movie
+	filtered.TranslationIframe = p.filterTranslations(movie.TranslationIframe)
+	filtered.Seasons = p.filterSeasons(movie.Seasons)
+
+	return &filtered, decision
+}
+
+// FilterMovies returns the allowed titles, see FilterMovie. The nil titles are skipped.
+func (p *Policy) FilterMovies(movies []*alloha.MovieData) []*alloha.MovieData {
+	result := make([]*alloha.MovieData, 0, len(movies))
+	for _, movie := range movies {
+		if filtered, _ := p.FilterMovie(movie); filtered != nil {
+			result = append(result, filtered)
+		}
+	}
+
+	return result
+}
+
+// FilterSearchMovies returns the allowed titles of the search results, e.g. of FindListResponse.Data. The nil titles
+// are skipped.
+func (p *Policy) FilterSearchMovies(movies []*alloha.MovieSearchData) []*alloha.MovieSearchData {
+	result := make([]*alloha.MovieSearchData, 0, len(movies))
+	for _, movie := range movies {
+		if filtered, _ := p.FilterSearchMovie(movie); filtered != nil {
+			result = append(result, filtered)
+		}
+	}
+
+	return result
+}
+
+// FilterSeriesList returns the allowed latest series episodes, e.g. of ListOfLatestSeriesResponse.Data. The nil
+// episodes are skipped.
+func (p *Policy) FilterSeriesList(series []*alloha.SeriesData) []*alloha.SeriesData {
+	result := make([]*alloha.SeriesData, 0, len(series))
+	for _, s := range series {
+		if p.AllowSeries(s) {
+			result = append(result, s)
+		}
+	}
+
+	return result
+}
+
+// FilterSeriesFunc wraps the callback of StreamListOfLatestSeries to skip the denied episodes
+func (p *Policy) FilterSeriesFunc(fn func(series *alloha.SeriesData) error) func(series *alloha.SeriesData) error {
+	return func(series *alloha.SeriesData) error {
+		if !p.AllowSeries(series) {
+			return nil
+		}
+
+		return fn(series)
+	}
+}
+
+// FilterSearchFunc wraps the callback of StreamListByName to skip the denied titles and to remove the denied
+// translations in the translation mode
+func (p *Policy) FilterSearchFunc(fn func(movie *alloha.MovieSearchData) error) func(movie *alloha.MovieSearchData) error {
+	return func(movie *alloha.MovieSearchData) error {
+		filtered, _ := p.FilterSearchMovie(movie)
+		if filtered == nil {
+			return nil
+		}
+
+		return fn(filtered)
+	}
+}
+
+//endregion
+
+//region - Private Methods
+
+// filterTranslations returns the allowed translations or nil if there are no translations
+func (p *Policy) filterTranslations(translations map[string]alloha.TranslationIframe) map[string]alloha.TranslationIframe {
+	if translations == nil {
+		return nil
+	}
+
+	return p.allowedTranslations(translations)
+}
+
+// filterSeasons returns the seasons without the denied translations of the episodes. The episodes and the seasons
+// left with no allowed translations are removed.
+func (p *Policy) filterSeasons(seasons map[string]alloha.SeasonIframe) map[string]alloha.SeasonIframe {
+	if seasons == nil {
+		return nil
+	}
+
+	result := make(map[string]alloha.SeasonIframe, len(seasons))
+	for seasonKey, season := range seasons {
+		episodes := make(map[string]alloha.EpisodeIframe, len(season.Episodes))
+		for episodeKey, episode := range season.Episodes {
+			translations := p.filterTranslations(episode.Translation)
+			if len(episode.Translation) > 0 && len(translations) <= 0 {
+				continue
+			}
+
+			episode.Translation = translations
+			episodes[episodeKey] = episode
+		}
+		if len(season.Episodes) > 0 && len(episodes) <= 0 {
+			continue
+		}
+
+		season.Episodes = episodes
+		result[seasonKey] = season
+	}
+
+	return result
+}
+
+//endregion
@@ -0,0 +1,320 @@
+// Package policy filters the titles, the latest series episodes and the translations by the content policies of
+// the regions. A Policy evaluates the age restriction, the MPAA rating, the LGBT and advertising flags and
+// the countries, and returns the Decision with the reasons of the denial. The policies are loaded from the JSON
+// config, see LoadFile, or from the YAML config with the optional contrib/yamlpolicy module.
+package policy
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/electromystyle/alloha-sdk-go/alloha"
+	"github.com/electromystyle/alloha-sdk-go/internal/strutil"
+)
+
+// Mode is the evaluation mode of the LGBT and advertising rules
+type Mode string
+
+// Evaluation modes
+const (
+	// ModeTitle evaluates the LGBT flag of the title, the title with advertising in all translations is denied
+	ModeTitle Mode = "title"
+	// ModeTranslation evaluates the LGBT and advertising flags of every translation, the denied translations are
+	// removed and the title without the allowed translations is denied
+	ModeTranslation Mode = "translation"
+)
+
+// Rules of the policy
+const (
+	RuleAge     = "age"
+	RuleMpaa    = "mpaa"
+	RuleLgbt    = "lgbt"
+	RuleAdv     = "adv"
+	RuleCountry = "country"
+	RuleData    = "data"
+)
+
+// Policy is a content policy of a region. The zero value allows everything.
+type Policy struct {
+	// Name of the policy, e.g. the region code
+	Name string `json:"name" yaml:"name"`
+	// Mode of the LGBT and advertising rules, ModeTitle by default
+	Mode Mode `json:"mode,omitempty" yaml:"mode,omitempty"`
+	// Maximum age restriction, e.g. 16 denies the 18+ titles, zero disables the rule
+	MaxAge int `json:"max_age,omitempty" yaml:"max_age,omitempty"`
+	// DenyUnknownAge denies the titles without the age restriction when MaxAge is set
+	DenyUnknownAge bool `json:"deny_unknown_age,omitempty" yaml:"deny_unknown_age,omitempty"`
+	// Denied MPAA ratings, e.g. "r" and "nc17", the case and the dashes are ignored
+	DenyMpaa []string `json:"deny_mpaa,omitempty" yaml:"deny_mpaa,omitempty"`
+	// DenyLgbt denies the LGBT content
+	DenyLgbt bool `json:"deny_lgbt,omitempty" yaml:"deny_lgbt,omitempty"`
+	// DenyAdv denies the translations with advertising
+	DenyAdv bool `json:"deny_adv,omitempty" yaml:"deny_adv,omitempty"`
+	// Allowed countries, the title is allowed if any of its countries is allowed, empty allows all countries
+	AllowCountries []string `json:"allow_countries,omitempty" yaml:"allow_countries,omitempty"`
+	// Denied countries, the title is denied if any of its countries is denied
+	DenyCountries []string `json:"deny_countries,omitempty" yaml:"deny_countries,omitempty"`
+}
+
+// Decision is the result of the evaluation
+type Decision struct {
+	Allowed bool
+	// Reasons of the denial
+	Reasons []Reason
+}
+
+// Reason is a violated rule
+type Reason struct {
+	Rule    string
+	Message string
+}
+
+// subject holds the evaluated fields of a title
+type subject struct {
+	age          alloha.NullInt32
+	mpaa         string
+	lgbt         bool
+	country      string
+	translations map[string]alloha.TranslationIframe
+}
+
+// String implements the fmt.Stringer interface
+func (r Reason) String() string {
+	return r.Rule + ": " + r.Message
+}
+
+//region - Public Methods
+
+// EvaluateMovie evaluates the title, the nil title is denied
+func (p *Policy) EvaluateMovie(movie *alloha.MovieData) *Decision {
+	if movie == nil {
+		return missingData("title")
+	}
+
+	return p.evaluate(subjectOfMovie(movie))
+}
+
+// EvaluateSearchMovie evaluates the title of the search results, the nil title is denied
+func (p *Policy) EvaluateSearchMovie(movie *alloha.MovieSearchData) *Decision {
+	if movie == nil {
+		return missingData("title")
+	}
+
+	return p.evaluate(subjectOfSearchMovie(movie))
+}
+
+// EvaluateSeries evaluates the latest series episode in its translation. The latest series list has no age
+// restriction, MPAA rating and countries, only the LGBT and advertising rules apply unless DenyUnknownAge is set.
+// The nil episode is denied.
+func (p *Policy) EvaluateSeries(series *alloha.SeriesData) *Decision {
+	if series == nil {
+		return missingData("episode")
+	}
+
+	decision := &Decision{}
+	p.checkAge(decision, alloha.NullInt32{})
+	p.checkFlags(decision, "", series.Lgbt, series.Adv)
+	decision.Allowed = len(decision.Reasons) <= 0
+
+	return decision
+}
+
+// EvaluateTranslation evaluates the LGBT and advertising flags of the translation
+func (p *Policy) EvaluateTranslation(translation alloha.TranslationIframe) *Decision {
+	decision := &Decision{}
+	p.checkFlags(decision, "", translation.Lgbt, translation.Adv)
+	decision.Allowed = len(decision.Reasons) <= 0
+
+	return decision
+}
+
+// AllowSeries reports whether the latest series episode is allowed, it can be passed to watch.WithFilter
+func (p *Policy) AllowSeries(series *alloha.SeriesData) bool {
+	return p.EvaluateSeries(series).Allowed
+}
+
+// Err returns the error with the reasons if the title is denied
+func (d *Decision) Err() error {
+	if d.Allowed {
+		return nil
+	}
+
+	return &DeniedError{Reasons: d.Reasons}
+}
+
+//endregion
+
+//region - Private Methods
+
+// evaluate evaluates the title rules and the LGBT and advertising rules in the mode of the policy
+func (p *Policy) evaluate(s *subject) *Decision {
+	decision := &Decision{}
+	p.checkAge(decision, s.age)
+	p.checkMpaa(decision, s.mpaa)
+	p.checkCountries(decision, s.country)
+
+	if p.Mode == ModeTranslation {
+		if len(s.translations) > 0 && len(p.allowedTranslations(s.translations)) <= 0 {
+			for _, key := range sortedKeys(s.translations) {
+				p.checkFlags(decision, "translation "+key+" ("+s.translations[key].Name+"): ",
+					s.translations[key].Lgbt, s.translations[key].Adv)
+			}
+		}
+	} else {
+		p.checkFlags(decision, "", s.lgbt, len(s.translations) > 0 && allWithAdv(s.translations))
+	}
+
+	decision.Allowed = len(decision.Reasons) <= 0
+	return decision
+}
+
+// allowedTranslations returns the translations allowed by the LGBT and advertising rules
+func (p *Policy) allowedTranslations(translations map[string]alloha.TranslationIframe) map[string]alloha.TranslationIframe {
+	allowed := make(map[string]alloha.TranslationIframe, len(translations))
+	for key, translation := range translations {
+		if (!p.DenyLgbt || !translation.Lgbt) && (!p.DenyAdv || !translation.Adv) {
+			allowed[key] = translation
+		}
+	}
+
+	return allowed
+}
+
+// checkAge adds the reason if the age restriction exceeds the maximum age or it is unknown
+func (p *Policy) checkAge(decision *Decision, age alloha.NullInt32) {
+	if p.MaxAge <= 0 {
+		return
+	}
+
+	switch {
+	case !age.Valid && p.DenyUnknownAge:
+		decision.Reasons = append(decision.Reasons, Reason{Rule: RuleAge, Message: "age restriction is unknown"})
+	case age.Valid && int(age.Int32) > p.MaxAge:
+		decision.Reasons = append(decision.Reasons, Reason{
+			Rule:    RuleAge,
+			Message: fmt.Sprintf("age restriction %d+ exceeds %d+", age.Int32, p.MaxAge),
+		})
+	}
+}
+
+// checkMpaa adds the reason if the MPAA rating is denied
+func (p *Policy) checkMpaa(decision *Decision, rating string) {
+	if len(rating) <= 0 {
+		return
+	}
+
+	for _, denied := range p.DenyMpaa {
+		if normalizeMpaa(denied) == normalizeMpaa(rating) {
+			decision.Reasons = append(decision.Reasons, Reason{
+				Rule:    RuleMpaa,
+				Message: fmt.Sprintf("MPAA rating %s is denied", rating),
+			})
+			return
+		}
+	}
+}
+
+// checkCountries adds the reasons if a country is denied or no country is allowed
+func (p *Policy) checkCountries(decision *Decision, value string) {
+	countries := strutil.SplitList(value)
+
+	for _, country := range countries {
+		if containsFold(p.DenyCountries, country) {
+			decision.Reasons = append(decision.Reasons, Reason{
+				Rule:    RuleCountry,
+				Message: fmt.Sprintf("country %s is denied", country),
+			})
+		}
+	}
+
+	if len(p.AllowCountries) <= 0 || len(countries) <= 0 {
+		return
+	}
+	for _, country := range countries {
+		if containsFold(p.AllowCountries, country) {
+			return
+		}
+	}
+	decision.Reasons = append(decision.Reasons, Reason{
+		Rule:    RuleCountry,
+		Message: fmt.Sprintf("none of the countries %s is allowed", strings.Join(countries, ", ")),
+	})
+}
+
+// checkFlags adds the reasons if the LGBT content or advertising is denied
+func (p *Policy) checkFlags(decision *Decision, prefix string, lgbt, adv bool) {
+	if p.DenyLgbt && lgbt {
+		decision.Reasons = append(decision.Reasons, Reason{Rule: RuleLgbt, Message: prefix + "LGBT content is denied"})
+	}
+	if p.DenyAdv && adv {
+		decision.Reasons = append(decision.Reasons, Reason{Rule: RuleAdv, Message: prefix + "advertising is denied"})
+	}
+}
+
+// missingData returns the denial of the missing title or episode data
+func missingData(name string) *Decision {
+	return &Decision{Reasons: []Reason{{Rule: RuleData, Message: name + " data is missing"}}}
+}
+
+// subjectOfMovie returns the evaluated fields of the title
+func subjectOfMovie(movie *alloha.MovieData) *subject {
+	return &subject{
+		age:          movie.AgeRestrictions,
+		mpaa:         movie.RatingMpaa,
+		lgbt:         movie.Lgbt,
+		country:      movie.Country,
+		translations: movie.TranslationIframe,
+	}
+}
+
+// subjectOfSearchMovie returns the evaluated fields of the title of the search results
+func subjectOfSearchMovie(movie *alloha.MovieSearchData) *subject {
+	return &subject{
+		age:          movie.AgeRestrictions,
+		mpaa:         movie.RatingMpaa,
+		lgbt:         movie.Lgbt,
+		country:      movie.Country,
+		translations: movie.TranslationIframe,
+	}
+}
+
+// allWithAdv reports whether all translations have advertising
+func allWithAdv(translations map[string]alloha.TranslationIframe) bool {
+	for _, translation := range translations {
+		if !translation.Adv {
+			return false
+		}
+	}
+
+	return true
+}
+
+// sortedKeys returns the keys of the translations in the numeric order
+func sortedKeys(translations map[string]alloha.TranslationIframe) []string {
+	keys := make([]string, 0, len(translations))
+	for key := range translations {
+		keys = append(keys, key)
+	}
+
+	strutil.SortNumeric(keys)
+
+	return keys
+}
+
+// normalizeMpaa normalizes the MPAA rating, e.g. "PG-13" to "pg13"
+func normalizeMpaa(rating string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(rating), "-", ""))
+}
+
+// containsFold reports whether the list contains the value ignoring the case
+func containsFold(list []string, value string) bool {
+	for _, item := range list {
+		if strings.EqualFold(strings.TrimSpace(item), value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+//endregion
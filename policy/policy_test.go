@@ -0,0 +1,286 @@
+package policy
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/electromystyle/alloha-sdk-go/alloha"
+	"github.com/electromystyle/alloha-sdk-go/allohamock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testMovie returns a series with the translations with and without advertising
+func testMovie() *alloha.MovieData {
+	return &alloha.MovieData{
+		IDKp:            77044,
+		Name:            "Бригада",
+		Country:         "Россия, Украина",
+		AgeRestrictions: alloha.NullInt32{Int32: 18, Valid: true},
+		RatingMpaa:      "R",
+		TranslationIframe: map[string]alloha.TranslationIframe{
+			"66": {Name: "Оригинал"},
+			"10": {Name: "LostFilm", Adv: true},
+		},
+		Seasons: map[string]alloha.SeasonIframe{
+			"1": {Season: 1, Episodes: map[string]alloha.EpisodeIframe{
+				"1": {Episode: 1, Translation: map[string]alloha.TranslationIframe{"10": {Name: "LostFilm", Adv: true}}},
+				"2": {Episode: 2, Translation: map[string]alloha.TranslationIframe{
+					"10": {Name: "LostFilm", Adv: true},
+					"66": {Name: "Оригинал"},
+				}},
+			}},
+			"2": {Season: 2, Episodes: map[string]alloha.EpisodeIframe{
+				"1": {Episode: 1, Translation: map[string]alloha.TranslationIframe{"10": {Name: "LostFilm", Lgbt: true}}},
+			}},
+		},
+	}
+}
+
+func TestPolicy_EvaluateMovie(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  *Policy
+		reasons []Reason
+	}{
+		{name: "zero policy", policy: &Policy{}},
+		{name: "age", policy: &Policy{MaxAge: 16}, reasons: []Reason{
+			{Rule: RuleAge, Message: "age restriction 18+ exceeds 16+"},
+		}},
+		{name: "age allowed", policy: &Policy{MaxAge: 18}},
+		{name: "mpaa", policy: &Policy{DenyMpaa: []string{"pg-13", "r"}}, reasons: []Reason{
+			{Rule: RuleMpaa, Message: "MPAA rating R is denied"},
+		}},
+		{name: "denied country", policy: &Policy{DenyCountries: []string{"украина"}}, reasons: []Reason{
+			{Rule: RuleCountry, Message: "country Украина is denied"},
+		}},
+		{name: "allowed country", policy: &Policy{AllowCountries: []string{"Россия"}}},
+		{name: "not allowed country", policy: &Policy{AllowCountries: []string{"США"}}, reasons: []Reason{
+			{Rule: RuleCountry, Message: "none of the countries Россия, Украина is allowed"},
+		}},
+		{name: "title with ad-free translation", policy: &Policy{DenyAdv: true, DenyLgbt: true}},
+		{name: "translation mode", policy: &Policy{Mode: ModeTranslation, DenyAdv: true}},
+		{name: "translation mode without allowed translations", policy: &Policy{Mode: ModeTranslation, DenyAdv: true, DenyLgbt: true, MaxAge: 16}, reasons: []Reason{
+			{Rule: RuleAge, Message: "age restriction 18+ exceeds 16+"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision := tt.policy.EvaluateMovie(testMovie())
+
+			// Проверяем результат
+			assert.Equal(t, len(tt.reasons) <= 0, decision.Allowed)
+			assert.Equal(t, tt.reasons, decision.Reasons)
+		})
+	}
+}
+
+func TestPolicy_EvaluateTranslations(t *testing.T) {
+	movie := testMovie()
+	movie.TranslationIframe = map[string]alloha.TranslationIframe{
+		"10": {Name: "LostFilm", Adv: true},
+		"9":  {Name: "Кубик в кубе", Adv: true, Lgbt: true},
+	}
+	movie.Lgbt = true
+
+	// Проверяем результат
+	decision := (&Policy{DenyAdv: true}).EvaluateMovie(movie)
+	assert.Equal(t, []Reason{{Rule: RuleAdv, Message: "advertising is denied"}}, decision.Reasons)
+
+	decision = (&Policy{DenyLgbt: true}).EvaluateMovie(movie)
+	assert.Equal(t, []Reason{{Rule: RuleLgbt, Message: "LGBT content is denied"}}, decision.Reasons)
+
+	decision = (&Policy{Mode: ModeTranslation, DenyAdv: true, DenyLgbt: true}).EvaluateMovie(movie)
+	assert.Equal(t, []Reason{
+		{Rule: RuleLgbt, Message: "translation 9 (Кубик в кубе): LGBT content is denied"},
+		{Rule: RuleAdv, Message: "translation 9 (Кубик в кубе): advertising is denied"},
+		{Rule: RuleAdv, Message: "translation 10 (LostFilm): advertising is denied"},
+	}, decision.Reasons)
+	assert.EqualError(t, decision.Err(), "denied by the policy: lgbt: translation 9 (Кубик в кубе): LGBT content is denied; "+
+		"adv: translation 9 (Кубик в кубе): advertising is denied; adv: translation 10 (LostFilm): advertising is denied")
+
+	assert.True(t, (&Policy{Mode: ModeTranslation, DenyLgbt: true}).EvaluateMovie(movie).Allowed)
+	assert.False(t, (&Policy{DenyAdv: true}).EvaluateTranslation(alloha.TranslationIframe{Adv: true}).Allowed)
+	assert.NoError(t, (&Policy{DenyAdv: true}).EvaluateTranslation(alloha.TranslationIframe{Lgbt: true}).Err())
+}
+
+func TestPolicy_EvaluateSeries(t *testing.T) {
+	policy := &Policy{MaxAge: 16, DenyAdv: true}
+
+	// Проверяем результат
+	assert.True(t, policy.AllowSeries(&alloha.SeriesData{IDKp: 77044, Lgbt: true}))
+	assert.False(t, policy.AllowSeries(&alloha.SeriesData{IDKp: 77044, Adv: true}))
+
+	policy.DenyUnknownAge = true
+	assert.Equal(t, []Reason{{Rule: RuleAge, Message: "age restriction is unknown"}},
+		policy.EvaluateSeries(&alloha.SeriesData{IDKp: 77044}).Reasons)
+}
+
+func TestPolicy_FilterMovie(t *testing.T) {
+	policy := &Policy{Mode: ModeTranslation, DenyAdv: true, DenyLgbt: true}
+	movie := testMovie()
+
+	filtered, decision := policy.FilterMovie(movie)
+
+	// Проверяем результат
+	require.True(t, decision.Allowed)
+	assert.Equal(t, map[string]alloha.TranslationIframe{"66": {Name: "Оригинал"}}, filtered.TranslationIframe)
+	assert.Equal(t, map[string]alloha.SeasonIframe{
+		"1": {Season: 1, Episodes: map[string]alloha.EpisodeIframe{
+			"2": {Episode: 2, Translation: map[string]alloha.TranslationIframe{"66": {Name: "Оригинал"}}},
+		}},
+	}, filtered.Seasons)
+	assert.Len(t, movie.TranslationIframe, 2)
+	assert.Len(t, movie.Seasons, 2)
+
+	same, _ := (&Policy{DenyAdv: true}).FilterMovie(movie)
+	assert.Same(t, movie, same)
+
+	denied, decision := (&Policy{MaxAge: 12}).FilterMovie(movie)
+	assert.Nil(t, denied)
+	assert.False(t, decision.Allowed)
+}
+
+func TestPolicy_Filters(t *testing.T) {
+	policy := &Policy{MaxAge: 16, DenyAdv: true}
+
+	movies := policy.FilterMovies([]*alloha.MovieData{testMovie(), {IDKp: 326, AgeRestrictions: alloha.NullInt32{Int32: 16, Valid: true}}})
+	search := policy.FilterSearchMovies([]*alloha.MovieSearchData{{IDKp: 1, RatingMpaa: "r"}, {IDKp: 2, AgeRestrictions: alloha.NullInt32{Int32: 18, Valid: true}}})
+	series := policy.FilterSeriesList([]*alloha.SeriesData{{IDKp: 1, Adv: true}, {IDKp: 2}})
+
+	// Проверяем результат
+	require.Len(t, movies, 1)
+	assert.Equal(t, 326, movies[0].IDKp)
+	require.Len(t, search, 1)
+	assert.Equal(t, 1, search[0].IDKp)
+	require.Len(t, series, 1)
+	assert.Equal(t, 2, series[0].IDKp)
+
+	var ids []int
+	onSeries := policy.FilterSeriesFunc(func(series *alloha.SeriesData) error {
+		ids = append(ids, series.IDKp)
+		return nil
+	})
+	require.NoError(t, onSeries(&alloha.SeriesData{IDKp: 1, Adv: true}))
+	require.NoError(t, onSeries(&alloha.SeriesData{IDKp: 2}))
+	onSearch := policy.FilterSearchFunc(func(movie *alloha.MovieSearchData) error {
+		ids = append(ids, movie.IDKp)
+		return nil
+	})
+	require.NoError(t, onSearch(&alloha.MovieSearchData{IDKp: 3, AgeRestrictions: alloha.NullInt32{Int32: 18, Valid: true}}))
+	require.NoError(t, onSearch(&alloha.MovieSearchData{IDKp: 4}))
+	assert.Equal(t, []int{2, 4}, ids)
+}
+
+func TestPolicy_NilTitles(t *testing.T) {
+	policy := &Policy{}
+
+	// Проверяем, что пустые элементы списков пропускаются
+	assert.Empty(t, policy.FilterMovies([]*alloha.MovieData{nil}))
+	assert.Len(t, policy.FilterSearchMovies([]*alloha.MovieSearchData{nil, {IDKp: 1}}), 1)
+	assert.Len(t, policy.FilterSeriesList([]*alloha.SeriesData{{IDKp: 1}, nil}), 1)
+
+	// Проверяем, что пустые данные запрещаются
+	assert.Equal(t, []Reason{{Rule: RuleData, Message: "title data is missing"}}, policy.EvaluateMovie(nil).Reasons)
+	assert.False(t, policy.EvaluateSearchMovie(nil).Allowed)
+	assert.False(t, policy.AllowSeries(nil))
+
+	filtered, decision := policy.FilterMovie(nil)
+	assert.Nil(t, filtered)
+	assert.False(t, decision.Allowed)
+	require.NoError(t, policy.FilterSearchFunc(func(movie *alloha.MovieSearchData) error {
+		t.Error("nil title passed to the callback")
+		return nil
+	})(nil))
+}
+
+func TestFilteredAPI(t *testing.T) {
+	page := &alloha.ListOfLatestSeriesResponse{
+		Status:   "success",
+		Data:     []*alloha.SeriesData{{IDKp: 1, Adv: true}, {IDKp: 2}, nil},
+		NextPage: alloha.NullInt32{Int32: 2, Valid: true},
+	}
+	mock := &allohamock.Mock{
+		GetListOfLatestSeriesFunc: func(ctx context.Context, pageNum int) (*alloha.ListOfLatestSeriesResponse, error) {
+			return page, nil
+		},
+		FindByKPIdFunc: func(ctx context.Context, kpId int) (*alloha.FindOneResponse, error) {
+			return &alloha.FindOneResponse{Status: "success", Data: testMovie()}, nil
+		},
+		StreamListOfLatestSeriesFunc: func(ctx context.Context, pageNum int, fn func(series *alloha.SeriesData) error) (*alloha.ListPageInfo, error) {
+			for _, series := range page.Data {
+				if err := fn(series); err != nil {
+					return nil, err
+				}
+			}
+			return &alloha.ListPageInfo{Status: "success", NextPage: page.NextPage}, nil
+		},
+	}
+	api := NewFilteredAPI(mock, &Policy{MaxAge: 16, DenyAdv: true})
+
+	response, err := api.GetListOfLatestSeries(t.Context(), 1)
+
+	// Проверяем, что запрещенные эпизоды удалены со страницы, а пагинация сохранена
+	require.NoError(t, err)
+	require.Len(t, response.Data, 1)
+	assert.Equal(t, 2, response.Data[0].IDKp)
+	assert.Equal(t, int32(2), response.NextPage.Int32)
+	assert.Len(t, page.Data, 3)
+
+	var ids []int
+	_, err = api.StreamListOfLatestSeries(t.Context(), 1, func(series *alloha.SeriesData) error {
+		ids = append(ids, series.IDKp)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int{2}, ids)
+
+	// Проверяем, что запрещенный фильм удален из ответа
+	movie, err := api.FindByKPId(t.Context(), 77044)
+	require.NoError(t, err)
+	assert.Equal(t, "success", movie.Status)
+	assert.Nil(t, movie.Data)
+}
+
+func TestLoad(t *testing.T) {
+	config, err := Load(strings.NewReader(`{
+  "policies": [
+    {"name": "kids", "max_age": 12, "deny_unknown_age": true, "deny_mpaa": ["r", "nc17"]},
+    {"name": "ru", "mode": "translation", "deny_lgbt": true, "deny_adv": true, "allow_countries": ["Россия"]}
+  ]
+}`))
+	require.NoError(t, err)
+
+	// Проверяем результат
+	kids, found := config.Get("kids")
+	require.True(t, found)
+	assert.Equal(t, &Policy{Name: "kids", MaxAge: 12, DenyUnknownAge: true, DenyMpaa: []string{"r", "nc17"}}, kids)
+	ru, found := config.Get("ru")
+	require.True(t, found)
+	assert.Equal(t, ModeTranslation, ru.Mode)
+	_, found = config.Get("us")
+	assert.False(t, found)
+
+	path := filepath.Join(t.TempDir(), "policies.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"policies": [{"name": "us", "deny_countries": ["Россия"]}]}`), 0o600))
+	config, err = LoadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, []*Policy{{Name: "us", DenyCountries: []string{"Россия"}}}, config.Policies)
+
+	_, err = Load(strings.NewReader(`{"policies": [{"name": "kids", "max_ages": 12}]}`))
+	assert.ErrorContains(t, err, `unknown field "max_ages"`)
+	_, err = Load(strings.NewReader(`{"policies": [{"name": "kids"}, {"name": "kids"}]}`))
+	assert.EqualError(t, err, `invalid policy "kids": duplicate name`)
+	_, err = Load(strings.NewReader(`{"policies": [{"name": "kids", "mode": "episode"}]}`))
+	assert.Equal(t, &InvalidPolicyError{Name: "kids", Reason: `unknown mode "episode"`}, err)
+	_, err = Load(strings.NewReader(`{"policies": [{"max_age": 12}]}`))
+	assert.EqualError(t, err, `invalid policy "#1": name is empty`)
+
+	config, err = Load(strings.NewReader(""))
+	require.NoError(t, err)
+	assert.Empty(t, config.Policies)
+}
@@ -0,0 +1,605 @@
+// Package search provides an in-process full-text index over the titles for the instant search without the API
+// calls. The index matches the name, the original name and the alternative name by the words, their prefixes and
+// with typos, ranks the matches by the text relevance, the rating, the year and the popularity, and is kept up to
+// date by the sync engine. The queries are normalized and transliterated with alloha.QueryVariants.
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/electromystyle/alloha-sdk-go/alloha"
+	"github.com/electromystyle/alloha-sdk-go/internal/fsutil"
+	allohasync "github.com/electromystyle/alloha-sdk-go/sync"
+)
+
+// Default search settings
+const (
+	DefaultLimit = 20
+)
+
+// Fuzziness settings of the Query
+const (
+	// FuzzyAuto allows 1 typo in the words of 4 runes and longer and 2 typos in the words of 8 runes and longer
+	FuzzyAuto = 0
+	// FuzzyOff disables the fuzzy matching
+	FuzzyOff = -1
+)
+
+// indexVersion is the version of the serialized index
+const indexVersion = 1
+
+// Weights of the fields and the match kinds in the text relevance
+const (
+	weightName            = 1.0
+	weightOriginalName    = 0.9
+	weightAlternativeName = 0.8
+	weightExact           = 1.0
+	weightPrefix          = 0.85
+	weightFuzzy           = 0.7
+)
+
+// Weights of the score components
+const (
+	scoreText       = 0.7
+	scoreRating     = 0.15
+	scorePopularity = 0.1
+	scoreYear       = 0.05
+)
+
+var UnsupportedIndexVersionError = errors.New("index version is unsupported")
+
+// Document is a title in the index
+type Document struct {
+	// Kinopoisk ID of the title
+	IDKp            int    `json:"id_kp"`
+	Name            string `json:"name"`
+	OriginalName    string `json:"original_name,omitempty"`
+	AlternativeName string `json:"alternative_name,omitempty"`
+	Year            int    `json:"year,omitempty"`
+	Category        int    `json:"category"`
+	// Rating of the title from 0 to 10
+	Rating float64 `json:"rating"`
+	// Popularity of the title, any non-negative number, e.g. the number of the translations
+	Popularity float64 `json:"popularity"`
+}
+
+// Query is a search query, the zero values of the filters match all titles
+type Query struct {
+	// Text of the query, the empty text matches all titles passing the filters
+	Text       string
+	Categories []int
+	// Range of the years, inclusive
+	YearFrom int
+	YearTo   int
+	// Maximum number of the results, DefaultLimit if it is not positive
+	Limit int
+	// FuzzyAuto, FuzzyOff or the maximum number of the typos in a word
+	Fuzziness int
+}
+
+// Result is a search result
+type Result struct {
+	Document Document
+	// Score of the result from 0 to 1
+	Score float64
+}
+
+// Index is an in-memory search index. It is safe for concurrent use.
+type Index struct {
+	mu        sync.RWMutex
+	documents map[int]*Document
+	// postings are the documents containing the term with the best field weight
+	postings map[string]map[int]float64
+	// terms are the sorted terms of the postings
+	terms []string
+}
+
+// indexFile is the serialized index
+type indexFile struct {
+	Version   int         `json:"version"`
+	Documents []*Document `json:"documents"`
+}
+
+// termMatch is a term matching a query word
+type termMatch struct {
+	term   string
+	weight float64
+}
+
+//region - Constructor
+
+// NewIndex creates a new empty Index instance
+func NewIndex() *Index {
+	return &Index{
+		documents: make(map[int]*Document),
+		postings:  make(map[string]map[int]float64),
+	}
+}
+
+// Load reads the index serialized by Save
+func Load(r io.Reader) (*Index, error) {
+	file := &indexFile{}
+	if err := json.NewDecoder(r).Decode(file); err != nil {
+		return nil, err
+	}
+	if file.Version != indexVersion {
+		return nil, UnsupportedIndexVersionError
+	}
+
+	index := NewIndex()
+	for _, document := range file.Documents {
+		index.add(document)
+	}
+
+	return index, nil
+}
+
+// OpenFile reads the index from the file written by SaveFile, it returns an empty index if the file does not exist
+func OpenFile(path string) (*Index, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return NewIndex(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return Load(file)
+}
+
+//endregion
+
+//region - Public Methods
+
+// NewDocument converts the title data to the index document, the popularity is the number of the translations
+func NewDocument(movie *alloha.MovieData) *Document {
+	return &Document{
+		IDKp:            movie.IDKp,
+		Name:            movie.Name,
+		OriginalName:    movie.OriginalName,
+		AlternativeName: movie.AlternativeName,
+		Year:            movie.Year,
+		Category:        movie.Category,
+		Rating:          rating(movie.RatingKp, movie.RatingImdb),
+		Popularity:      float64(len(movie.TranslationIframe)),
+	}
+}
+
+// NewSearchDocument converts the search result to the index document, the popularity is the number of the translations
+func NewSearchDocument(movie *alloha.MovieSearchData) *Document {
+	return &Document{
+		IDKp:            movie.IDKp,
+		Name:            movie.Name,
+		OriginalName:    movie.OriginalName,
+		AlternativeName: movie.AlternativeName,
+		Year:            movie.Year,
+		Category:        movie.CategoryId,
+		Rating:          rating(movie.RatingKp, movie.RatingImdb),
+		Popularity:      float64(len(movie.TranslationIframe)),
+	}
+}
+
+// Add inserts or replaces the documents
+func (i *Index) Add(documents ...*Document) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	for _, document := range documents {
+		i.add(document)
+	}
+}
+
+// AddRecords inserts or replaces the titles of the sync store records, e.g. to build the index from the JSONLStore
+func (i *Index) AddRecords(records ...*allohasync.Record) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	for _, record := range records {
+		if record.Movie != nil {
+			i.add(NewDocument(record.Movie))
+		}
+	}
+}
+
+// Remove removes the document by the KP ID
+func (i *Index) Remove(idKp int) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.remove(idKp)
+}
+
+// Get returns the document by the KP ID or nil if there is no such document
+func (i *Index) Get(idKp int) *Document {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	document, found := i.documents[idKp]
+	if !found {
+		return nil
+	}
+
+	copied := *document
+	return &copied
+}
+
+// Len returns the number of the documents
+func (i *Index) Len() int {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	return len(i.documents)
+}
+
+// SyncListener returns the sync listener that applies the title changes to the index
+func (i *Index) SyncListener() allohasync.Listener {
+	return func(ctx context.Context, change *allohasync.TitleChange) {
+		if change.Kind == allohasync.ChangeRemoved || change.New == nil {
+			i.Remove(change.IDKp)
+			return
+		}
+
+		i.Add(NewDocument(change.New))
+	}
+}
+
+// Search returns the documents matching the query ordered by the score
+func (i *Index) Search(query Query) []*Result {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	var relevance map[int]float64
+	if variants := alloha.QueryVariants(query.Text); len(variants) > 0 {
+		// The transliterations of the query match the titles typed in the other script, e.g. "brigada" matches
+		// "Бригада", the best variant is taken for every title
+		relevance = make(map[int]float64)
+		for _, variant := range variants {
+			for idKp, weight := range i.match(tokenize(variant), query.Fuzziness) {
+				if weight > relevance[idKp] {
+					relevance[idKp] = weight
+				}
+			}
+		}
+	} else {
+		relevance = make(map[int]float64, len(i.documents))
+		for idKp := range i.documents {
+			relevance[idKp] = 1
+		}
+	}
+
+	documents := make([]*Document, 0, len(relevance))
+	maxYear := 0
+	for idKp := range relevance {
+		document := i.documents[idKp]
+		if !query.match(document) {
+			continue
+		}
+		documents = append(documents, document)
+		if document.Year > maxYear {
+			maxYear = document.Year
+		}
+	}
+
+	results := make([]*Result, 0, len(documents))
+	for _, document := range documents {
+		results = append(results, &Result{
+			Document: *document,
+			Score:    score(document, relevance[document.IDKp], maxYear),
+		})
+	}
+	sort.Slice(results, func(a, b int) bool {
+		if results[a].Score != results[b].Score {
+			return results[a].Score > results[b].Score
+		}
+		return results[a].Document.IDKp < results[b].Document.IDKp
+	})
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	if len(results) > limit {
+		results = results[:limit]
+	}
+
+	return results
+}
+
+// Save writes the index
+func (i *Index) Save(w io.Writer) error {
+	i.mu.RLock()
+	file := &indexFile{Version: indexVersion, Documents: make([]*Document, 0, len(i.documents))}
+	for _, document := range i.documents {
+		file.Documents = append(file.Documents, document)
+	}
+	i.mu.RUnlock()
+
+	sort.Slice(file.Documents, func(a, b int) bool {
+		return file.Documents[a].IDKp < file.Documents[b].IDKp
+	})
+
+	return json.NewEncoder(w).Encode(file)
+}
+
+// SaveFile writes the index to the file through a temporary one, so that a crash never leaves a partially written file
+func (i *Index) SaveFile(path string) error {
+	return fsutil.WriteFunc(path, i.Save)
+}
+
+//endregion
+
+//region - Private Methods
+
+// add inserts or replaces the document, the lock must be held
+func (i *Index) add(document *Document) {
+	i.remove(document.IDKp)
+
+	copied := *document
+	i.documents[document.IDKp] = &copied
+
+	for _, field := range []struct {
+		value  string
+		weight float64
+	}{
+		{value: document.Name, weight: weightName},
+		{value: document.OriginalName, weight: weightOriginalName},
+		{value: document.AlternativeName, weight: weightAlternativeName},
+	} {
+		for _, term := range tokenize(field.value) {
+			postings, found := i.postings[term]
+			if !found {
+				postings = make(map[int]float64)
+				i.postings[term] = postings
+				i.insertTerm(term)
+			}
+			if field.weight > postings[document.IDKp] {
+				postings[document.IDKp] = field.weight
+			}
+		}
+	}
+}
+
+// remove removes the document, the lock must be held
+func (i *Index) remove(idKp int) {
+	document, found := i.documents[idKp]
+	if !found {
+		return
+	}
+	delete(i.documents, idKp)
+
+	for _, value := range []string{document.Name, document.OriginalName, document.AlternativeName} {
+		for _, term := range tokenize(value) {
+			postings, found := i.postings[term]
+			if !found {
+				continue
+			}
+			delete(postings, idKp)
+			if len(postings) <= 0 {
+				delete(i.postings, term)
+				i.deleteTerm(term)
+			}
+		}
+	}
+}
+
+// insertTerm inserts the new term into the sorted terms, the lock must be held
+func (i *Index) insertTerm(term string) {
+	position := sort.SearchStrings(i.terms, term)
+	i.terms = append(i.terms, "")
+	copy(i.terms[position+1:], i.terms[position:])
+	i.terms[position] = term
+}
+
+// deleteTerm deletes the term from the sorted terms, the lock must be held
+func (i *Index) deleteTerm(term string) {
+	position := sort.SearchStrings(i.terms, term)
+	if position < len(i.terms) && i.terms[position] == term {
+		i.terms = append(i.terms[:position], i.terms[position+1:]...)
+	}
+}
+
+// match returns the text relevance of the documents matching all the words, the read lock must be held
+func (i *Index) match(words []string, fuzziness int) map[int]float64 {
+	var relevance map[int]float64
+	for _, word := range words {
+		wordRelevance := make(map[int]float64)
+		for _, m := range i.matchTerms(word, fuzziness) {
+			for idKp, fieldWeight := range i.postings[m.term] {
+				if weight := m.weight * fieldWeight; weight > wordRelevance[idKp] {
+					wordRelevance[idKp] = weight
+				}
+			}
+		}
+
+		if relevance == nil {
+			relevance = wordRelevance
+			continue
+		}
+		for idKp, weight := range relevance {
+			if wordWeight, found := wordRelevance[idKp]; found {
+				relevance[idKp] = weight + wordWeight
+			} else {
+				delete(relevance, idKp)
+			}
+		}
+	}
+
+	for idKp, weight := range relevance {
+		relevance[idKp] = weight / float64(len(words))
+	}
+
+	return relevance
+}
+
+// matchTerms returns the terms matching the word exactly, by the prefix or with typos
+func (i *Index) matchTerms(word string, fuzziness int) []termMatch {
+	var matches []termMatch
+
+	start := sort.SearchStrings(i.terms, word)
+	for _, term := range i.terms[start:] {
+		if !strings.HasPrefix(term, word) {
+			break
+		}
+		if term == word {
+			matches = append(matches, termMatch{term: term, weight: weightExact})
+		} else {
+			matches = append(matches, termMatch{term: term, weight: weightPrefix})
+		}
+	}
+
+	wordRunes := []rune(word)
+	maxTypos := maxTypos(len(wordRunes), fuzziness)
+	if maxTypos <= 0 {
+		return matches
+	}
+
+	for _, term := range i.terms {
+		if strings.HasPrefix(term, word) {
+			continue
+		}
+
+		// The typos are looked for in the whole term and in its prefix of the word length, so that the unfinished
+		// words with typos match too
+		termRunes := []rune(term)
+		typos := distance(wordRunes, termRunes, maxTypos)
+		if len(termRunes) > len(wordRunes) {
+			if prefixTypos := distance(wordRunes, termRunes[:len(wordRunes)], maxTypos); prefixTypos < typos {
+				typos = prefixTypos
+			}
+		}
+		if typos <= maxTypos {
+			matches = append(matches, termMatch{term: term, weight: weightFuzzy / float64(typos)})
+		}
+	}
+
+	return matches
+}
+
+// match reports whether the document passes the filters of the query
+func (q *Query) match(document *Document) bool {
+	if len(q.Categories) > 0 {
+		found := false
+		for _, category := range q.Categories {
+			if category == document.Category {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if q.YearFrom > 0 && document.Year < q.YearFrom {
+		return false
+	}
+	if q.YearTo > 0 && document.Year > q.YearTo {
+		return false
+	}
+
+	return true
+}
+
+// score combines the text relevance with the rating, the popularity and the year relative to the newest matching title
+func score(document *Document, relevance float64, maxYear int) float64 {
+	year := 0.0
+	if document.Year > 0 && maxYear > 0 {
+		year = math.Max(0, 1-float64(maxYear-document.Year)/50)
+	}
+	popularity := document.Popularity / (document.Popularity + 10)
+
+	return scoreText*relevance +
+		scoreRating*math.Min(document.Rating, 10)/10 +
+		scorePopularity*popularity +
+		scoreYear*year
+}
+
+// rating returns the Kinopoisk rating or the IMDb one if the title has no Kinopoisk rating
+func rating(kp, imdb float64) float64 {
+	if kp > 0 {
+		return kp
+	}
+
+	return imdb
+}
+
+// maxTypos returns the maximum number of the typos in the word of the length
+func maxTypos(length, fuzziness int) int {
+	switch {
+	case fuzziness > 0:
+		return fuzziness
+	case fuzziness < 0:
+		return 0
+	case length >= 8:
+		return 2
+	case length >= 4:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// distance returns the Levenshtein distance of the words or max+1 if it exceeds max
+func distance(a, b []rune, max int) int {
+	if diff := len(a) - len(b); diff > max || -diff > max {
+		return max + 1
+	}
+
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		rowMin := current[0]
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+			if current[j] < rowMin {
+				rowMin = current[j]
+			}
+		}
+		if rowMin > max {
+			return max + 1
+		}
+		previous, current = current, previous
+	}
+
+	if previous[len(b)] > max {
+		return max + 1
+	}
+
+	return previous[len(b)]
+}
+
+// minInt returns the minimum of the numbers
+func minInt(values ...int) int {
+	result := values[0]
+	for _, value := range values[1:] {
+		if value < result {
+			result = value
+		}
+	}
+
+	return result
+}
+
+// tokenize splits the text into the words folded with alloha.FoldName
+func tokenize(text string) []string {
+	return strings.Fields(alloha.FoldName(text))
+}
+
+//endregion
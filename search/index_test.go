@@ -0,0 +1,149 @@
+package search
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/electromystyle/alloha-sdk-go/alloha"
+	allohasync "github.com/electromystyle/alloha-sdk-go/sync"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestIndex returns an index with the test titles
+func newTestIndex() *Index {
+	index := NewIndex()
+	index.Add(
+		&Document{IDKp: 77044, Name: "Бригада", Year: 2002, Category: 2, Rating: 8.3, Popularity: 3},
+		&Document{IDKp: 1, Name: "Бригада 2: Наследник", Year: 2012, Category: 2, Rating: 4.1},
+		&Document{IDKp: 435, Name: "Зелёная миля", OriginalName: "The Green Mile", Year: 1999, Category: 1, Rating: 9.1, Popularity: 10},
+		&Document{IDKp: 326, Name: "Побег из Шоушенка", OriginalName: "The Shawshank Redemption", Year: 1994, Category: 1, Rating: 9.1, Popularity: 8},
+		&Document{IDKp: 464963, Name: "Игра престолов", OriginalName: "Game of Thrones", AlternativeName: "GoT", Year: 2011, Category: 2, Rating: 9.0, Popularity: 20},
+	)
+
+	return index
+}
+
+// resultIDs returns the KP IDs of the results
+func resultIDs(results []*Result) []int {
+	ids := make([]int, 0, len(results))
+	for _, result := range results {
+		ids = append(ids, result.Document.IDKp)
+	}
+
+	return ids
+}
+
+func TestIndex_Search(t *testing.T) {
+	index := newTestIndex()
+
+	tests := []struct {
+		name  string
+		query Query
+		want  []int
+	}{
+		{name: "exact", query: Query{Text: "бригада"}, want: []int{77044, 1}},
+		{name: "prefix", query: Query{Text: "бриг"}, want: []int{77044, 1}},
+		{name: "several words", query: Query{Text: "бригада наследник"}, want: []int{1}},
+		{name: "original name", query: Query{Text: "green mile"}, want: []int{435}},
+		{name: "alternative name", query: Query{Text: "got"}, want: []int{464963}},
+		{name: "yo folding", query: Query{Text: "зеленая"}, want: []int{435}},
+		{name: "transliteration", query: Query{Text: "Brigada"}, want: []int{77044, 1}},
+		{name: "transliterated words", query: Query{Text: "brigada naslednik"}, want: []int{1}},
+		{name: "year in query", query: Query{Text: "Бригада (2002)"}, want: []int{77044, 1}},
+		{name: "typo", query: Query{Text: "шоушенко"}, want: []int{326}},
+		{name: "unfinished word with typo", query: Query{Text: "престал"}, want: []int{464963}},
+		{name: "two typos", query: Query{Text: "redemptoin"}, want: []int{326}},
+		{name: "fuzzy off", query: Query{Text: "шоушенко", Fuzziness: FuzzyOff}, want: []int{}},
+		{name: "category", query: Query{Text: "бригада", Categories: []int{1}}, want: []int{}},
+		{name: "years", query: Query{YearFrom: 1995, YearTo: 2005}, want: []int{435, 77044}},
+		{name: "limit", query: Query{Limit: 2}, want: []int{464963, 435}},
+		{name: "no match", query: Query{Text: "матрица"}, want: []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Проверяем результат
+			assert.Equal(t, tt.want, resultIDs(index.Search(tt.query)))
+		})
+	}
+}
+
+func TestIndex_Ranking(t *testing.T) {
+	index := newTestIndex()
+
+	results := index.Search(Query{Text: "the"})
+
+	// Проверяем результат
+	require.Len(t, results, 2)
+	assert.Equal(t, []int{435, 326}, resultIDs(results))
+	assert.Greater(t, results[0].Score, results[1].Score)
+	assert.LessOrEqual(t, results[0].Score, 1.0)
+}
+
+func TestIndex_Update(t *testing.T) {
+	index := newTestIndex()
+
+	// Заменяем и удаляем документы
+	index.Add(&Document{IDKp: 1, Name: "Бумер", Year: 2003, Category: 1})
+	index.Remove(464963)
+	index.Remove(404)
+
+	// Проверяем результат
+	assert.Equal(t, 4, index.Len())
+	assert.Equal(t, []int{77044}, resultIDs(index.Search(Query{Text: "бригада"})))
+	assert.Equal(t, []int{1}, resultIDs(index.Search(Query{Text: "бумер"})))
+	assert.Empty(t, index.Search(Query{Text: "престолов"}))
+	assert.Nil(t, index.Get(464963))
+	assert.Equal(t, "Бумер", index.Get(1).Name)
+}
+
+func TestIndex_Persistence(t *testing.T) {
+	index := newTestIndex()
+
+	var buffer bytes.Buffer
+	require.NoError(t, index.Save(&buffer))
+
+	loaded, err := Load(&buffer)
+	require.NoError(t, err)
+
+	// Проверяем результат
+	assert.Equal(t, index.Search(Query{Text: "бриг"}), loaded.Search(Query{Text: "бриг"}))
+
+	path := filepath.Join(t.TempDir(), "index", "titles.json")
+	empty, err := OpenFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, 0, empty.Len())
+
+	require.NoError(t, index.SaveFile(path))
+	reopened, err := OpenFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, 5, reopened.Len())
+
+	_, err = Load(bytes.NewBufferString(`{"version":2,"documents":[]}`))
+	assert.Equal(t, UnsupportedIndexVersionError, err)
+}
+
+func TestIndex_SyncListener(t *testing.T) {
+	index := NewIndex()
+	index.AddRecords(&allohasync.Record{IDKp: 435, Movie: &alloha.MovieData{IDKp: 435, Name: "Зелёная миля", RatingImdb: 8.6}})
+	listener := index.SyncListener()
+
+	listener(t.Context(), &allohasync.TitleChange{
+		Kind: allohasync.ChangeAdded,
+		IDKp: 77044,
+		New: &alloha.MovieData{
+			IDKp:              77044,
+			Name:              "Бригада",
+			Category:          2,
+			RatingKp:          8.3,
+			TranslationIframe: map[string]alloha.TranslationIframe{"66": {Name: "Оригинал"}},
+		},
+	})
+	listener(t.Context(), &allohasync.TitleChange{Kind: allohasync.ChangeRemoved, IDKp: 435})
+
+	// Проверяем результат
+	assert.Equal(t, 1, index.Len())
+	assert.Equal(t, &Document{IDKp: 77044, Name: "Бригада", Category: 2, Rating: 8.3, Popularity: 1}, index.Get(77044))
+}
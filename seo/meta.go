@@ -0,0 +1,129 @@
+package seo
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/electromystyle/alloha-sdk-go/alloha"
+	"github.com/electromystyle/alloha-sdk-go/internal/strutil"
+)
+
+// Meta is a meta tag of the page head
+type Meta struct {
+	// Property is the attribute of the Open Graph tags, e.g. "og:title"
+	Property string
+	// Name is the attribute of the Twitter and the standard tags, e.g. "twitter:card"
+	Name    string
+	Content string
+}
+
+//region - Public Methods
+
+// TitleMeta returns the description, Open Graph and Twitter meta tags of the title page. The trailer is added as
+// the Twitter player.
+func (g *Generator) TitleMeta(movie *alloha.MovieData) []*Meta {
+	title := movie.Name
+	if movie.Year > 0 {
+		title += " (" + strconv.Itoa(movie.Year) + ")"
+	}
+
+	ogType := "video.movie"
+	if IsSeries(movie) {
+		ogType = "video.tv_show"
+	}
+
+	tags := g.commonMeta(ogType, title, movie.Description, g.urlOfTitle(movie), movie.Poster)
+	tags = appendMeta(tags, "video:release_date", "", premiereDate(movie))
+	if duration := parseDuration(movie.Time); duration > 0 && !IsSeries(movie) {
+		tags = appendMeta(tags, "video:duration", "", strconv.Itoa(int(duration.Seconds())))
+	}
+	for _, genre := range strutil.SplitList(movie.Genre) {
+		tags = appendMeta(tags, "video:tag", "", genre)
+	}
+
+	return g.appendTwitterMeta(tags, title, movie.Description, movie.Poster, movie.IframeTrailer)
+}
+
+// EpisodeMeta returns the description, Open Graph and Twitter meta tags of the episode page, it reports false if
+// there is no such episode
+func (g *Generator) EpisodeMeta(movie *alloha.MovieData, seasonNumber, episodeNumber int) ([]*Meta, bool) {
+	_, episode, found := findEpisode(movie, seasonNumber, episodeNumber)
+	if !found {
+		return nil, false
+	}
+
+	title := g.episodeTitle(movie, seasonNumber, episodeNumber)
+	tags := g.commonMeta("video.episode", title, movie.Description,
+		g.urlOfEpisode(movie, seasonNumber, episodeNumber), movie.Poster)
+	tags = appendMeta(tags, "video:release_date", "", formatDate(firstTranslationDate(episode.Translation)))
+	tags = appendMeta(tags, "video:series", "", g.urlOfTitle(movie))
+
+	return g.appendTwitterMeta(tags, title, movie.Description, movie.Poster, ""), true
+}
+
+//endregion
+
+//region - Private Methods
+
+// commonMeta returns the description and the basic Open Graph tags
+func (g *Generator) commonMeta(ogType, title, description, url, image string) []*Meta {
+	description = truncate(collapseSpaces(description), g.descriptionLength)
+
+	tags := appendMeta(nil, "", "description", description)
+	tags = appendMeta(tags, "og:type", "", ogType)
+	tags = appendMeta(tags, "og:title", "", title)
+	tags = appendMeta(tags, "og:description", "", description)
+	tags = appendMeta(tags, "og:url", "", url)
+	tags = appendMeta(tags, "og:image", "", image)
+	tags = appendMeta(tags, "og:site_name", "", g.siteName)
+
+	return appendMeta(tags, "og:locale", "", g.locale)
+}
+
+// appendTwitterMeta appends the Twitter card, the player card if there is a player
+func (g *Generator) appendTwitterMeta(tags []*Meta, title, description, image, player string) []*Meta {
+	card := "summary_large_image"
+	if len(player) > 0 {
+		card = "player"
+	}
+
+	tags = appendMeta(tags, "", "twitter:card", card)
+	tags = appendMeta(tags, "", "twitter:site", g.twitterSite)
+	tags = appendMeta(tags, "", "twitter:title", title)
+	tags = appendMeta(tags, "", "twitter:description", truncate(collapseSpaces(description), g.descriptionLength))
+	tags = appendMeta(tags, "", "twitter:image", image)
+	if len(player) > 0 {
+		tags = appendMeta(tags, "", "twitter:player", player)
+		tags = appendMeta(tags, "", "twitter:player:width", strconv.Itoa(g.playerWidth))
+		tags = appendMeta(tags, "", "twitter:player:height", strconv.Itoa(g.playerHeight))
+	}
+
+	return tags
+}
+
+// appendMeta appends the tag if the content is not empty
+func appendMeta(tags []*Meta, property, name, content string) []*Meta {
+	if len(content) <= 0 {
+		return tags
+	}
+
+	return append(tags, &Meta{Property: property, Name: name, Content: content})
+}
+
+// truncate cuts the text to the length in runes at the word boundary and appends the ellipsis
+func truncate(text string, length int) string {
+	if length <= 0 || utf8.RuneCountInString(text) <= length {
+		return text
+	}
+
+	runes := []rune(text)
+	cut := string(runes[:length-1])
+	if i := strings.LastIndex(cut, " "); i > 0 {
+		cut = cut[:i]
+	}
+
+	return strings.TrimRight(cut, " ,.;:") + "…"
+}
+
+//endregion
@@ -0,0 +1,471 @@
+package seo
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/electromystyle/alloha-sdk-go/alloha"
+	"github.com/electromystyle/alloha-sdk-go/internal/strutil"
+)
+
+// SchemaContext is the @context of the JSON-LD objects
+const SchemaContext = "https://schema.org"
+
+// Schema.org types of the objects
+const (
+	TypeMovie     = "Movie"
+	TypeTVSeries  = "TVSeries"
+	TypeTVSeason  = "TVSeason"
+	TypeTVEpisode = "TVEpisode"
+)
+
+// Object is a Schema.org creative work: a Movie, a TVSeries, a TVSeason or a TVEpisode
+type Object struct {
+	Context         string           `json:"@context,omitempty"`
+	Type            string           `json:"@type"`
+	Name            string           `json:"name,omitempty"`
+	AlternateName   string           `json:"alternateName,omitempty"`
+	URL             string           `json:"url,omitempty"`
+	Image           string           `json:"image,omitempty"`
+	Description     string           `json:"description,omitempty"`
+	DatePublished   string           `json:"datePublished,omitempty"`
+	Genres          []string         `json:"genre,omitempty"`
+	Countries       []*Country       `json:"countryOfOrigin,omitempty"`
+	Actors          []*Person        `json:"actor,omitempty"`
+	Directors       []*Person        `json:"director,omitempty"`
+	Producers       []*Person        `json:"producer,omitempty"`
+	ContentRating   string           `json:"contentRating,omitempty"`
+	Duration        string           `json:"duration,omitempty"`
+	AggregateRating *AggregateRating `json:"aggregateRating,omitempty"`
+	Trailer         *VideoObject     `json:"trailer,omitempty"`
+	SameAs          []string         `json:"sameAs,omitempty"`
+	// Series properties
+	NumberOfSeasons int       `json:"numberOfSeasons,omitempty"`
+	Seasons         []*Object `json:"containsSeason,omitempty"`
+	// Season properties
+	SeasonNumber     int       `json:"seasonNumber,omitempty"`
+	NumberOfEpisodes int       `json:"numberOfEpisodes,omitempty"`
+	Episodes         []*Object `json:"episode,omitempty"`
+	// Episode properties
+	EpisodeNumber int     `json:"episodeNumber,omitempty"`
+	PartOfSeason  *Object `json:"partOfSeason,omitempty"`
+	PartOfSeries  *Object `json:"partOfSeries,omitempty"`
+}
+
+// Person is a Schema.org person
+type Person struct {
+	Type string `json:"@type"`
+	Name string `json:"name"`
+}
+
+// Country is a Schema.org country
+type Country struct {
+	Type string `json:"@type"`
+	Name string `json:"name"`
+}
+
+// AggregateRating is a Schema.org rating on the 1-10 scale
+type AggregateRating struct {
+	Type        string  `json:"@type"`
+	RatingValue float64 `json:"ratingValue"`
+	BestRating  int     `json:"bestRating"`
+	WorstRating int     `json:"worstRating"`
+}
+
+// VideoObject is a Schema.org video, the trailer of a title
+type VideoObject struct {
+	Type         string `json:"@type"`
+	Name         string `json:"name"`
+	Description  string `json:"description,omitempty"`
+	EmbedURL     string `json:"embedUrl"`
+	ThumbnailURL string `json:"thumbnailUrl,omitempty"`
+}
+
+// mpaaRatings are the MPAA ratings by the API values
+var mpaaRatings = map[string]string{
+	"g":    "G",
+	"pg":   "PG",
+	"pg13": "PG-13",
+	"r":    "R",
+	"nc17": "NC-17",
+}
+
+//region - Public Methods
+
+// IsSeries reports whether the title is a series, i.e. it has seasons
+func IsSeries(movie *alloha.MovieData) bool {
+	return movie.SeasonsCount > 0 || len(movie.Seasons) > 0
+}
+
+// Title returns the Movie or the TVSeries object of the title with the seasons of the series
+func (g *Generator) Title(movie *alloha.MovieData) *Object {
+	object := &Object{
+		Context:         SchemaContext,
+		Type:            TypeMovie,
+		Name:            movie.Name,
+		AlternateName:   alternateName(movie),
+		URL:             g.urlOfTitle(movie),
+		Image:           movie.Poster,
+		Description:     collapseSpaces(movie.Description),
+		DatePublished:   premiereDate(movie),
+		Genres:          strutil.SplitList(movie.Genre),
+		Countries:       newCountries(movie.Country),
+		Actors:          newPersons(movie.Actors),
+		Directors:       newPersons(movie.Directors),
+		Producers:       newPersons(movie.Producers),
+		ContentRating:   contentRating(movie),
+		AggregateRating: newAggregateRating(movie),
+		Trailer:         newTrailer(movie),
+		SameAs:          sameAs(movie),
+	}
+
+	if !IsSeries(movie) {
+		object.Duration = isoDuration(parseDuration(movie.Time))
+		return object
+	}
+
+	object.Type = TypeTVSeries
+	for _, season := range sortedSeasons(movie) {
+		object.Seasons = append(object.Seasons, &Object{
+			Type:             TypeTVSeason,
+			URL:              g.urlOfSeason(movie, season.Season),
+			SeasonNumber:     season.Season,
+			NumberOfEpisodes: len(season.Episodes),
+		})
+	}
+	object.NumberOfSeasons = movie.SeasonsCount
+	if len(object.Seasons) > object.NumberOfSeasons {
+		object.NumberOfSeasons = len(object.Seasons)
+	}
+
+	return object
+}
+
+// Season returns the TVSeason object with the episodes, it reports false if there is no such season
+func (g *Generator) Season(movie *alloha.MovieData, number int) (*Object, bool) {
+	season, found := findSeason(movie, number)
+	if !found {
+		return nil, false
+	}
+
+	object := &Object{
+		Context:          SchemaContext,
+		Type:             TypeTVSeason,
+		URL:              g.urlOfSeason(movie, number),
+		Image:            movie.Poster,
+		SeasonNumber:     number,
+		NumberOfEpisodes: len(season.Episodes),
+		PartOfSeries:     g.seriesReference(movie),
+	}
+
+	var first time.Time
+	for _, episode := range sortedEpisodes(season) {
+		published := firstTranslationDate(episode.Translation)
+		if !published.IsZero() && (first.IsZero() || published.Before(first)) {
+			first = published
+		}
+
+		object.Episodes = append(object.Episodes, &Object{
+			Type:          TypeTVEpisode,
+			Name:          g.episodeTitle(movie, number, episode.Episode),
+			URL:           g.urlOfEpisode(movie, number, episode.Episode),
+			DatePublished: formatDate(published),
+			EpisodeNumber: episode.Episode,
+		})
+	}
+	object.DatePublished = formatDate(first)
+
+	return object, true
+}
+
+// Episode returns the TVEpisode object, it reports false if there is no such episode. The publication date is
+// the date of the first translation of the episode.
+func (g *Generator) Episode(movie *alloha.MovieData, seasonNumber, episodeNumber int) (*Object, bool) {
+	_, episode, found := findEpisode(movie, seasonNumber, episodeNumber)
+	if !found {
+		return nil, false
+	}
+
+	return &Object{
+		Context:       SchemaContext,
+		Type:          TypeTVEpisode,
+		Name:          g.episodeTitle(movie, seasonNumber, episodeNumber),
+		URL:           g.urlOfEpisode(movie, seasonNumber, episodeNumber),
+		Image:         movie.Poster,
+		DatePublished: formatDate(firstTranslationDate(episode.Translation)),
+		EpisodeNumber: episodeNumber,
+		PartOfSeason: &Object{
+			Type:         TypeTVSeason,
+			URL:          g.urlOfSeason(movie, seasonNumber),
+			SeasonNumber: seasonNumber,
+		},
+		PartOfSeries: g.seriesReference(movie),
+	}, true
+}
+
+//endregion
+
+//region - Private Methods
+
+// seriesReference returns the short TVSeries object for the partOfSeries property
+func (g *Generator) seriesReference(movie *alloha.MovieData) *Object {
+	return &Object{Type: TypeTVSeries, Name: movie.Name, URL: g.urlOfTitle(movie), SameAs: sameAs(movie)}
+}
+
+// urlOfTitle returns the URL of the title page or an empty string
+func (g *Generator) urlOfTitle(movie *alloha.MovieData) string {
+	if g.titleURL == nil {
+		return ""
+	}
+
+	return g.titleURL(movie)
+}
+
+// urlOfSeason returns the URL of the season page or an empty string
+func (g *Generator) urlOfSeason(movie *alloha.MovieData, season int) string {
+	if g.seasonURL == nil {
+		return ""
+	}
+
+	return g.seasonURL(movie, season)
+}
+
+// urlOfEpisode returns the URL of the episode page or an empty string
+func (g *Generator) urlOfEpisode(movie *alloha.MovieData, season, episode int) string {
+	if g.episodeURL == nil {
+		return ""
+	}
+
+	return g.episodeURL(movie, season, episode)
+}
+
+// alternateName returns the original name or the alternative name if it differs from the name
+func alternateName(movie *alloha.MovieData) string {
+	for _, name := range []string{movie.OriginalName, movie.AlternativeName} {
+		if len(name) > 0 && name != movie.Name {
+			return name
+		}
+	}
+
+	return ""
+}
+
+// premiereDate returns the world premiere date, the Russian premiere date or the year
+func premiereDate(movie *alloha.MovieData) string {
+	for _, value := range []string{movie.Premiere, movie.PremiereRu} {
+		if date := formatDate(parseDate(value)); len(date) > 0 {
+			return date
+		}
+	}
+	if movie.Year > 0 {
+		return strconv.Itoa(movie.Year)
+	}
+
+	return ""
+}
+
+// contentRating returns the MPAA rating or the age restriction, e.g. "PG-13" or "16+"
+func contentRating(movie *alloha.MovieData) string {
+	if rating, found := mpaaRatings[strings.ToLower(strings.ReplaceAll(movie.RatingMpaa, "-", ""))]; found {
+		return rating
+	}
+	if movie.AgeRestrictions.Valid {
+		return fmt.Sprintf("%d+", movie.AgeRestrictions.Int32)
+	}
+
+	return ""
+}
+
+// newAggregateRating returns the Kinopoisk rating or the IMDb rating if there is no Kinopoisk one
+func newAggregateRating(movie *alloha.MovieData) *AggregateRating {
+	value := movie.RatingKp
+	if value <= 0 {
+		value = movie.RatingImdb
+	}
+	if value <= 0 {
+		return nil
+	}
+
+	return &AggregateRating{Type: "AggregateRating", RatingValue: value, BestRating: 10, WorstRating: 1}
+}
+
+// newTrailer returns the trailer video with the iframe as the embed URL
+func newTrailer(movie *alloha.MovieData) *VideoObject {
+	if len(movie.IframeTrailer) <= 0 {
+		return nil
+	}
+
+	return &VideoObject{
+		Type:         "VideoObject",
+		Name:         movie.Name,
+		Description:  collapseSpaces(movie.Description),
+		EmbedURL:     movie.IframeTrailer,
+		ThumbnailURL: movie.Poster,
+	}
+}
+
+// sameAs returns the Kinopoisk and the IMDb pages of the title
+func sameAs(movie *alloha.MovieData) []string {
+	var result []string
+	if movie.IDKp > 0 {
+		result = append(result, fmt.Sprintf("https://www.kinopoisk.ru/film/%d/", movie.IDKp))
+	}
+	if len(movie.IDImdb) > 0 {
+		result = append(result, fmt.Sprintf("https://www.imdb.com/title/%s/", movie.IDImdb))
+	}
+
+	return result
+}
+
+// newPersons converts the comma-separated names to the persons
+func newPersons(value string) []*Person {
+	var result []*Person
+	for _, name := range strutil.SplitList(value) {
+		result = append(result, &Person{Type: "Person", Name: name})
+	}
+
+	return result
+}
+
+// newCountries converts the comma-separated names to the countries
+func newCountries(value string) []*Country {
+	var result []*Country
+	for _, name := range strutil.SplitList(value) {
+		result = append(result, &Country{Type: "Country", Name: name})
+	}
+
+	return result
+}
+
+// sortedSeasons returns the seasons ordered by the number
+func sortedSeasons(movie *alloha.MovieData) []alloha.SeasonIframe {
+	seasons := make([]alloha.SeasonIframe, 0, len(movie.Seasons))
+	for _, season := range movie.Seasons {
+		seasons = append(seasons, season)
+	}
+	sort.Slice(seasons, func(i, j int) bool {
+		return seasons[i].Season < seasons[j].Season
+	})
+
+	return seasons
+}
+
+// sortedEpisodes returns the episodes of the season ordered by the number
+func sortedEpisodes(season alloha.SeasonIframe) []alloha.EpisodeIframe {
+	episodes := make([]alloha.EpisodeIframe, 0, len(season.Episodes))
+	for _, episode := range season.Episodes {
+		episodes = append(episodes, episode)
+	}
+	sort.Slice(episodes, func(i, j int) bool {
+		return episodes[i].Episode < episodes[j].Episode
+	})
+
+	return episodes
+}
+
+// findSeason returns the season by the number
+func findSeason(movie *alloha.MovieData, number int) (alloha.SeasonIframe, bool) {
+	for _, season := range movie.Seasons {
+		if season.Season == number {
+			return season, true
+		}
+	}
+
+	return alloha.SeasonIframe{}, false
+}
+
+// findEpisode returns the season and the episode by the numbers
+func findEpisode(movie *alloha.MovieData, seasonNumber, episodeNumber int) (alloha.SeasonIframe, alloha.EpisodeIframe, bool) {
+	season, found := findSeason(movie, seasonNumber)
+	if !found {
+		return season, alloha.EpisodeIframe{}, false
+	}
+	for _, episode := range season.Episodes {
+		if episode.Episode == episodeNumber {
+			return season, episode, true
+		}
+	}
+
+	return season, alloha.EpisodeIframe{}, false
+}
+
+// firstTranslationDate returns the earliest date of the translations or the zero time
+func firstTranslationDate(translations map[string]alloha.TranslationIframe) time.Time {
+	var first time.Time
+	for _, translation := range translations {
+		date := parseDate(translation.Date)
+		if !date.IsZero() && (first.IsZero() || date.Before(first)) {
+			first = date
+		}
+	}
+
+	return first
+}
+
+// parseDate parses the dates of the API, e.g. "2023-10-07" or "2024-07-16 09:36:48", it returns the zero time for
+// the invalid dates
+func parseDate(value string) time.Time {
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02", time.RFC3339} {
+		if date, err := time.Parse(layout, strings.TrimSpace(value)); err == nil {
+			return date
+		}
+	}
+
+	return time.Time{}
+}
+
+// formatDate formats the date as the ISO 8601 date, the zero time is an empty string
+func formatDate(date time.Time) string {
+	if date.IsZero() {
+		return ""
+	}
+
+	return date.Format("2006-01-02")
+}
+
+// parseDuration parses the duration of the API, "01:51" or the number of minutes
+func parseDuration(value string) time.Duration {
+	value = strings.TrimSpace(value)
+	if parts := strings.Split(value, ":"); len(parts) == 2 {
+		hours, errHours := strconv.Atoi(parts[0])
+		minutes, errMinutes := strconv.Atoi(parts[1])
+		if errHours != nil || errMinutes != nil || hours < 0 || minutes < 0 {
+			return 0
+		}
+		return time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute
+	}
+
+	minutes, err := strconv.Atoi(strings.TrimSpace(strings.TrimSuffix(value, "мин.")))
+	if err != nil || minutes < 0 {
+		return 0
+	}
+
+	return time.Duration(minutes) * time.Minute
+}
+
+// isoDuration formats the duration as the ISO 8601 duration, e.g. "PT1H51M"
+func isoDuration(duration time.Duration) string {
+	if duration <= 0 {
+		return ""
+	}
+
+	hours, minutes := int(duration.Hours()), int(duration.Minutes())%60
+	switch {
+	case hours > 0 && minutes > 0:
+		return fmt.Sprintf("PT%dH%dM", hours, minutes)
+	case hours > 0:
+		return fmt.Sprintf("PT%dH", hours)
+	default:
+		return fmt.Sprintf("PT%dM", minutes)
+	}
+}
+
+// collapseSpaces replaces the line breaks and the repeated spaces with a single space
+func collapseSpaces(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
+
+//endregion
@@ -0,0 +1,162 @@
+// Package seo generates the SEO metadata of the title pages: the Schema.org JSON-LD objects (Movie, TVSeries,
+// TVSeason and TVEpisode) and the Open Graph and Twitter meta tags. The rendered metadata is escaped for the HTML
+// and can be embedded into the head of the page as is.
+package seo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"html/template"
+	"strings"
+
+	"github.com/electromystyle/alloha-sdk-go/alloha"
+)
+
+// Defaults of the Generator
+const (
+	// DefaultLocale is the default Open Graph locale
+	DefaultLocale = "ru_RU"
+	// DefaultDescriptionLength is the default maximum length of the meta descriptions in runes
+	DefaultDescriptionLength = 200
+	// DefaultPlayerWidth is the default width of the Twitter player
+	DefaultPlayerWidth = 1280
+	// DefaultPlayerHeight is the default height of the Twitter player
+	DefaultPlayerHeight = 720
+)
+
+// Generator generates the metadata of the title pages
+type Generator struct {
+	siteName          string
+	locale            string
+	twitterSite       string
+	descriptionLength int
+	playerWidth       int
+	playerHeight      int
+	titleURL          func(movie *alloha.MovieData) string
+	seasonURL         func(movie *alloha.MovieData, season int) string
+	episodeURL        func(movie *alloha.MovieData, season, episode int) string
+	episodeTitle      func(movie *alloha.MovieData, season, episode int) string
+}
+
+// Option configures the Generator
+type Option func(g *Generator)
+
+//region - Constructor
+
+// NewGenerator creates a new Generator instance
+func NewGenerator(options ...Option) *Generator {
+	g := &Generator{
+		locale:            DefaultLocale,
+		descriptionLength: DefaultDescriptionLength,
+		playerWidth:       DefaultPlayerWidth,
+		playerHeight:      DefaultPlayerHeight,
+		episodeTitle: func(movie *alloha.MovieData, season, episode int) string {
+			return fmt.Sprintf("%s S%02dE%02d", movie.Name, season, episode)
+		},
+	}
+	for _, option := range options {
+		option(g)
+	}
+
+	return g
+}
+
+// WithSiteName sets the name of the site for the og:site_name tag
+func WithSiteName(name string) Option {
+	return func(g *Generator) {
+		g.siteName = name
+	}
+}
+
+// WithLocale sets the Open Graph locale, e.g. "en_US"
+func WithLocale(locale string) Option {
+	return func(g *Generator) {
+		g.locale = locale
+	}
+}
+
+// WithTwitterSite sets the Twitter account of the site, e.g. "@example"
+func WithTwitterSite(account string) Option {
+	return func(g *Generator) {
+		g.twitterSite = account
+	}
+}
+
+// WithDescriptionLength sets the maximum length of the meta descriptions in runes, 0 disables truncating
+func WithDescriptionLength(length int) Option {
+	return func(g *Generator) {
+		g.descriptionLength = length
+	}
+}
+
+// WithPlayerSize sets the size of the Twitter player of the trailer
+func WithPlayerSize(width, height int) Option {
+	return func(g *Generator) {
+		g.playerWidth, g.playerHeight = width, height
+	}
+}
+
+// WithTitleURL sets the function building the canonical URL of the title page
+func WithTitleURL(titleURL func(movie *alloha.MovieData) string) Option {
+	return func(g *Generator) {
+		g.titleURL = titleURL
+	}
+}
+
+// WithSeasonURL sets the function building the canonical URL of the season page
+func WithSeasonURL(seasonURL func(movie *alloha.MovieData, season int) string) Option {
+	return func(g *Generator) {
+		g.seasonURL = seasonURL
+	}
+}
+
+// WithEpisodeURL sets the function building the canonical URL of the episode page
+func WithEpisodeURL(episodeURL func(movie *alloha.MovieData, season, episode int) string) Option {
+	return func(g *Generator) {
+		g.episodeURL = episodeURL
+	}
+}
+
+// WithEpisodeTitle sets the function building the title of the episode page, "<name> S01E02" by default
+func WithEpisodeTitle(episodeTitle func(movie *alloha.MovieData, season, episode int) string) Option {
+	return func(g *Generator) {
+		g.episodeTitle = episodeTitle
+	}
+}
+
+//endregion
+
+//region - Public Methods
+
+// JSONLD renders the object as the application/ld+json script element. The "<", ">" and "&" characters are escaped
+// as the JSON unicode escapes, so the strings can not close the script element.
+func JSONLD(object *Object) (template.HTML, error) {
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(true)
+	if err := encoder.Encode(object); err != nil {
+		return "", err
+	}
+
+	return template.HTML(`<script type="application/ld+json">` +
+		strings.TrimSuffix(buffer.String(), "\n") + `</script>`), nil
+}
+
+// RenderMeta renders the meta tags as the HTML elements, one per line, with the escaped attributes
+func RenderMeta(tags []*Meta) template.HTML {
+	var builder strings.Builder
+	for _, tag := range tags {
+		if len(tag.Property) > 0 {
+			builder.WriteString(`<meta property="` + html.EscapeString(tag.Property) + `"`)
+		} else {
+			builder.WriteString(`<meta name="` + html.EscapeString(tag.Name) + `"`)
+		}
+		builder.WriteString(` content="` + html.EscapeString(tag.Content) + "\">\n")
+	}
+
+	return template.HTML(builder.String())
+}
+
+//endregion
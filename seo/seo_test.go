@@ -0,0 +1,206 @@
+package seo
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/electromystyle/alloha-sdk-go/alloha"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testMovie returns a movie with all metadata
+func testMovie() *alloha.MovieData {
+	return &alloha.MovieData{
+		IDKp:            326,
+		IDImdb:          "tt0111161",
+		Name:            "Побег из Шоушенка",
+		OriginalName:    "The Shawshank Redemption",
+		Year:            1994,
+		Country:         "США",
+		Genre:           "драма",
+		Actors:          "Тим Роббинс, Морган Фриман",
+		Directors:       "Фрэнк Дарабонт",
+		Premiere:        "1994-09-10",
+		PremiereRu:      "2019-10-24",
+		AgeRestrictions: alloha.NullInt32{Int32: 16, Valid: true},
+		RatingMpaa:      "r",
+		RatingKp:        9.1,
+		RatingImdb:      9.3,
+		Time:            "02:22",
+		Poster:          "https://example.com/poster.jpg",
+		Description:     "Бухгалтер Энди\nДюфрейн обвинён в убийстве.",
+		IframeTrailer:   "https://example.com/trailer",
+	}
+}
+
+// testSeries returns a series with two seasons
+func testSeries() *alloha.MovieData {
+	return &alloha.MovieData{
+		IDKp:         77044,
+		Name:         "Бригада",
+		Year:         2002,
+		RatingImdb:   8.6,
+		SeasonsCount: 1,
+		Seasons: map[string]alloha.SeasonIframe{
+			"2": {Season: 2, Episodes: map[string]alloha.EpisodeIframe{}},
+			"1": {Season: 1, Episodes: map[string]alloha.EpisodeIframe{
+				"2": {Episode: 2, Translation: map[string]alloha.TranslationIframe{
+					"66": {Name: "Оригинал", Date: "2023-12-12 15:42:36"},
+					"10": {Name: "LostFilm", Date: "2023-12-06 02:24:06"},
+				}},
+				"1": {Episode: 1},
+			}},
+		},
+	}
+}
+
+// testGenerator creates a generator with the URLs of the pages
+func testGenerator(options ...Option) *Generator {
+	return NewGenerator(append([]Option{
+		WithTitleURL(func(movie *alloha.MovieData) string {
+			return fmt.Sprintf("https://example.com/titles/%d", movie.IDKp)
+		}),
+		WithSeasonURL(func(movie *alloha.MovieData, season int) string {
+			return fmt.Sprintf("https://example.com/titles/%d/%d", movie.IDKp, season)
+		}),
+		WithEpisodeURL(func(movie *alloha.MovieData, season, episode int) string {
+			return fmt.Sprintf("https://example.com/titles/%d/%d/%d", movie.IDKp, season, episode)
+		}),
+	}, options...)...)
+}
+
+func TestGenerator_Title(t *testing.T) {
+	object := testGenerator().Title(testMovie())
+
+	// Проверяем результат
+	assert.Equal(t, &Object{
+		Context:         SchemaContext,
+		Type:            TypeMovie,
+		Name:            "Побег из Шоушенка",
+		AlternateName:   "The Shawshank Redemption",
+		URL:             "https://example.com/titles/326",
+		Image:           "https://example.com/poster.jpg",
+		Description:     "Бухгалтер Энди Дюфрейн обвинён в убийстве.",
+		DatePublished:   "1994-09-10",
+		Genres:          []string{"драма"},
+		Countries:       []*Country{{Type: "Country", Name: "США"}},
+		Actors:          []*Person{{Type: "Person", Name: "Тим Роббинс"}, {Type: "Person", Name: "Морган Фриман"}},
+		Directors:       []*Person{{Type: "Person", Name: "Фрэнк Дарабонт"}},
+		ContentRating:   "R",
+		Duration:        "PT2H22M",
+		AggregateRating: &AggregateRating{Type: "AggregateRating", RatingValue: 9.1, BestRating: 10, WorstRating: 1},
+		Trailer: &VideoObject{
+			Type:         "VideoObject",
+			Name:         "Побег из Шоушенка",
+			Description:  "Бухгалтер Энди Дюфрейн обвинён в убийстве.",
+			EmbedURL:     "https://example.com/trailer",
+			ThumbnailURL: "https://example.com/poster.jpg",
+		},
+		SameAs: []string{"https://www.kinopoisk.ru/film/326/", "https://www.imdb.com/title/tt0111161/"},
+	}, object)
+
+	movie := testMovie()
+	movie.RatingMpaa, movie.Premiere, movie.PremiereRu, movie.Time = "", "", "invalid", "90"
+	object = NewGenerator().Title(movie)
+	assert.Equal(t, "16+", object.ContentRating)
+	assert.Equal(t, "1994", object.DatePublished)
+	assert.Equal(t, "PT1H30M", object.Duration)
+	assert.Empty(t, object.URL)
+}
+
+func TestGenerator_Series(t *testing.T) {
+	generator := testGenerator()
+
+	series := generator.Title(testSeries())
+
+	// Проверяем результат
+	assert.Equal(t, TypeTVSeries, series.Type)
+	assert.Equal(t, 2, series.NumberOfSeasons)
+	assert.Empty(t, series.Duration)
+	assert.Equal(t, 8.6, series.AggregateRating.RatingValue)
+	require.Len(t, series.Seasons, 2)
+	assert.Equal(t, &Object{Type: TypeTVSeason, URL: "https://example.com/titles/77044/1", SeasonNumber: 1, NumberOfEpisodes: 2}, series.Seasons[0])
+
+	season, found := generator.Season(testSeries(), 1)
+	require.True(t, found)
+	assert.Equal(t, "2023-12-06", season.DatePublished)
+	assert.Equal(t, "https://example.com/titles/77044", season.PartOfSeries.URL)
+	require.Len(t, season.Episodes, 2)
+	assert.Equal(t, &Object{Type: TypeTVEpisode, Name: "Бригада S01E01", URL: "https://example.com/titles/77044/1/1", EpisodeNumber: 1}, season.Episodes[0])
+	assert.Equal(t, 2, season.Episodes[1].EpisodeNumber)
+
+	episode, found := generator.Episode(testSeries(), 1, 2)
+	require.True(t, found)
+	assert.Equal(t, "2023-12-06", episode.DatePublished)
+	assert.Equal(t, &Object{Type: TypeTVSeason, URL: "https://example.com/titles/77044/1", SeasonNumber: 1}, episode.PartOfSeason)
+	assert.Equal(t, TypeTVSeries, episode.PartOfSeries.Type)
+
+	_, found = generator.Season(testSeries(), 3)
+	assert.False(t, found)
+	_, found = generator.Episode(testSeries(), 1, 3)
+	assert.False(t, found)
+}
+
+func TestJSONLD(t *testing.T) {
+	movie := testMovie()
+	movie.Name = `</script><script>alert("x")</script>`
+
+	script, err := JSONLD(NewGenerator().Title(movie))
+	require.NoError(t, err)
+
+	// Проверяем результат
+	assert.Contains(t, string(script), `"name":"\u003c/script\u003e\u003cscript\u003ealert(\"x\")\u003c/script\u003e"`)
+	assert.Contains(t, string(script), `<script type="application/ld+json">{"@context":"https://schema.org","@type":"Movie"`)
+	assert.Equal(t, 1, strings.Count(string(script), "</script>"))
+}
+
+func TestGenerator_TitleMeta(t *testing.T) {
+	movie := testMovie()
+	movie.Name = `Побег "из" <Шоушенка>`
+	generator := testGenerator(WithSiteName("Кино & сериалы"), WithTwitterSite("@example"), WithDescriptionLength(20))
+
+	// Проверяем результат
+	assert.Equal(t, ""+
+		`<meta name="description" content="Бухгалтер Энди…">`+"\n"+
+		`<meta property="og:type" content="video.movie">`+"\n"+
+		`<meta property="og:title" content="Побег &#34;из&#34; &lt;Шоушенка&gt; (1994)">`+"\n"+
+		`<meta property="og:description" content="Бухгалтер Энди…">`+"\n"+
+		`<meta property="og:url" content="https://example.com/titles/326">`+"\n"+
+		`<meta property="og:image" content="https://example.com/poster.jpg">`+"\n"+
+		`<meta property="og:site_name" content="Кино &amp; сериалы">`+"\n"+
+		`<meta property="og:locale" content="ru_RU">`+"\n"+
+		`<meta property="video:release_date" content="1994-09-10">`+"\n"+
+		`<meta property="video:duration" content="8520">`+"\n"+
+		`<meta property="video:tag" content="драма">`+"\n"+
+		`<meta name="twitter:card" content="player">`+"\n"+
+		`<meta name="twitter:site" content="@example">`+"\n"+
+		`<meta name="twitter:title" content="Побег &#34;из&#34; &lt;Шоушенка&gt; (1994)">`+"\n"+
+		`<meta name="twitter:description" content="Бухгалтер Энди…">`+"\n"+
+		`<meta name="twitter:image" content="https://example.com/poster.jpg">`+"\n"+
+		`<meta name="twitter:player" content="https://example.com/trailer">`+"\n"+
+		`<meta name="twitter:player:width" content="1280">`+"\n"+
+		`<meta name="twitter:player:height" content="720">`+"\n",
+		string(RenderMeta(generator.TitleMeta(movie))))
+}
+
+func TestGenerator_EpisodeMeta(t *testing.T) {
+	tags, found := testGenerator().EpisodeMeta(testSeries(), 1, 2)
+	require.True(t, found)
+
+	// Проверяем результат
+	assert.Equal(t, ""+
+		`<meta property="og:type" content="video.episode">`+"\n"+
+		`<meta property="og:title" content="Бригада S01E02">`+"\n"+
+		`<meta property="og:url" content="https://example.com/titles/77044/1/2">`+"\n"+
+		`<meta property="og:locale" content="ru_RU">`+"\n"+
+		`<meta property="video:release_date" content="2023-12-06">`+"\n"+
+		`<meta property="video:series" content="https://example.com/titles/77044">`+"\n"+
+		`<meta name="twitter:card" content="summary_large_image">`+"\n"+
+		`<meta name="twitter:title" content="Бригада S01E02">`+"\n",
+		string(RenderMeta(tags)))
+
+	_, found = testGenerator().EpisodeMeta(testSeries(), 2, 1)
+	assert.False(t, found)
+}
@@ -0,0 +1,255 @@
+package sync
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/electromystyle/alloha-sdk-go/internal/fsutil"
+)
+
+// JSONLStore is a Store that keeps the records in memory and writes them to a JSON Lines file, one record per line.
+// The checkpoint is kept in a separate file next to it with the ".checkpoint.json" suffix. Every checkpoint appends
+// only the records changed since the previous one, so the file always matches the checkpoint, and the later lines
+// of a title override the earlier ones. Flush, Close and the checkpoint of a completed sync compact the file to one
+// line per title ordered by the KP ID.
+type JSONLStore struct {
+	path string
+
+	mu      sync.Mutex
+	records map[int]*Record
+	changed map[int]bool
+	dirty   bool
+}
+
+// jsonlLine is a line of the JSON Lines file, the removed titles are written as the lines with the deleted flag
+type jsonlLine struct {
+	*Record
+	Deleted bool `json:"deleted,omitempty"`
+}
+
+var _ Store = (*JSONLStore)(nil)
+
+// OpenJSONLStore opens the JSON Lines store, the file is created on the first write if it does not exist
+func OpenJSONLStore(path string) (*JSONLStore, error) {
+	s := &JSONLStore{path: path, records: make(map[int]*Record), changed: make(map[int]bool)}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64<<10), 64<<20)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) <= 0 {
+			continue
+		}
+
+		record := jsonlLine{Record: &Record{}}
+		if err = json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("invalid record on line %d of %s: %w", line, path, err)
+		}
+		if record.Deleted {
+			delete(s.records, record.IDKp)
+		} else {
+			s.records[record.IDKp] = record.Record
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Get implements the Store interface
+func (s *JSONLStore) Get(ctx context.Context, idKp int) (*Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.records[idKp], nil
+}
+
+// Put implements the Store interface
+func (s *JSONLStore) Put(ctx context.Context, record *Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.records[record.IDKp] = record
+	s.changed[record.IDKp] = true
+	s.dirty = true
+
+	return nil
+}
+
+// Delete implements the Store interface
+func (s *JSONLStore) Delete(ctx context.Context, idKp int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.records, idKp)
+	s.changed[idKp] = true
+	s.dirty = true
+
+	return nil
+}
+
+// StaleIDs implements the Store interface
+func (s *JSONLStore) StaleIDs(ctx context.Context, before time.Time) ([]int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var ids []int
+	for idKp, record := range s.records {
+		if record.SyncedAt.Before(before) {
+			ids = append(ids, idKp)
+		}
+	}
+	sort.Ints(ids)
+
+	return ids, nil
+}
+
+// Records returns all records ordered by the KP ID
+func (s *JSONLStore) Records() []*Record {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.sortedRecords()
+}
+
+// Checkpoint implements the Store interface
+func (s *JSONLStore) Checkpoint(ctx context.Context) (*Checkpoint, error) {
+	data, err := os.ReadFile(s.checkpointPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	checkpoint := &Checkpoint{}
+	if err = json.Unmarshal(data, checkpoint); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint %s: %w", s.checkpointPath(), err)
+	}
+
+	return checkpoint, nil
+}
+
+// SaveCheckpoint implements the Store interface. The records changed since the previous checkpoint are appended to
+// the file first, the checkpoint of a completed sync compacts the file.
+func (s *JSONLStore) SaveCheckpoint(ctx context.Context, checkpoint *Checkpoint) error {
+	var err error
+	if checkpoint.Completed {
+		err = s.Flush()
+	} else {
+		err = s.appendChanged()
+	}
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return err
+	}
+
+	return fsutil.WriteFile(s.checkpointPath(), data)
+}
+
+// Flush compacts the file to the current records ordered by the KP ID
+func (s *JSONLStore) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.dirty {
+		return nil
+	}
+
+	var data []byte
+	for _, record := range s.sortedRecords() {
+		line, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+		data = append(append(data, line...), '\n')
+	}
+
+	if err := fsutil.WriteFile(s.path, data); err != nil {
+		return err
+	}
+	s.changed = make(map[int]bool)
+	s.dirty = false
+
+	return nil
+}
+
+// Close compacts the file, see Flush
+func (s *JSONLStore) Close() error {
+	return s.Flush()
+}
+
+// appendChanged appends the records changed since the previous checkpoint to the file
+func (s *JSONLStore) appendChanged() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.changed) <= 0 {
+		return nil
+	}
+
+	ids := make([]int, 0, len(s.changed))
+	for idKp := range s.changed {
+		ids = append(ids, idKp)
+	}
+	sort.Ints(ids)
+
+	var data []byte
+	for _, idKp := range ids {
+		line := jsonlLine{Record: s.records[idKp]}
+		if line.Record == nil {
+			line = jsonlLine{Record: &Record{IDKp: idKp}, Deleted: true}
+		}
+
+		lineData, err := json.Marshal(line)
+		if err != nil {
+			return err
+		}
+		data = append(append(data, lineData...), '\n')
+	}
+
+	if err := fsutil.AppendFile(s.path, data); err != nil {
+		return err
+	}
+	s.changed = make(map[int]bool)
+
+	return nil
+}
+
+// sortedRecords returns the records ordered by the KP ID, the caller must hold the lock
+func (s *JSONLStore) sortedRecords() []*Record {
+	records := make([]*Record, 0, len(s.records))
+	for _, record := range s.records {
+		records = append(records, record)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].IDKp < records[j].IDKp
+	})
+
+	return records
+}
+
+// checkpointPath returns the path of the checkpoint file
+func (s *JSONLStore) checkpointPath() string {
+	return s.path + ".checkpoint.json"
+}
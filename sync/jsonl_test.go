@@ -0,0 +1,104 @@
+package sync
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/electromystyle/alloha-sdk-go/alloha"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONLStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", "catalog.jsonl")
+	syncedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	store, err := OpenJSONLStore(path)
+	require.NoError(t, err)
+
+	// Записываем тестовые данные
+	require.NoError(t, store.Put(t.Context(), &Record{IDKp: 2, Hash: "b", SyncedAt: syncedAt, Movie: &alloha.MovieData{IDKp: 2}}))
+	require.NoError(t, store.Put(t.Context(), &Record{IDKp: 1, Hash: "a", SyncedAt: syncedAt.Add(time.Hour), Movie: &alloha.MovieData{IDKp: 1, Name: "Бригада"}}))
+	require.NoError(t, store.SaveCheckpoint(t.Context(), &Checkpoint{Mode: ModeFull, Page: 3}))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[0], `"id_kp":1`)
+
+	// Открываем хранилище повторно
+	store, err = OpenJSONLStore(path)
+	require.NoError(t, err)
+
+	record, err := store.Get(t.Context(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, "Бригада", record.Movie.Name)
+	assert.True(t, syncedAt.Add(time.Hour).Equal(record.SyncedAt))
+
+	record, err = store.Get(t.Context(), 3)
+	require.NoError(t, err)
+	assert.Nil(t, record)
+
+	ids, err := store.StaleIDs(t.Context(), syncedAt.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, []int{2}, ids)
+
+	checkpoint, err := store.Checkpoint(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, &Checkpoint{Mode: ModeFull, Page: 3}, checkpoint)
+
+	require.NoError(t, store.Delete(t.Context(), 2))
+	require.NoError(t, store.Flush())
+
+	store, err = OpenJSONLStore(path)
+	require.NoError(t, err)
+	assert.Len(t, store.Records(), 1)
+}
+
+func TestJSONLStore_AppendChanged(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "catalog.jsonl")
+
+	store, err := OpenJSONLStore(path)
+	require.NoError(t, err)
+
+	// Записываем две страницы синхронизации
+	require.NoError(t, store.Put(t.Context(), &Record{IDKp: 1, Hash: "a", Movie: &alloha.MovieData{IDKp: 1}}))
+	require.NoError(t, store.Put(t.Context(), &Record{IDKp: 2, Hash: "b", Movie: &alloha.MovieData{IDKp: 2}}))
+	require.NoError(t, store.SaveCheckpoint(t.Context(), &Checkpoint{Mode: ModeFull, Page: 1}))
+	require.NoError(t, store.Put(t.Context(), &Record{IDKp: 1, Hash: "c", Movie: &alloha.MovieData{IDKp: 1}}))
+	require.NoError(t, store.Delete(t.Context(), 2))
+	require.NoError(t, store.SaveCheckpoint(t.Context(), &Checkpoint{Mode: ModeFull, Page: 2}))
+
+	// Проверяем, что вторая контрольная точка дописала только измененные записи
+	assert.Len(t, fileLines(t, path), 4)
+
+	reopened, err := OpenJSONLStore(path)
+	require.NoError(t, err)
+	records := reopened.Records()
+	require.Len(t, records, 1)
+	assert.Equal(t, "c", records[0].Hash)
+
+	// Проверяем, что закрытие хранилища сжимает файл
+	require.NoError(t, store.Close())
+	assert.Len(t, fileLines(t, path), 1)
+}
+
+func TestOpenJSONLStore_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "catalog.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte("{\"id_kp\":1}\nnot json\n"), 0o644))
+
+	_, err := OpenJSONLStore(path)
+	assert.ErrorContains(t, err, "line 2")
+}
+
+// fileLines returns the non-empty lines of the file
+func fileLines(t *testing.T, path string) []string {
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	return strings.Split(strings.TrimSpace(string(data)), "\n")
+}
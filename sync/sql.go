@@ -0,0 +1,203 @@
+package sync
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Dialect defines the placeholder style and the upsert syntax of the SQL database
+type Dialect int
+
+const (
+	// DialectSQLite uses the "?" placeholders and the "ON CONFLICT" upsert of SQLite 3.24+
+	DialectSQLite Dialect = iota
+	// DialectPostgres uses the "$1" placeholders and the "ON CONFLICT" upsert
+	DialectPostgres
+	// DialectMySQL uses the "?" placeholders and the "ON DUPLICATE KEY UPDATE" upsert
+	DialectMySQL
+)
+
+// SQLStore is a Store in a database/sql database. The records are kept in the "<prefix>titles" table and the
+// checkpoint in the "<prefix>sync_checkpoint" table, the tables are created by Migrate.
+type SQLStore struct {
+	db          *sql.DB
+	dialect     Dialect
+	titles      string
+	checkpoints string
+}
+
+var _ Store = (*SQLStore)(nil)
+
+// NewSQLStore creates a new SQLStore instance with the dialect of the database and the table name prefix,
+// e.g. "alloha_"
+func NewSQLStore(db *sql.DB, dialect Dialect, tablePrefix string) *SQLStore {
+	return &SQLStore{
+		db:          db,
+		dialect:     dialect,
+		titles:      tablePrefix + "titles",
+		checkpoints: tablePrefix + "sync_checkpoint",
+	}
+}
+
+// Migrate creates the tables, if they do not exist. The title data is kept in the LONGTEXT column on MySQL, since
+// the MySQL TEXT holds at most 64KB, and in the TEXT column on SQLite and PostgreSQL.
+func (s *SQLStore) Migrate(ctx context.Context) error {
+	dataType := "TEXT"
+	if s.dialect == DialectMySQL {
+		dataType = "LONGTEXT"
+	}
+
+	statements := []string{
+		"CREATE TABLE IF NOT EXISTS " + s.titles +
+			" (id_kp INTEGER PRIMARY KEY, hash VARCHAR(64) NOT NULL, synced_at BIGINT NOT NULL, data " + dataType + " NOT NULL)",
+		"CREATE TABLE IF NOT EXISTS " + s.checkpoints + " (id INTEGER PRIMARY KEY, data " + dataType + " NOT NULL)",
+	}
+
+	for _, statement := range statements {
+		if _, err := s.db.ExecContext(ctx, statement); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Get implements the Store interface
+func (s *SQLStore) Get(ctx context.Context, idKp int) (*Record, error) {
+	var hash, data string
+	var syncedAt int64
+
+	row := s.db.QueryRowContext(ctx, s.query("SELECT hash, synced_at, data FROM "+s.titles+" WHERE id_kp = ?"), idKp)
+	if err := row.Scan(&hash, &syncedAt, &data); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	record := &Record{IDKp: idKp, Hash: hash, SyncedAt: time.Unix(0, syncedAt).UTC()}
+	if err := json.Unmarshal([]byte(data), &record.Movie); err != nil {
+		return nil, fmt.Errorf("invalid data of the title %d: %w", idKp, err)
+	}
+
+	return record, nil
+}
+
+// Put implements the Store interface. The record is inserted or updated in a single upsert statement of the dialect,
+// so that the concurrent writers do not conflict.
+func (s *SQLStore) Put(ctx context.Context, record *Record) error {
+	data, err := json.Marshal(record.Movie)
+	if err != nil {
+		return err
+	}
+
+	return s.upsert(ctx, s.titles, []string{"id_kp", "hash", "synced_at", "data"},
+		record.IDKp, record.Hash, record.SyncedAt.UnixNano(), string(data))
+}
+
+// Delete implements the Store interface
+func (s *SQLStore) Delete(ctx context.Context, idKp int) error {
+	_, err := s.db.ExecContext(ctx, s.query("DELETE FROM "+s.titles+" WHERE id_kp = ?"), idKp)
+
+	return err
+}
+
+// StaleIDs implements the Store interface
+func (s *SQLStore) StaleIDs(ctx context.Context, before time.Time) ([]int, error) {
+	rows, err := s.db.QueryContext(ctx,
+		s.query("SELECT id_kp FROM "+s.titles+" WHERE synced_at < ? ORDER BY id_kp"), before.UnixNano())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var idKp int
+		if err = rows.Scan(&idKp); err != nil {
+			return nil, err
+		}
+		ids = append(ids, idKp)
+	}
+
+	return ids, rows.Err()
+}
+
+// Checkpoint implements the Store interface
+func (s *SQLStore) Checkpoint(ctx context.Context) (*Checkpoint, error) {
+	var data string
+
+	row := s.db.QueryRowContext(ctx, s.query("SELECT data FROM "+s.checkpoints+" WHERE id = ?"), 1)
+	if err := row.Scan(&data); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	checkpoint := &Checkpoint{}
+	if err := json.Unmarshal([]byte(data), checkpoint); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint: %w", err)
+	}
+
+	return checkpoint, nil
+}
+
+// SaveCheckpoint implements the Store interface
+func (s *SQLStore) SaveCheckpoint(ctx context.Context, checkpoint *Checkpoint) error {
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return err
+	}
+
+	return s.upsert(ctx, s.checkpoints, []string{"id", "data"}, 1, string(data))
+}
+
+// upsert inserts the row or updates it, if a row with the same key exists. The first column is the primary key.
+func (s *SQLStore) upsert(ctx context.Context, table string, columns []string, args ...interface{}) error {
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ")
+	assignments := make([]string, 0, len(columns)-1)
+	for _, column := range columns[1:] {
+		if s.dialect == DialectMySQL {
+			assignments = append(assignments, column+" = VALUES("+column+")")
+		} else {
+			assignments = append(assignments, column+" = excluded."+column)
+		}
+	}
+
+	query := "INSERT INTO " + table + " (" + strings.Join(columns, ", ") + ") VALUES (" + placeholders + ")"
+	if s.dialect == DialectMySQL {
+		query += " ON DUPLICATE KEY UPDATE " + strings.Join(assignments, ", ")
+	} else {
+		query += " ON CONFLICT (" + columns[0] + ") DO UPDATE SET " + strings.Join(assignments, ", ")
+	}
+
+	_, err := s.db.ExecContext(ctx, s.query(query), args...)
+
+	return err
+}
+
+// query replaces the "?" placeholders with the placeholders of the dialect
+func (s *SQLStore) query(query string) string {
+	if s.dialect != DialectPostgres {
+		return query
+	}
+
+	var sb strings.Builder
+	n := 0
+	for _, r := range query {
+		if r != '?' {
+			sb.WriteRune(r)
+			continue
+		}
+		n++
+		sb.WriteString("$" + strconv.Itoa(n))
+	}
+
+	return sb.String()
+}
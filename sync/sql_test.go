@@ -0,0 +1,248 @@
+package sync
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/electromystyle/alloha-sdk-go/alloha"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDriver is a database/sql driver that understands exactly the statements of the SQLStore
+type fakeDriver struct {
+	mu          sync.Mutex
+	queries     []string
+	titles      map[int64][]driver.Value
+	checkpoints map[int64]string
+}
+
+// fakeConn is a connection of the fakeDriver
+type fakeConn struct {
+	d *fakeDriver
+}
+
+// fakeStmt is a prepared statement of the fakeDriver
+type fakeStmt struct {
+	d     *fakeDriver
+	query string
+}
+
+// fakeRows are the result rows of the fakeDriver
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+var dollarPlaceholder = regexp.MustCompile(`\$\d+`)
+
+var fakeDrivers = 0
+
+// openFakeDB registers a new fakeDriver and opens the database
+func openFakeDB(t *testing.T) (*sql.DB, *fakeDriver) {
+	d := &fakeDriver{titles: make(map[int64][]driver.Value), checkpoints: make(map[int64]string)}
+	fakeDrivers++
+	name := fmt.Sprintf("alloha-sync-fake-%d", fakeDrivers)
+	sql.Register(name, d)
+
+	db, err := sql.Open(name, "")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	return db, d
+}
+
+func (d *fakeDriver) Open(name string) (driver.Conn, error) {
+	return &fakeConn{d: d}, nil
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{d: c.d, query: query}, nil
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return c, nil
+}
+
+func (c *fakeConn) Commit() error {
+	return nil
+}
+
+func (c *fakeConn) Rollback() error {
+	return nil
+}
+
+func (s *fakeStmt) Close() error {
+	return nil
+}
+
+func (s *fakeStmt) NumInput() int {
+	return -1
+}
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	affected, _, err := s.d.execute(s.query, args)
+
+	return driver.RowsAffected(affected), err
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	_, rows, err := s.d.execute(s.query, args)
+
+	return rows, err
+}
+
+func (r *fakeRows) Columns() []string {
+	return r.columns
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) <= 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+
+	return nil
+}
+
+// execute executes the statement against the in-memory tables
+func (d *fakeDriver) execute(query string, args []driver.Value) (int64, *fakeRows, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.queries = append(d.queries, query)
+	query = dollarPlaceholder.ReplaceAllString(query, "?")
+
+	switch query {
+	case "SELECT hash, synced_at, data FROM alloha_titles WHERE id_kp = ?":
+		rows := &fakeRows{columns: []string{"hash", "synced_at", "data"}}
+		if row, ok := d.titles[args[0].(int64)]; ok {
+			rows.rows = append(rows.rows, row)
+		}
+		return 0, rows, nil
+	case "INSERT INTO alloha_titles (id_kp, hash, synced_at, data) VALUES (?, ?, ?, ?) " +
+		"ON CONFLICT (id_kp) DO UPDATE SET hash = excluded.hash, synced_at = excluded.synced_at, data = excluded.data",
+		"INSERT INTO alloha_titles (id_kp, hash, synced_at, data) VALUES (?, ?, ?, ?) " +
+			"ON DUPLICATE KEY UPDATE hash = VALUES(hash), synced_at = VALUES(synced_at), data = VALUES(data)":
+		d.titles[args[0].(int64)] = []driver.Value{args[1], args[2], args[3]}
+		return 1, nil, nil
+	case "DELETE FROM alloha_titles WHERE id_kp = ?":
+		delete(d.titles, args[0].(int64))
+		return 1, nil, nil
+	case "SELECT id_kp FROM alloha_titles WHERE synced_at < ? ORDER BY id_kp":
+		var ids []int64
+		for idKp, row := range d.titles {
+			if row[1].(int64) < args[0].(int64) {
+				ids = append(ids, idKp)
+			}
+		}
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+		rows := &fakeRows{columns: []string{"id_kp"}}
+		for _, idKp := range ids {
+			rows.rows = append(rows.rows, []driver.Value{idKp})
+		}
+		return 0, rows, nil
+	case "SELECT data FROM alloha_sync_checkpoint WHERE id = ?":
+		rows := &fakeRows{columns: []string{"data"}}
+		if data, ok := d.checkpoints[args[0].(int64)]; ok {
+			rows.rows = append(rows.rows, []driver.Value{data})
+		}
+		return 0, rows, nil
+	case "INSERT INTO alloha_sync_checkpoint (id, data) VALUES (?, ?) ON CONFLICT (id) DO UPDATE SET data = excluded.data",
+		"INSERT INTO alloha_sync_checkpoint (id, data) VALUES (?, ?) ON DUPLICATE KEY UPDATE data = VALUES(data)":
+		d.checkpoints[args[0].(int64)] = args[1].(string)
+		return 1, nil, nil
+	}
+
+	if strings.HasPrefix(query, "CREATE TABLE IF NOT EXISTS ") {
+		return 0, nil, nil
+	}
+
+	return 0, nil, fmt.Errorf("unexpected query: %s", query)
+}
+
+func TestSQLStore(t *testing.T) {
+	db, d := openFakeDB(t)
+	store := NewSQLStore(db, DialectPostgres, "alloha_")
+	syncedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	require.NoError(t, store.Migrate(t.Context()))
+
+	// Записываем тестовые данные
+	require.NoError(t, store.Put(t.Context(), &Record{IDKp: 1, Hash: "a", SyncedAt: syncedAt, Movie: &alloha.MovieData{IDKp: 1}}))
+	require.NoError(t, store.Put(t.Context(), &Record{IDKp: 1, Hash: "b", SyncedAt: syncedAt, Movie: &alloha.MovieData{IDKp: 1, Name: "Бригада"}}))
+	require.NoError(t, store.Put(t.Context(), &Record{IDKp: 2, Hash: "c", SyncedAt: syncedAt.Add(time.Hour), Movie: &alloha.MovieData{IDKp: 2}}))
+	assert.Len(t, d.titles, 2)
+
+	// Проверяем результат
+	record, err := store.Get(t.Context(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, "b", record.Hash)
+	assert.Equal(t, "Бригада", record.Movie.Name)
+	assert.Equal(t, syncedAt, record.SyncedAt)
+
+	record, err = store.Get(t.Context(), 3)
+	require.NoError(t, err)
+	assert.Nil(t, record)
+
+	ids, err := store.StaleIDs(t.Context(), syncedAt.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, []int{1}, ids)
+
+	require.NoError(t, store.Delete(t.Context(), 1))
+	assert.Len(t, d.titles, 1)
+
+	checkpoint, err := store.Checkpoint(t.Context())
+	require.NoError(t, err)
+	assert.Nil(t, checkpoint)
+
+	require.NoError(t, store.SaveCheckpoint(t.Context(), &Checkpoint{Mode: ModeFull, Page: 1}))
+	require.NoError(t, store.SaveCheckpoint(t.Context(), &Checkpoint{Mode: ModeFull, Page: 2}))
+
+	checkpoint, err = store.Checkpoint(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, 2, checkpoint.Page)
+
+	assert.Contains(t, d.queries, "INSERT INTO alloha_titles (id_kp, hash, synced_at, data) VALUES ($1, $2, $3, $4) "+
+		"ON CONFLICT (id_kp) DO UPDATE SET hash = excluded.hash, synced_at = excluded.synced_at, data = excluded.data")
+}
+
+func TestSQLStore_Syncer(t *testing.T) {
+	db, d := openFakeDB(t)
+	store := NewSQLStore(db, DialectMySQL, "alloha_")
+
+	c := &catalog{
+		pages:  [][]*alloha.SeriesData{{{IDKp: 1, Date: "2024-01-01"}}},
+		movies: map[int]*alloha.MovieData{1: {IDKp: 1, Name: "Бригада"}},
+	}
+
+	require.NoError(t, store.Migrate(t.Context()))
+	assert.Contains(t, d.queries, "CREATE TABLE IF NOT EXISTS alloha_titles "+
+		"(id_kp INTEGER PRIMARY KEY, hash VARCHAR(64) NOT NULL, synced_at BIGINT NOT NULL, data LONGTEXT NOT NULL)")
+
+	result, err := NewSyncer(newCatalogMock(c), store).Full(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Added)
+
+	record, err := store.Get(t.Context(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, "Бригада", record.Movie.Name)
+}
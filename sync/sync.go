@@ -0,0 +1,367 @@
+// Package sync mirrors the Alloha catalog into a local Store. The Syncer pages through the latest series list,
+// re-fetches the details of every title by its KP ID and upserts them, checkpointing the progress after every page,
+// so that an interrupted sync resumes from the last processed page.
+package sync
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/electromystyle/alloha-sdk-go/alloha"
+)
+
+// Sync modes stored in the Checkpoint
+const (
+	ModeFull        = "full"
+	ModeIncremental = "incremental"
+)
+
+// Record is a title stored in the Store
+type Record struct {
+	// Kinopoisk ID of the title
+	IDKp int `json:"id_kp"`
+	// Hash of the title data used to detect the changes
+	Hash string `json:"hash"`
+	// Start time of the last sync that saw the title
+	SyncedAt time.Time `json:"synced_at"`
+	// Title data
+	Movie *alloha.MovieData `json:"movie"`
+}
+
+// Checkpoint is the progress of the sync saved after every processed page
+type Checkpoint struct {
+	// Mode of the last sync, ModeFull or ModeIncremental
+	Mode string `json:"mode"`
+	// Last processed page of the last sync
+	Page int `json:"page"`
+	// Start time of the last sync
+	StartedAt time.Time `json:"started_at"`
+	// Whether the last sync has been completed
+	Completed bool `json:"completed"`
+	// Newest episode date seen by the last sync
+	RunDate string `json:"run_date"`
+	// Newest episode date seen by the completed syncs, the incremental sync stops at it
+	LastDate string `json:"last_date"`
+}
+
+// Kinds of the title changes
+const (
+	ChangeAdded   = "added"
+	ChangeUpdated = "updated"
+	ChangeRemoved = "removed"
+)
+
+// TitleChange is a change of the store made by the Syncer
+type TitleChange struct {
+	// Kind of the change, ChangeAdded, ChangeUpdated or ChangeRemoved
+	Kind string
+	// Kinopoisk ID of the title
+	IDKp int
+	// Title data before the change (nil for the added titles)
+	Old *alloha.MovieData
+	// Title data after the change (nil for the removed titles)
+	New *alloha.MovieData
+	// Changes of the title data (only for the updated titles)
+	Changes []alloha.Change
+}
+
+// Listener is notified about the changes of the store made by the Syncer. It is called synchronously after the store
+// has been changed, so it must not block for long.
+type Listener func(ctx context.Context, change *TitleChange)
+
+// Store provides an interface for the local catalog storage. Implementations must be safe for concurrent use.
+type Store interface {
+	// Get returns the record by the KP ID or nil if there is no such record
+	Get(ctx context.Context, idKp int) (*Record, error)
+	// Put inserts or replaces the record
+	Put(ctx context.Context, record *Record) error
+	// Delete removes the record by the KP ID
+	Delete(ctx context.Context, idKp int) error
+	// StaleIDs returns the KP IDs of the records synced before the specified time
+	StaleIDs(ctx context.Context, before time.Time) ([]int, error)
+	// Checkpoint returns the saved checkpoint or nil if there is none
+	Checkpoint(ctx context.Context) (*Checkpoint, error)
+	// SaveCheckpoint saves the checkpoint
+	SaveCheckpoint(ctx context.Context, checkpoint *Checkpoint) error
+}
+
+// Result is the summary of a sync run
+type Result struct {
+	// Mode of the sync, ModeFull or ModeIncremental
+	Mode string
+	// Whether the sync resumed an interrupted one
+	Resumed bool
+	// Number of the processed pages
+	Pages int
+	// Number of the added, updated, removed and unchanged titles
+	Added     int
+	Updated   int
+	Removed   int
+	Unchanged int
+	// Newest episode date seen by the completed syncs
+	LastDate string
+}
+
+// Syncer mirrors the Alloha catalog into the Store
+type Syncer struct {
+	api       alloha.API
+	store     Store
+	limiter   *alloha.RateLimiter
+	listeners []Listener
+	now       func() time.Time
+}
+
+// Option configures the Syncer
+type Option func(s *Syncer)
+
+//region - Constructor
+
+// NewSyncer creates a new Syncer instance
+func NewSyncer(api alloha.API, store Store, options ...Option) *Syncer {
+	s := &Syncer{
+		api:   api,
+		store: store,
+		now:   time.Now,
+	}
+
+	for _, option := range options {
+		option(s)
+	}
+
+	return s
+}
+
+// WithRateLimiter makes the Syncer wait for the rate limiter before every API call. The limiter can be shared with
+// other clients of the same API token.
+func WithRateLimiter(limiter *alloha.RateLimiter) Option {
+	return func(s *Syncer) {
+		s.limiter = limiter
+	}
+}
+
+// WithListener adds the listener of the title changes
+func WithListener(listener Listener) Option {
+	return func(s *Syncer) {
+		s.listeners = append(s.listeners, listener)
+	}
+}
+
+//endregion
+
+//region - Public Methods
+
+// Full syncs the whole catalog. The titles that have not been seen by the sync are removed from the store.
+func (s *Syncer) Full(ctx context.Context) (*Result, error) {
+	return s.run(ctx, ModeFull)
+}
+
+// Incremental syncs the titles with the episodes newer than the ones seen by the last completed sync
+func (s *Syncer) Incremental(ctx context.Context) (*Result, error) {
+	return s.run(ctx, ModeIncremental)
+}
+
+//endregion
+
+//region - Private Methods
+
+// run executes the sync in the specified mode, resuming the interrupted sync of the same mode
+func (s *Syncer) run(ctx context.Context, mode string) (*Result, error) {
+	checkpoint, err := s.store.Checkpoint(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{Mode: mode}
+	if checkpoint != nil && !checkpoint.Completed && checkpoint.Mode == mode {
+		result.Resumed = true
+	} else {
+		lastDate := ""
+		if checkpoint != nil {
+			lastDate = checkpoint.LastDate
+		}
+		checkpoint = &Checkpoint{Mode: mode, StartedAt: s.now(), LastDate: lastDate}
+	}
+
+	seen := make(map[int]bool)
+	for page := checkpoint.Page + 1; ; page++ {
+		if err = s.wait(ctx); err != nil {
+			return result, err
+		}
+
+		response, err := s.api.GetListOfLatestSeries(ctx, page)
+		if err != nil {
+			return result, err
+		}
+		if err = response.Err(); err != nil {
+			return result, err
+		}
+
+		reachedLastDate := false
+		for _, series := range response.Data {
+			if mode == ModeIncremental && len(checkpoint.LastDate) > 0 && series.Date < checkpoint.LastDate {
+				reachedLastDate = true
+				break
+			}
+			if series.Date > checkpoint.RunDate {
+				checkpoint.RunDate = series.Date
+			}
+			if seen[series.IDKp] {
+				continue
+			}
+			seen[series.IDKp] = true
+
+			if err = s.syncTitle(ctx, series.IDKp, checkpoint.StartedAt, result); err != nil {
+				return result, err
+			}
+		}
+
+		checkpoint.Page = page
+		if err = s.store.SaveCheckpoint(ctx, checkpoint); err != nil {
+			return result, err
+		}
+		result.Pages++
+
+		if reachedLastDate || !response.NextPage.Valid || len(response.Data) <= 0 {
+			break
+		}
+	}
+
+	if mode == ModeFull {
+		if err = s.removeStale(ctx, checkpoint.StartedAt, result); err != nil {
+			return result, err
+		}
+	}
+
+	checkpoint.Completed = true
+	if checkpoint.RunDate > checkpoint.LastDate {
+		checkpoint.LastDate = checkpoint.RunDate
+	}
+	if err = s.store.SaveCheckpoint(ctx, checkpoint); err != nil {
+		return result, err
+	}
+	result.LastDate = checkpoint.LastDate
+
+	return result, nil
+}
+
+// syncTitle fetches the details of the title and upserts it, the titles that are not found anymore or have no data
+// are removed
+func (s *Syncer) syncTitle(ctx context.Context, idKp int, syncedAt time.Time, result *Result) error {
+	if err := s.wait(ctx); err != nil {
+		return err
+	}
+
+	response, err := s.api.FindByKPId(ctx, idKp)
+	if err == nil {
+		err = response.Err()
+	}
+	if err == nil && response.Data == nil {
+		// The success response without the title data is treated as not found, like in the gateway and the CLI
+		err = &alloha.APIError{ErrorInfo: alloha.ErrorInfoNotFound}
+	}
+	if alloha.IsNotFound(err) {
+		return s.remove(ctx, idKp, result)
+	}
+	if err != nil {
+		return err
+	}
+
+	existing, err := s.store.Get(ctx, idKp)
+	if err != nil {
+		return err
+	}
+
+	hash, err := hashMovie(response.Data)
+	if err != nil {
+		return err
+	}
+
+	change := &TitleChange{IDKp: idKp, New: response.Data}
+	switch {
+	case existing == nil:
+		change.Kind = ChangeAdded
+		result.Added++
+	case existing.Hash != hash:
+		change.Kind, change.Old = ChangeUpdated, existing.Movie
+		change.Changes = alloha.Diff(existing.Movie, response.Data)
+		result.Updated++
+	default:
+		result.Unchanged++
+	}
+
+	if err = s.store.Put(ctx, &Record{IDKp: idKp, Hash: hash, SyncedAt: syncedAt, Movie: response.Data}); err != nil {
+		return err
+	}
+	if len(change.Kind) > 0 {
+		s.notify(ctx, change)
+	}
+
+	return nil
+}
+
+// remove removes the title from the store, if it is there
+func (s *Syncer) remove(ctx context.Context, idKp int, result *Result) error {
+	existing, err := s.store.Get(ctx, idKp)
+	if err != nil || existing == nil {
+		return err
+	}
+
+	if err = s.store.Delete(ctx, idKp); err != nil {
+		return err
+	}
+	result.Removed++
+	s.notify(ctx, &TitleChange{Kind: ChangeRemoved, IDKp: idKp, Old: existing.Movie})
+
+	return nil
+}
+
+// removeStale removes the titles that have not been seen by the full sync
+func (s *Syncer) removeStale(ctx context.Context, startedAt time.Time, result *Result) error {
+	ids, err := s.store.StaleIDs(ctx, startedAt)
+	if err != nil {
+		return err
+	}
+
+	for _, idKp := range ids {
+		if err = s.remove(ctx, idKp, result); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// notify passes the title change to the listeners
+func (s *Syncer) notify(ctx context.Context, change *TitleChange) {
+	for _, listener := range s.listeners {
+		listener(ctx, change)
+	}
+}
+
+// wait waits for the rate limiter, if any
+func (s *Syncer) wait(ctx context.Context) error {
+	if s.limiter == nil {
+		return ctx.Err()
+	}
+
+	_, err := s.limiter.Wait(ctx)
+
+	return err
+}
+
+// hashMovie returns the hash of the title data
+func hashMovie(movie *alloha.MovieData) (string, error) {
+	data, err := json.Marshal(movie)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:]), nil
+}
+
+//endregion
@@ -0,0 +1,259 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/electromystyle/alloha-sdk-go/alloha"
+	"github.com/electromystyle/alloha-sdk-go/allohamock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// catalog is a fake catalog served by the mock API
+type catalog struct {
+	pages  [][]*alloha.SeriesData
+	movies map[int]*alloha.MovieData
+	// failPage makes the request of the page fail
+	failPage int
+}
+
+// newCatalogMock creates a mock API serving the catalog
+func newCatalogMock(c *catalog) *allohamock.Mock {
+	return &allohamock.Mock{
+		GetListOfLatestSeriesFunc: func(ctx context.Context, pageNum int) (*alloha.ListOfLatestSeriesResponse, error) {
+			if pageNum == c.failPage {
+				return nil, errors.New("connection reset")
+			}
+
+			response := &alloha.ListOfLatestSeriesResponse{Status: "success"}
+			if pageNum <= len(c.pages) {
+				response.Data = c.pages[pageNum-1]
+			}
+			if pageNum < len(c.pages) {
+				response.NextPage = alloha.NullInt32{Int32: int32(pageNum + 1), Valid: true}
+			}
+
+			return response, nil
+		},
+		FindByKPIdFunc: func(ctx context.Context, kpId int) (*alloha.FindOneResponse, error) {
+			movie, ok := c.movies[kpId]
+			if !ok {
+				return &alloha.FindOneResponse{Status: "error", ErrorInfo: alloha.ErrorInfoNotFound}, nil
+			}
+
+			return &alloha.FindOneResponse{Status: "success", Data: movie}, nil
+		},
+	}
+}
+
+// newTestSyncer creates a Syncer with a JSONL store in a temporary directory
+func newTestSyncer(t *testing.T, c *catalog) (*Syncer, *JSONLStore) {
+	store, err := OpenJSONLStore(filepath.Join(t.TempDir(), "catalog.jsonl"))
+	require.NoError(t, err)
+
+	syncer := NewSyncer(newCatalogMock(c), store)
+	clock := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	syncer.now = func() time.Time {
+		clock = clock.Add(time.Hour)
+		return clock
+	}
+
+	return syncer, store
+}
+
+// recordIDs returns the KP IDs of the records in the store
+func recordIDs(store *JSONLStore) []int {
+	ids := make([]int, 0)
+	for _, record := range store.Records() {
+		ids = append(ids, record.IDKp)
+	}
+
+	return ids
+}
+
+func TestSyncer_Full(t *testing.T) {
+	// Создаем каталог из двух страниц, сериал 1 встречается дважды
+	c := &catalog{
+		pages: [][]*alloha.SeriesData{
+			{{IDKp: 1, Date: "2024-01-03"}, {IDKp: 2, Date: "2024-01-02"}},
+			{{IDKp: 1, Date: "2024-01-01"}, {IDKp: 3, Date: "2024-01-01"}},
+		},
+		movies: map[int]*alloha.MovieData{
+			1: {IDKp: 1, Name: "Бригада"},
+			2: {IDKp: 2, Name: "Бандитский Петербург"},
+			3: {IDKp: 3, Name: "Улицы разбитых фонарей"},
+		},
+	}
+	syncer, store := newTestSyncer(t, c)
+
+	result, err := syncer.Full(t.Context())
+	require.NoError(t, err)
+
+	// Проверяем результат
+	assert.Equal(t, &Result{Mode: ModeFull, Pages: 2, Added: 3, LastDate: "2024-01-03"}, result)
+	assert.Equal(t, []int{1, 2, 3}, recordIDs(store))
+
+	// Изменяем каталог: сериал 2 обновлен, сериал 3 удален
+	c.movies[2] = &alloha.MovieData{IDKp: 2, Name: "Бандитский Петербург", SeasonsCount: 10}
+	delete(c.movies, 3)
+	c.pages = c.pages[:1]
+
+	result, err = syncer.Full(t.Context())
+	require.NoError(t, err)
+
+	assert.Equal(t, &Result{Mode: ModeFull, Pages: 1, Updated: 1, Removed: 1, Unchanged: 1, LastDate: "2024-01-03"}, result)
+	assert.Equal(t, []int{1, 2}, recordIDs(store))
+
+	record, err := store.Get(t.Context(), 2)
+	require.NoError(t, err)
+	assert.Equal(t, 10, record.Movie.SeasonsCount)
+}
+
+func TestSyncer_EmptyData(t *testing.T) {
+	c := &catalog{
+		pages: [][]*alloha.SeriesData{{{IDKp: 1}, {IDKp: 2}}},
+		movies: map[int]*alloha.MovieData{
+			1: {IDKp: 1, Name: "Бригада"},
+			2: {IDKp: 2, Name: "Бандитский Петербург"},
+		},
+	}
+	syncer, store := newTestSyncer(t, c)
+
+	_, err := syncer.Full(t.Context())
+	require.NoError(t, err)
+
+	// API возвращает успешный ответ без данных сериала 2
+	c.movies[2] = nil
+
+	result, err := syncer.Full(t.Context())
+	require.NoError(t, err)
+
+	// Проверяем, что сериал без данных удален, а не сохранен пустой записью
+	assert.Equal(t, &Result{Mode: ModeFull, Pages: 1, Removed: 1, Unchanged: 1}, result)
+	assert.Equal(t, []int{1}, recordIDs(store))
+}
+
+func TestSyncer_Resume(t *testing.T) {
+	// Создаем каталог, вторая страница которого недоступна
+	c := &catalog{
+		pages: [][]*alloha.SeriesData{
+			{{IDKp: 1, Date: "2024-01-03"}},
+			{{IDKp: 2, Date: "2024-01-02"}},
+			{{IDKp: 3, Date: "2024-01-01"}},
+		},
+		movies: map[int]*alloha.MovieData{
+			1: {IDKp: 1, Name: "Бригада"},
+			2: {IDKp: 2, Name: "Бандитский Петербург"},
+			3: {IDKp: 3, Name: "Улицы разбитых фонарей"},
+		},
+		failPage: 2,
+	}
+	syncer, store := newTestSyncer(t, c)
+
+	result, err := syncer.Full(t.Context())
+	assert.Error(t, err)
+	assert.Equal(t, 1, result.Pages)
+
+	checkpoint, err := store.Checkpoint(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, 1, checkpoint.Page)
+	assert.False(t, checkpoint.Completed)
+
+	// Продолжаем синхронизацию со второй страницы
+	c.failPage = 0
+	mock := syncer.api.(*allohamock.Mock)
+	mock.Reset()
+
+	result, err = syncer.Full(t.Context())
+	require.NoError(t, err)
+
+	// Проверяем результат
+	assert.True(t, result.Resumed)
+	assert.Equal(t, 2, result.Pages)
+	assert.Equal(t, 2, result.Added)
+	assert.Equal(t, 0, result.Removed)
+	assert.Equal(t, []int{1, 2, 3}, recordIDs(store))
+
+	pages := mock.CallsTo("GetListOfLatestSeries")
+	require.Len(t, pages, 2)
+	assert.Equal(t, []interface{}{2}, pages[0].Args)
+}
+
+func TestSyncer_Incremental(t *testing.T) {
+	c := &catalog{
+		pages: [][]*alloha.SeriesData{
+			{{IDKp: 1, Date: "2024-01-02"}, {IDKp: 2, Date: "2024-01-01"}},
+		},
+		movies: map[int]*alloha.MovieData{
+			1: {IDKp: 1, Name: "Бригада"},
+			2: {IDKp: 2, Name: "Бандитский Петербург"},
+			3: {IDKp: 3, Name: "Улицы разбитых фонарей"},
+		},
+	}
+	syncer, store := newTestSyncer(t, c)
+
+	_, err := syncer.Full(t.Context())
+	require.NoError(t, err)
+
+	// Добавляем новые серии перед уже синхронизированными
+	c.pages = [][]*alloha.SeriesData{
+		{{IDKp: 3, Date: "2024-01-04"}, {IDKp: 1, Date: "2024-01-03"}},
+		{{IDKp: 1, Date: "2024-01-02"}, {IDKp: 2, Date: "2024-01-01"}},
+	}
+
+	result, err := syncer.Incremental(t.Context())
+	require.NoError(t, err)
+
+	// Проверяем результат
+	assert.Equal(t, &Result{Mode: ModeIncremental, Pages: 2, Added: 1, Unchanged: 1, LastDate: "2024-01-04"}, result)
+	assert.Equal(t, []int{1, 2, 3}, recordIDs(store))
+}
+
+func TestSyncer_APIError(t *testing.T) {
+	syncer, _ := newTestSyncer(t, &catalog{})
+	syncer.api.(*allohamock.Mock).GetListOfLatestSeriesFunc = func(ctx context.Context, pageNum int) (*alloha.ListOfLatestSeriesResponse, error) {
+		return &alloha.ListOfLatestSeriesResponse{Status: "error", ErrorInfo: "not valid token"}, nil
+	}
+
+	_, err := syncer.Full(t.Context())
+
+	var apiError *alloha.APIError
+	assert.ErrorAs(t, err, &apiError)
+}
+
+func TestSyncer_Listener(t *testing.T) {
+	c := &catalog{
+		pages: [][]*alloha.SeriesData{{{IDKp: 1, Date: "2024-01-02"}, {IDKp: 2, Date: "2024-01-01"}}},
+		movies: map[int]*alloha.MovieData{
+			1: {IDKp: 1, Name: "Бригада"},
+			2: {IDKp: 2, Name: "Бандитский Петербург"},
+		},
+	}
+	syncer, _ := newTestSyncer(t, c)
+
+	var changes []string
+	var diff []alloha.Change
+	syncer.listeners = append(syncer.listeners, func(ctx context.Context, change *TitleChange) {
+		changes = append(changes, fmt.Sprintf("%s:%d", change.Kind, change.IDKp))
+		diff = append(diff, change.Changes...)
+	})
+
+	_, err := syncer.Full(t.Context())
+	require.NoError(t, err)
+
+	// Изменяем сериал 1 и удаляем сериал 2
+	c.movies[1] = &alloha.MovieData{IDKp: 1, Name: "Бригада", SeasonsCount: 2}
+	delete(c.movies, 2)
+
+	_, err = syncer.Full(t.Context())
+	require.NoError(t, err)
+
+	// Проверяем результат
+	assert.Equal(t, []string{"added:1", "added:2", "updated:1", "removed:2"}, changes)
+	assert.Equal(t, []alloha.Change{{Kind: alloha.ChangeFieldChanged, Path: "seasons_count", Old: 0, New: 2}}, diff)
+}
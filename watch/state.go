@@ -0,0 +1,119 @@
+package watch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/electromystyle/alloha-sdk-go/internal/fsutil"
+)
+
+// Key identifies an episode in a translation, the Watcher emits a single event per key
+type Key struct {
+	IDKp        int `json:"id_kp"`
+	Season      int `json:"season"`
+	Episode     int `json:"episode"`
+	Translation int `json:"translation"`
+}
+
+// Entry is a key seen by the Watcher
+type Entry struct {
+	Key
+	// Last seen video quality
+	Quality string `json:"quality"`
+	// Last time the key has been seen
+	SeenAt time.Time `json:"seen_at"`
+}
+
+// State is the seen-state of the Watcher
+type State struct {
+	Entries []Entry `json:"entries"`
+}
+
+// StateStore provides an interface for the persistence of the seen-state, so that the restarted Watcher does not
+// emit the old events again
+type StateStore interface {
+	// Load returns the saved state or nil if there is none
+	Load(ctx context.Context) (*State, error)
+	// Save saves the state
+	Save(ctx context.Context, state *State) error
+}
+
+// MemoryState is an in-memory StateStore
+type MemoryState struct {
+	mu    sync.Mutex
+	state *State
+}
+
+var _ StateStore = (*MemoryState)(nil)
+
+// FileState is a StateStore in a JSON file
+type FileState struct {
+	path string
+}
+
+var _ StateStore = (*FileState)(nil)
+
+// NewMemoryState creates a new MemoryState instance
+func NewMemoryState() *MemoryState {
+	return &MemoryState{}
+}
+
+// Load implements the StateStore interface
+func (s *MemoryState) Load(ctx context.Context) (*State, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.state == nil {
+		return nil, nil
+	}
+
+	return &State{Entries: append([]Entry(nil), s.state.Entries...)}, nil
+}
+
+// Save implements the StateStore interface
+func (s *MemoryState) Save(ctx context.Context, state *State) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.state = &State{Entries: append([]Entry(nil), state.Entries...)}
+
+	return nil
+}
+
+// NewFileState creates a new FileState instance, the file is created on the first save if it does not exist
+func NewFileState(path string) *FileState {
+	return &FileState{path: path}
+}
+
+// Load implements the StateStore interface
+func (s *FileState) Load(ctx context.Context) (*State, error) {
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	state := &State{}
+	if err = json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("invalid watcher state %s: %w", s.path, err)
+	}
+
+	return state, nil
+}
+
+// Save implements the StateStore interface. The file is written through a temporary one, so that a crash never
+// leaves a partially written state.
+func (s *FileState) Save(ctx context.Context, state *State) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	return fsutil.WriteFile(s.path, data)
+}
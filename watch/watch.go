@@ -0,0 +1,330 @@
+// Package watch notifies about the new episodes of the series. The Watcher polls the latest series list on an interval,
+// deduplicates the episodes by the KP ID, the season, the episode and the translation and emits typed events.
+package watch
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/electromystyle/alloha-sdk-go/alloha"
+)
+
+// EventKind is the kind of the Watcher event
+type EventKind string
+
+// Kinds of the Watcher events
+const (
+	// EventNewEpisode is emitted for an episode that has not been seen in any translation
+	EventNewEpisode EventKind = "new_episode"
+	// EventNewTranslation is emitted for a seen episode in a new translation
+	EventNewTranslation EventKind = "new_translation"
+	// EventQualityUpgrade is emitted when the video quality of a seen episode in a translation gets better
+	EventQualityUpgrade EventKind = "quality_upgrade"
+)
+
+// Default Watcher settings
+const (
+	DefaultInterval  = 5 * time.Minute
+	DefaultPages     = 1
+	DefaultRetention = 30 * 24 * time.Hour
+)
+
+// Event is a change of the latest series list
+type Event struct {
+	Kind EventKind `json:"kind"`
+	// Latest episode data of the series
+	Series *alloha.SeriesData `json:"series"`
+	// Video quality before the upgrade (only for the EventQualityUpgrade kind)
+	PreviousQuality string `json:"previous_quality,omitempty"`
+	// Time of the poll that detected the change
+	DetectedAt time.Time `json:"detected_at"`
+}
+
+// Handler handles the Watcher events. The event is emitted again after a restart, if the handler returns an error.
+type Handler func(ctx context.Context, event *Event) error
+
+// Watcher polls the latest series list and emits the events about the new episodes, translations and quality
+// upgrades. The first poll without a saved state records the current episodes without emitting any events.
+type Watcher struct {
+	api       alloha.API
+	state     StateStore
+	interval  time.Duration
+	pages     int
+	retention time.Duration
+	onError   func(err error)
+	filter    func(series *alloha.SeriesData) bool
+	now       func() time.Time
+
+	mu       sync.Mutex
+	loaded   bool
+	baseline bool
+	entries  map[Key]*Entry
+}
+
+// Option configures the Watcher
+type Option func(w *Watcher)
+
+// episodeKey identifies an episode regardless of the translation
+type episodeKey struct {
+	idKp    int
+	season  int
+	episode int
+}
+
+//region - Constructor
+
+// NewWatcher creates a new Watcher instance
+func NewWatcher(api alloha.API, state StateStore, options ...Option) *Watcher {
+	w := &Watcher{
+		api:       api,
+		state:     state,
+		interval:  DefaultInterval,
+		pages:     DefaultPages,
+		retention: DefaultRetention,
+		now:       time.Now,
+	}
+
+	for _, option := range options {
+		option(w)
+	}
+
+	return w
+}
+
+// WithInterval sets the polling interval, DefaultInterval by default
+func WithInterval(interval time.Duration) Option {
+	return func(w *Watcher) {
+		if interval > 0 {
+			w.interval = interval
+		}
+	}
+}
+
+// WithPages sets the number of the latest series pages fetched by every poll, DefaultPages by default
+func WithPages(pages int) Option {
+	return func(w *Watcher) {
+		if pages > 0 {
+			w.pages = pages
+		}
+	}
+}
+
+// WithRetention sets how long the keys that are not on the polled pages anymore are kept in the state,
+// DefaultRetention by default
+func WithRetention(retention time.Duration) Option {
+	return func(w *Watcher) {
+		if retention > 0 {
+			w.retention = retention
+		}
+	}
+}
+
+// WithErrorHandler sets the function receiving the poll and handler errors of Run, which are ignored by default
+func WithErrorHandler(fn func(err error)) Option {
+	return func(w *Watcher) {
+		w.onError = fn
+	}
+}
+
+// WithFilter sets the function selecting the polled episodes, the rejected episodes are neither emitted nor recorded,
+// e.g. the policy.Policy.AllowSeries method
+func WithFilter(filter func(series *alloha.SeriesData) bool) Option {
+	return func(w *Watcher) {
+		w.filter = filter
+	}
+}
+
+//endregion
+
+//region - Public Methods
+
+// Run polls the latest series list on the interval until the context is canceled. The errors do not stop the
+// Watcher, they are passed to the error handler.
+func (w *Watcher) Run(ctx context.Context, handler Handler) error {
+	if handler == nil {
+		return alloha.NilCallbackParameterError
+	}
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := w.Poll(ctx, handler); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if w.onError != nil {
+				w.onError(err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll fetches the latest series list once and passes the events to the handler. The state is saved after the
+// events are handled.
+func (w *Watcher) Poll(ctx context.Context, handler Handler) error {
+	if handler == nil {
+		return alloha.NilCallbackParameterError
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := w.load(ctx); err != nil {
+		return err
+	}
+
+	series, err := w.fetch(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := w.now()
+	episodes := make(map[episodeKey]bool, len(w.entries))
+	for key := range w.entries {
+		episodes[episodeKey{idKp: key.IDKp, season: key.Season, episode: key.Episode}] = true
+	}
+
+	for _, s := range series {
+		if w.filter != nil && !w.filter(s) {
+			continue
+		}
+
+		key := Key{IDKp: s.IDKp, Season: s.Season, Episode: s.Episode, Translation: s.Translation}
+		episode := episodeKey{idKp: s.IDKp, season: s.Season, episode: s.Episode}
+		entry, ok := w.entries[key]
+
+		var event *Event
+		switch {
+		case !ok && episodes[episode]:
+			event = &Event{Kind: EventNewTranslation, Series: s, DetectedAt: now}
+		case !ok:
+			event = &Event{Kind: EventNewEpisode, Series: s, DetectedAt: now}
+		case alloha.IsQualityUpgrade(entry.Quality, s.Quality):
+			event = &Event{Kind: EventQualityUpgrade, Series: s, PreviousQuality: entry.Quality, DetectedAt: now}
+		}
+
+		if event != nil && !w.baseline {
+			if err = handler(ctx, event); err != nil {
+				_ = w.save(ctx, now)
+				return err
+			}
+		}
+
+		if !ok {
+			entry = &Entry{Key: key}
+			w.entries[key] = entry
+		}
+		if !ok || alloha.QualityRank(s.Quality) >= alloha.QualityRank(entry.Quality) {
+			entry.Quality = s.Quality
+		}
+		entry.SeenAt = now
+		episodes[episode] = true
+	}
+
+	if err = w.save(ctx, now); err != nil {
+		return err
+	}
+	w.baseline = false
+
+	return nil
+}
+
+// ChannelHandler returns a Handler sending the events to the channel
+func ChannelHandler(ch chan<- *Event) Handler {
+	return func(ctx context.Context, event *Event) error {
+		select {
+		case ch <- event:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+//endregion
+
+//region - Private Methods
+
+// load loads the saved state on the first poll
+func (w *Watcher) load(ctx context.Context) error {
+	if w.loaded {
+		return nil
+	}
+
+	state, err := w.state.Load(ctx)
+	if err != nil {
+		return err
+	}
+
+	w.entries = make(map[Key]*Entry)
+	w.baseline = state == nil
+	if state != nil {
+		for i := range state.Entries {
+			entry := state.Entries[i]
+			w.entries[entry.Key] = &entry
+		}
+	}
+	w.loaded = true
+
+	return nil
+}
+
+// fetch returns the episodes from the polled pages of the latest series list
+func (w *Watcher) fetch(ctx context.Context) ([]*alloha.SeriesData, error) {
+	var series []*alloha.SeriesData
+
+	for page := 1; page <= w.pages; page++ {
+		response, err := w.api.GetListOfLatestSeries(ctx, page)
+		if err != nil {
+			return nil, err
+		}
+		if err = response.Err(); err != nil {
+			return nil, err
+		}
+
+		series = append(series, response.Data...)
+		if !response.NextPage.Valid {
+			break
+		}
+	}
+
+	return series, nil
+}
+
+// save removes the expired entries and saves the state
+func (w *Watcher) save(ctx context.Context, now time.Time) error {
+	state := &State{Entries: make([]Entry, 0, len(w.entries))}
+	for key, entry := range w.entries {
+		if now.Sub(entry.SeenAt) > w.retention {
+			delete(w.entries, key)
+			continue
+		}
+		state.Entries = append(state.Entries, *entry)
+	}
+	sort.Slice(state.Entries, func(i, j int) bool {
+		a, b := state.Entries[i].Key, state.Entries[j].Key
+		if a.IDKp != b.IDKp {
+			return a.IDKp < b.IDKp
+		}
+		if a.Season != b.Season {
+			return a.Season < b.Season
+		}
+		if a.Episode != b.Episode {
+			return a.Episode < b.Episode
+		}
+		return a.Translation < b.Translation
+	})
+
+	return w.state.Save(ctx, state)
+}
+
+//endregion
@@ -0,0 +1,190 @@
+package watch
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/electromystyle/alloha-sdk-go/alloha"
+	"github.com/electromystyle/alloha-sdk-go/allohamock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// latestMock creates a mock API serving the latest series page returned by the function
+func latestMock(page func() []*alloha.SeriesData) *allohamock.Mock {
+	return &allohamock.Mock{
+		GetListOfLatestSeriesFunc: func(ctx context.Context, pageNum int) (*alloha.ListOfLatestSeriesResponse, error) {
+			return &alloha.ListOfLatestSeriesResponse{Status: "success", Data: page()}, nil
+		},
+	}
+}
+
+// collect returns a Handler appending the events to the slice
+func collect(events *[]*Event) Handler {
+	return func(ctx context.Context, event *Event) error {
+		*events = append(*events, event)
+		return nil
+	}
+}
+
+// eventKinds returns the kinds and the series names of the events
+func eventKinds(events []*Event) []string {
+	kinds := make([]string, 0, len(events))
+	for _, event := range events {
+		kinds = append(kinds, string(event.Kind)+":"+event.Series.Name)
+	}
+
+	return kinds
+}
+
+func TestWatcher_Poll(t *testing.T) {
+	page := []*alloha.SeriesData{
+		{IDKp: 1, Name: "Бригада", Season: 1, Episode: 1, Translation: 10, Quality: "HDTV"},
+	}
+	w := NewWatcher(latestMock(func() []*alloha.SeriesData { return page }), NewMemoryState())
+
+	// Первый опрос запоминает текущие серии без событий
+	var events []*Event
+	require.NoError(t, w.Poll(t.Context(), collect(&events)))
+	assert.Empty(t, events)
+
+	// Добавляем новую серию, новый перевод, повторы и улучшение качества
+	page = []*alloha.SeriesData{
+		{IDKp: 1, Name: "Бригада", Season: 1, Episode: 2, Translation: 10, Quality: "HDTV"},
+		{IDKp: 1, Name: "Бригада", Season: 1, Episode: 2, Translation: 10, Quality: "HDTV"},
+		{IDKp: 1, Name: "Бригада", Season: 1, Episode: 1, Translation: 20, Quality: "WEBRip"},
+		{IDKp: 1, Name: "Бригада", Season: 1, Episode: 1, Translation: 10, Quality: "WEB-DL"},
+	}
+	require.NoError(t, w.Poll(t.Context(), collect(&events)))
+
+	// Проверяем результат
+	assert.Equal(t, []string{
+		"new_episode:Бригада",
+		"new_translation:Бригада",
+		"quality_upgrade:Бригада",
+	}, eventKinds(events))
+	assert.Equal(t, "HDTV", events[2].PreviousQuality)
+
+	// Повторный опрос не создает событий
+	events = nil
+	require.NoError(t, w.Poll(t.Context(), collect(&events)))
+	assert.Empty(t, events)
+}
+
+func TestWatcher_Filter(t *testing.T) {
+	page := []*alloha.SeriesData{}
+	w := NewWatcher(latestMock(func() []*alloha.SeriesData { return page }), NewMemoryState(),
+		WithFilter(func(series *alloha.SeriesData) bool { return !series.Adv }))
+
+	var events []*Event
+	require.NoError(t, w.Poll(t.Context(), collect(&events)))
+
+	// Серия с рекламой пропускается, перевод без рекламы считается новой серией
+	page = []*alloha.SeriesData{
+		{IDKp: 1, Name: "Бригада", Season: 1, Episode: 1, Translation: 10, Adv: true},
+		{IDKp: 2, Name: "Пульс", Season: 1, Episode: 1, Translation: 10},
+	}
+	require.NoError(t, w.Poll(t.Context(), collect(&events)))
+	page = append(page, &alloha.SeriesData{IDKp: 1, Name: "Бригада", Season: 1, Episode: 1, Translation: 20})
+	require.NoError(t, w.Poll(t.Context(), collect(&events)))
+
+	// Проверяем результат
+	assert.Equal(t, []string{"new_episode:Пульс", "new_episode:Бригада"}, eventKinds(events))
+}
+
+func TestWatcher_Restart(t *testing.T) {
+	state := NewFileState(filepath.Join(t.TempDir(), "watch.json"))
+	page := []*alloha.SeriesData{{IDKp: 1, Name: "Бригада", Season: 1, Episode: 1, Translation: 10}}
+	api := latestMock(func() []*alloha.SeriesData { return page })
+
+	require.NoError(t, NewWatcher(api, state).Poll(t.Context(), collect(new([]*Event))))
+
+	// Обработчик не смог обработать событие новой серии
+	page = append([]*alloha.SeriesData{{IDKp: 2, Name: "Бандитский Петербург", Season: 1, Episode: 1}}, page...)
+	failure := errors.New("handler failure")
+	err := NewWatcher(api, state).Poll(t.Context(), func(ctx context.Context, event *Event) error {
+		return failure
+	})
+	assert.ErrorIs(t, err, failure)
+
+	// Перезапущенный наблюдатель повторяет только необработанное событие
+	var events []*Event
+	require.NoError(t, NewWatcher(api, state).Poll(t.Context(), collect(&events)))
+	assert.Equal(t, []string{"new_episode:Бандитский Петербург"}, eventKinds(events))
+
+	events = nil
+	require.NoError(t, NewWatcher(api, state).Poll(t.Context(), collect(&events)))
+	assert.Empty(t, events)
+}
+
+func TestWatcher_Retention(t *testing.T) {
+	state := NewMemoryState()
+	page := []*alloha.SeriesData{{IDKp: 1, Season: 1, Episode: 1}}
+	w := NewWatcher(latestMock(func() []*alloha.SeriesData { return page }), state, WithRetention(time.Hour))
+	clock := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	w.now = func() time.Time { return clock }
+
+	require.NoError(t, w.Poll(t.Context(), collect(new([]*Event))))
+
+	// Серия пропадает со страницы и удаляется из состояния по истечении срока хранения
+	page = []*alloha.SeriesData{{IDKp: 2, Season: 1, Episode: 1}}
+	clock = clock.Add(2 * time.Hour)
+	require.NoError(t, w.Poll(t.Context(), collect(new([]*Event))))
+
+	saved, err := state.Load(t.Context())
+	require.NoError(t, err)
+	require.Len(t, saved.Entries, 1)
+	assert.Equal(t, 2, saved.Entries[0].IDKp)
+}
+
+func TestWatcher_Run(t *testing.T) {
+	episode := 1
+	api := latestMock(func() []*alloha.SeriesData {
+		episode++
+		return []*alloha.SeriesData{{IDKp: 1, Season: 1, Episode: episode}}
+	})
+	w := NewWatcher(api, NewMemoryState(), WithInterval(time.Millisecond))
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	ch := make(chan *Event)
+	done := make(chan error, 1)
+	go func() {
+		done <- w.Run(ctx, ChannelHandler(ch))
+	}()
+
+	// Проверяем результат
+	event := <-ch
+	assert.Equal(t, EventNewEpisode, event.Kind)
+	assert.Equal(t, 3, event.Series.Episode)
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
+
+func TestWatcher_Run_Error(t *testing.T) {
+	api := &allohamock.Mock{
+		GetListOfLatestSeriesFunc: func(ctx context.Context, pageNum int) (*alloha.ListOfLatestSeriesResponse, error) {
+			return &alloha.ListOfLatestSeriesResponse{Status: "error", ErrorInfo: "not valid token"}, nil
+		},
+	}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	errs := make(chan error, 1)
+	w := NewWatcher(api, NewMemoryState(), WithInterval(time.Hour), WithErrorHandler(func(err error) {
+		errs <- err
+		cancel()
+	}))
+
+	assert.ErrorIs(t, w.Run(ctx, collect(new([]*Event))), context.Canceled)
+
+	var apiError *alloha.APIError
+	assert.ErrorAs(t, <-errs, &apiError)
+	assert.ErrorIs(t, w.Run(t.Context(), nil), alloha.NilCallbackParameterError)
+}
@@ -0,0 +1,474 @@
+package webhook
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/electromystyle/alloha-sdk-go/alloha"
+	allohasync "github.com/electromystyle/alloha-sdk-go/sync"
+	"github.com/electromystyle/alloha-sdk-go/watch"
+)
+
+// Default Dispatcher settings
+const (
+	DefaultMaxAttempts    = 5
+	DefaultInitialBackoff = 500 * time.Millisecond
+	DefaultMaxBackoff     = 30 * time.Second
+	DefaultMaxBatchSize   = 100
+	DefaultMaxDeliveries  = 4
+)
+
+// DeliveryError is the error of a failed delivery after the last attempt
+type DeliveryError struct {
+	URL string
+	// Number of the delivery attempts
+	Attempts int
+	// Status code of the last response, zero if there has been no response
+	StatusCode int
+	Err        error
+}
+
+// DeadLetter is a failed delivery written to the dead-letter file
+type DeadLetter struct {
+	URL      string    `json:"url"`
+	Delivery string    `json:"delivery"`
+	Attempts int       `json:"attempts"`
+	Error    string    `json:"error"`
+	FailedAt time.Time `json:"failed_at"`
+	Events   []*Event  `json:"events"`
+}
+
+// Dispatcher delivers the events to the endpoints. The events of an endpoint are collected during the batching window
+// and delivered in a single request in the background. The batches are delivered by a bounded number of workers,
+// the other batches wait in the backlog.
+type Dispatcher struct {
+	client         alloha.HttpClient
+	maxAttempts    int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	batchWindow    time.Duration
+	maxBatchSize   int
+	maxDeliveries  int
+	deadLetterPath string
+	onError        func(err error)
+	now            func() time.Time
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu      sync.Mutex
+	queues  []*queue
+	backlog []*batch
+	workers int
+	closed  bool
+
+	deadLetterMu sync.Mutex
+}
+
+// Option configures the Dispatcher
+type Option func(d *Dispatcher)
+
+// queue is the pending batch of an endpoint
+type queue struct {
+	endpoint Endpoint
+	pending  []*Event
+	timer    *time.Timer
+}
+
+// batch is the batch of events waiting for the delivery to the endpoint
+type batch struct {
+	endpoint Endpoint
+	events   []*Event
+}
+
+//region - Constructor
+
+// NewDispatcher creates a new Dispatcher instance
+func NewDispatcher(endpoints []Endpoint, options ...Option) (*Dispatcher, error) {
+	d := &Dispatcher{
+		client:         http.DefaultClient,
+		maxAttempts:    DefaultMaxAttempts,
+		initialBackoff: DefaultInitialBackoff,
+		maxBackoff:     DefaultMaxBackoff,
+		maxBatchSize:   DefaultMaxBatchSize,
+		maxDeliveries:  DefaultMaxDeliveries,
+		now:            time.Now,
+	}
+
+	for _, endpoint := range endpoints {
+		if len(endpoint.URL) <= 0 {
+			return nil, EmptyEndpointURLError
+		}
+		d.queues = append(d.queues, &queue{endpoint: endpoint})
+	}
+
+	for _, option := range options {
+		option(d)
+	}
+	d.ctx, d.cancel = context.WithCancel(context.Background())
+
+	return d, nil
+}
+
+// WithHTTPClient sets the HTTP client of the deliveries, http.DefaultClient by default
+func WithHTTPClient(client alloha.HttpClient) Option {
+	return func(d *Dispatcher) {
+		if client != nil {
+			d.client = client
+		}
+	}
+}
+
+// WithRetries sets the maximum number of the delivery attempts and the backoff between them. The backoff doubles
+// after every attempt up to the maximum one.
+func WithRetries(maxAttempts int, initialBackoff, maxBackoff time.Duration) Option {
+	return func(d *Dispatcher) {
+		if maxAttempts > 0 {
+			d.maxAttempts = maxAttempts
+		}
+		d.initialBackoff = initialBackoff
+		d.maxBackoff = maxBackoff
+	}
+}
+
+// WithBatching sets the batching window and the maximum batch size. The events are delivered immediately, if the
+// window is zero.
+func WithBatching(window time.Duration, maxBatchSize int) Option {
+	return func(d *Dispatcher) {
+		d.batchWindow = window
+		if maxBatchSize > 0 {
+			d.maxBatchSize = maxBatchSize
+		}
+	}
+}
+
+// WithMaxDeliveries sets the maximum number of the concurrent deliveries, DefaultMaxDeliveries by default
+func WithMaxDeliveries(n int) Option {
+	return func(d *Dispatcher) {
+		if n > 0 {
+			d.maxDeliveries = n
+		}
+	}
+}
+
+// WithDeadLetterFile sets the JSON Lines file receiving the failed deliveries
+func WithDeadLetterFile(path string) Option {
+	return func(d *Dispatcher) {
+		d.deadLetterPath = path
+	}
+}
+
+// WithErrorHandler sets the function receiving the *DeliveryError errors and the dead-letter file errors
+func WithErrorHandler(fn func(err error)) Option {
+	return func(d *Dispatcher) {
+		d.onError = fn
+	}
+}
+
+//endregion
+
+//region - Public Methods
+
+// Error implements the error interface
+func (e *DeliveryError) Error() string {
+	return fmt.Sprintf("webhook delivery to %s failed after %d attempts: %v", e.URL, e.Attempts, e.Err)
+}
+
+// Unwrap returns the error of the last attempt
+func (e *DeliveryError) Unwrap() error {
+	return e.Err
+}
+
+// Send queues the events for the delivery to the matching endpoints
+func (d *Dispatcher) Send(ctx context.Context, events ...*Event) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.closed {
+		return DispatcherClosedError
+	}
+
+	for _, q := range d.queues {
+		for _, event := range events {
+			if !q.endpoint.Filter.Match(event) {
+				continue
+			}
+
+			q.pending = append(q.pending, event)
+			if d.batchWindow <= 0 || len(q.pending) >= d.maxBatchSize {
+				d.flush(q)
+				continue
+			}
+			if q.timer == nil {
+				q.timer = time.AfterFunc(d.batchWindow, func() {
+					d.mu.Lock()
+					defer d.mu.Unlock()
+					d.flush(q)
+				})
+			}
+		}
+	}
+
+	return nil
+}
+
+// WatchHandler returns a watch.Handler sending the watcher events
+func (d *Dispatcher) WatchHandler() watch.Handler {
+	return func(ctx context.Context, event *watch.Event) error {
+		return d.Send(ctx, FromWatchEvent(event))
+	}
+}
+
+// SyncListener returns a sync.Listener sending the title changes
+func (d *Dispatcher) SyncListener() allohasync.Listener {
+	return func(ctx context.Context, change *allohasync.TitleChange) {
+		if err := d.Send(ctx, FromTitleChange(change, d.now())); err != nil && d.onError != nil {
+			d.onError(err)
+		}
+	}
+}
+
+// Close delivers the pending events and waits for the deliveries. The deliveries that have not been completed when
+// the context is done are aborted and written to the dead-letter file.
+func (d *Dispatcher) Close(ctx context.Context) error {
+	d.mu.Lock()
+	if !d.closed {
+		d.closed = true
+		for _, q := range d.queues {
+			d.flush(q)
+		}
+	}
+	d.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		d.cancel()
+		return nil
+	case <-ctx.Done():
+		d.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// ReadDeadLetters reads the failed deliveries from the dead-letter file
+func ReadDeadLetters(path string) ([]*DeadLetter, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var letters []*DeadLetter
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64<<10), 64<<20)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) <= 0 {
+			continue
+		}
+
+		letter := &DeadLetter{}
+		if err = json.Unmarshal(scanner.Bytes(), letter); err != nil {
+			return nil, fmt.Errorf("invalid dead letter on line %d of %s: %w", line, path, err)
+		}
+		letters = append(letters, letter)
+	}
+
+	return letters, scanner.Err()
+}
+
+//endregion
+
+//region - Private Methods
+
+// flush adds the pending events of the endpoint to the backlog and starts a worker, if there are less workers than
+// the maximum number of the concurrent deliveries. The caller must hold the lock.
+func (d *Dispatcher) flush(q *queue) {
+	if q.timer != nil {
+		q.timer.Stop()
+		q.timer = nil
+	}
+	if len(q.pending) <= 0 {
+		return
+	}
+
+	d.backlog = append(d.backlog, &batch{endpoint: q.endpoint, events: q.pending})
+	q.pending = nil
+
+	if d.workers < d.maxDeliveries {
+		d.workers++
+		d.wg.Add(1)
+		go d.work()
+	}
+}
+
+// work delivers the batches of the backlog until it is empty
+func (d *Dispatcher) work() {
+	defer d.wg.Done()
+
+	for {
+		d.mu.Lock()
+		if len(d.backlog) <= 0 {
+			d.workers--
+			d.mu.Unlock()
+			return
+		}
+		next := d.backlog[0]
+		d.backlog[0] = nil
+		d.backlog = d.backlog[1:]
+		d.mu.Unlock()
+
+		d.deliver(next.endpoint, next.events)
+	}
+}
+
+// deliver delivers the batch to the endpoint with retries and writes it to the dead-letter file on failure
+func (d *Dispatcher) deliver(endpoint Endpoint, batch []*Event) {
+	body, err := json.Marshal(&Payload{Events: batch})
+	if err != nil {
+		d.fail(endpoint, "", batch, &DeliveryError{URL: endpoint.URL, Err: err})
+		return
+	}
+
+	delivery := newDeliveryID()
+	backoff := d.initialBackoff
+	deliveryErr := &DeliveryError{URL: endpoint.URL}
+
+	for attempt := 1; attempt <= d.maxAttempts; attempt++ {
+		deliveryErr.Attempts = attempt
+
+		var retry bool
+		deliveryErr.StatusCode, retry, deliveryErr.Err = d.post(endpoint, delivery, body)
+		if deliveryErr.Err == nil {
+			return
+		}
+		if !retry || attempt >= d.maxAttempts {
+			break
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-d.ctx.Done():
+			timer.Stop()
+			deliveryErr.Err = d.ctx.Err()
+			d.fail(endpoint, delivery, batch, deliveryErr)
+			return
+		case <-timer.C:
+		}
+
+		backoff *= 2
+		if backoff > d.maxBackoff {
+			backoff = d.maxBackoff
+		}
+	}
+
+	d.fail(endpoint, delivery, batch, deliveryErr)
+}
+
+// post sends a single delivery attempt and reports whether the failed attempt can be retried
+func (d *Dispatcher) post(endpoint Endpoint, delivery string, body []byte) (int, bool, error) {
+	req, err := http.NewRequestWithContext(d.ctx, http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, false, err
+	}
+
+	timestamp := d.now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderDelivery, delivery)
+	if len(endpoint.Secret) > 0 {
+		req.Header.Set(HeaderSignature, Sign(endpoint.Secret, timestamp, body))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, d.ctx.Err() == nil, err
+	}
+	// The body is drained to reuse the keep-alive connection
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return resp.StatusCode, false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return resp.StatusCode, true, &alloha.UnexpectedStatusCodeError{StatusCode: resp.StatusCode}
+	default:
+		return resp.StatusCode, false, &alloha.UnexpectedStatusCodeError{StatusCode: resp.StatusCode}
+	}
+}
+
+// fail writes the failed delivery to the dead-letter file and passes the error to the error handler
+func (d *Dispatcher) fail(endpoint Endpoint, delivery string, batch []*Event, deliveryErr *DeliveryError) {
+	if d.onError != nil {
+		d.onError(deliveryErr)
+	}
+	if len(d.deadLetterPath) <= 0 {
+		return
+	}
+
+	line, err := json.Marshal(&DeadLetter{
+		URL:      endpoint.URL,
+		Delivery: delivery,
+		Attempts: deliveryErr.Attempts,
+		Error:    deliveryErr.Err.Error(),
+		FailedAt: d.now(),
+		Events:   batch,
+	})
+	if err == nil {
+		err = d.appendDeadLetter(append(line, '\n'))
+	}
+	if err != nil && d.onError != nil {
+		d.onError(err)
+	}
+}
+
+// appendDeadLetter appends the line to the dead-letter file
+func (d *Dispatcher) appendDeadLetter(line []byte) error {
+	d.deadLetterMu.Lock()
+	defer d.deadLetterMu.Unlock()
+
+	file, err := os.OpenFile(d.deadLetterPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+
+	if _, err = file.Write(line); err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// newDeliveryID returns a random delivery ID
+func newDeliveryID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
+
+//endregion
@@ -0,0 +1,278 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/electromystyle/alloha-sdk-go/alloha"
+	allohasync "github.com/electromystyle/alloha-sdk-go/sync"
+	"github.com/electromystyle/alloha-sdk-go/watch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// receiver is a local webhook receiver
+type receiver struct {
+	*httptest.Server
+
+	mu         sync.Mutex
+	secret     string
+	statuses   []int
+	requests   int
+	payloads   []*Payload
+	deliveries []string
+	invalid    int
+}
+
+// newReceiver starts a receiver responding with the statuses in order and with 204 after them
+func newReceiver(t *testing.T, secret string, statuses ...int) *receiver {
+	r := &receiver{secret: secret, statuses: statuses}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		r.requests++
+		r.deliveries = append(r.deliveries, req.Header.Get(HeaderDelivery))
+		if len(r.statuses) > 0 {
+			status := r.statuses[0]
+			r.statuses = r.statuses[1:]
+			w.WriteHeader(status)
+			return
+		}
+		if Verify(r.secret, req.Header, body) != nil {
+			r.invalid++
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		payload := &Payload{}
+		_ = json.Unmarshal(body, payload)
+		r.payloads = append(r.payloads, payload)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(r.Close)
+
+	return r
+}
+
+// eventIDs returns the IDs of the received events by payload
+func (r *receiver) eventIDs() [][]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ids := make([][]string, 0, len(r.payloads))
+	for _, payload := range r.payloads {
+		var batch []string
+		for _, event := range payload.Events {
+			batch = append(batch, event.ID)
+		}
+		ids = append(ids, batch)
+	}
+
+	return ids
+}
+
+func TestDispatcher_Send(t *testing.T) {
+	all := newReceiver(t, "secret")
+	filtered := newReceiver(t, "other")
+
+	d, err := NewDispatcher([]Endpoint{
+		{URL: all.URL, Secret: "secret"},
+		{URL: filtered.URL, Secret: "other", Filter: Filter{IDKp: []int{2}}},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, d.Send(t.Context(), &Event{ID: "1", IDKp: 1}, &Event{ID: "2", IDKp: 2}))
+	require.NoError(t, d.Close(t.Context()))
+
+	// Проверяем результат
+	assert.ElementsMatch(t, [][]string{{"1"}, {"2"}}, all.eventIDs())
+	assert.Equal(t, [][]string{{"2"}}, filtered.eventIDs())
+	assert.Zero(t, all.invalid)
+	assert.ErrorIs(t, d.Send(t.Context(), &Event{ID: "3"}), DispatcherClosedError)
+}
+
+func TestDispatcher_Batching(t *testing.T) {
+	r := newReceiver(t, "secret")
+
+	d, err := NewDispatcher([]Endpoint{{URL: r.URL, Secret: "secret"}}, WithBatching(time.Hour, 3))
+	require.NoError(t, err)
+
+	// Пакет отправляется при достижении максимального размера и при закрытии
+	for _, id := range []string{"1", "2", "3", "4"} {
+		require.NoError(t, d.Send(t.Context(), &Event{ID: id}))
+	}
+	require.NoError(t, d.Close(t.Context()))
+
+	// Проверяем результат
+	assert.ElementsMatch(t, [][]string{{"1", "2", "3"}, {"4"}}, r.eventIDs())
+}
+
+func TestDispatcher_BatchWindow(t *testing.T) {
+	r := newReceiver(t, "secret")
+
+	d, err := NewDispatcher([]Endpoint{{URL: r.URL, Secret: "secret"}}, WithBatching(10*time.Millisecond, 0))
+	require.NoError(t, err)
+	defer d.Close(t.Context())
+
+	require.NoError(t, d.Send(t.Context(), &Event{ID: "1"}))
+	require.NoError(t, d.Send(t.Context(), &Event{ID: "2"}))
+
+	// Проверяем результат
+	assert.Eventually(t, func() bool {
+		return len(r.eventIDs()) == 1
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, [][]string{{"1", "2"}}, r.eventIDs())
+}
+
+func TestDispatcher_MaxDeliveries(t *testing.T) {
+	var mu sync.Mutex
+	var active, maxActive, requests int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		active++
+		requests++
+		if active > maxActive {
+			maxActive = active
+		}
+		mu.Unlock()
+
+		time.Sleep(5 * time.Millisecond)
+
+		mu.Lock()
+		active--
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	d, err := NewDispatcher([]Endpoint{{URL: ts.URL}}, WithMaxDeliveries(2))
+	require.NoError(t, err)
+
+	// Каждое событие отправляется отдельным запросом без пакетирования
+	for i := 0; i < 10; i++ {
+		require.NoError(t, d.Send(t.Context(), &Event{ID: strconv.Itoa(i)}))
+	}
+	require.NoError(t, d.Close(t.Context()))
+
+	// Проверяем, что все события доставлены не более чем двумя запросами одновременно
+	assert.Equal(t, 10, requests)
+	assert.LessOrEqual(t, maxActive, 2)
+}
+
+func TestDispatcher_Retries(t *testing.T) {
+	r := newReceiver(t, "secret", http.StatusBadGateway, http.StatusTooManyRequests)
+
+	d, err := NewDispatcher([]Endpoint{{URL: r.URL, Secret: "secret"}}, WithRetries(3, time.Millisecond, time.Millisecond))
+	require.NoError(t, err)
+
+	require.NoError(t, d.Send(t.Context(), &Event{ID: "1"}))
+	require.NoError(t, d.Close(t.Context()))
+
+	// Проверяем результат
+	assert.Equal(t, 3, r.requests)
+	assert.Equal(t, [][]string{{"1"}}, r.eventIDs())
+	assert.Equal(t, r.deliveries[0], r.deliveries[2])
+}
+
+func TestDispatcher_DeadLetter(t *testing.T) {
+	failing := newReceiver(t, "secret", 500, 500, 500)
+	rejecting := newReceiver(t, "secret", http.StatusBadRequest)
+	path := filepath.Join(t.TempDir(), "dead.jsonl")
+
+	var mu sync.Mutex
+	var errs []error
+	d, err := NewDispatcher([]Endpoint{{URL: failing.URL, Secret: "secret"}, {URL: rejecting.URL, Secret: "secret"}},
+		WithRetries(3, time.Millisecond, time.Millisecond),
+		WithDeadLetterFile(path),
+		WithErrorHandler(func(err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			errs = append(errs, err)
+		}),
+	)
+	require.NoError(t, err)
+
+	require.NoError(t, d.Send(t.Context(), &Event{ID: "1"}))
+	require.NoError(t, d.Close(t.Context()))
+
+	// Проверяем результат
+	assert.Equal(t, 3, failing.requests)
+	assert.Equal(t, 1, rejecting.requests)
+
+	letters, err := ReadDeadLetters(path)
+	require.NoError(t, err)
+	require.Len(t, letters, 2)
+
+	attempts := map[string]int{}
+	for _, letter := range letters {
+		attempts[letter.URL] = letter.Attempts
+		assert.Equal(t, "1", letter.Events[0].ID)
+	}
+	assert.Equal(t, map[string]int{failing.URL: 3, rejecting.URL: 1}, attempts)
+
+	require.Len(t, errs, 2)
+	var deliveryErr *DeliveryError
+	require.ErrorAs(t, errs[0], &deliveryErr)
+	var statusErr *alloha.UnexpectedStatusCodeError
+	assert.ErrorAs(t, errs[0], &statusErr)
+}
+
+func TestDispatcher_CloseTimeout(t *testing.T) {
+	r := newReceiver(t, "secret", 500)
+	path := filepath.Join(t.TempDir(), "dead.jsonl")
+
+	d, err := NewDispatcher([]Endpoint{{URL: r.URL, Secret: "secret"}},
+		WithRetries(2, time.Hour, time.Hour), WithDeadLetterFile(path))
+	require.NoError(t, err)
+
+	require.NoError(t, d.Send(t.Context(), &Event{ID: "1"}))
+
+	// Ожидание повторной попытки прерывается при закрытии
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, d.Close(ctx), context.DeadlineExceeded)
+
+	letters, err := ReadDeadLetters(path)
+	require.NoError(t, err)
+	require.Len(t, letters, 1)
+	assert.Equal(t, context.Canceled.Error(), letters[0].Error)
+}
+
+func TestDispatcher_Handlers(t *testing.T) {
+	r := newReceiver(t, "secret")
+
+	d, err := NewDispatcher([]Endpoint{{URL: r.URL, Secret: "secret"}}, WithBatching(time.Hour, 0))
+	require.NoError(t, err)
+
+	err = d.WatchHandler()(t.Context(), &watch.Event{
+		Kind:   watch.EventNewEpisode,
+		Series: &alloha.SeriesData{IDKp: 77044, Season: 1, Episode: 1, Translation: 10},
+	})
+	require.NoError(t, err)
+	d.SyncListener()(t.Context(), &allohasync.TitleChange{Kind: allohasync.ChangeAdded, IDKp: 77044})
+	require.NoError(t, d.Close(t.Context()))
+
+	// Проверяем результат
+	require.Len(t, r.payloads, 1)
+	require.Len(t, r.payloads[0].Events, 2)
+	assert.Equal(t, TypeNewEpisode, r.payloads[0].Events[0].Type)
+	assert.Equal(t, TypeTitleAdded, r.payloads[0].Events[1].Type)
+}
+
+func TestNewDispatcher_EmptyURL(t *testing.T) {
+	_, err := NewDispatcher([]Endpoint{{Secret: "secret"}})
+
+	// Проверяем результат
+	assert.ErrorIs(t, err, EmptyEndpointURLError)
+}
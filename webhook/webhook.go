@@ -0,0 +1,213 @@
+// Package webhook pushes the watcher and the sync events to HTTP endpoints. The payloads are signed with HMAC-SHA256,
+// failed deliveries are retried with an exponential backoff and written to a dead-letter file after the last attempt.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/electromystyle/alloha-sdk-go/alloha"
+	allohasync "github.com/electromystyle/alloha-sdk-go/sync"
+	"github.com/electromystyle/alloha-sdk-go/watch"
+)
+
+// Event types
+const (
+	TypeNewEpisode     = string(watch.EventNewEpisode)
+	TypeNewTranslation = string(watch.EventNewTranslation)
+	TypeQualityUpgrade = string(watch.EventQualityUpgrade)
+	TypeTitleAdded     = "title_" + allohasync.ChangeAdded
+	TypeTitleUpdated   = "title_" + allohasync.ChangeUpdated
+	TypeTitleRemoved   = "title_" + allohasync.ChangeRemoved
+)
+
+// Headers of the webhook requests
+const (
+	// HeaderSignature is the "sha256=<hex>" HMAC-SHA256 signature of the timestamp, a dot and the body
+	HeaderSignature = "X-Alloha-Signature"
+	// HeaderTimestamp is the Unix time of the delivery attempt
+	HeaderTimestamp = "X-Alloha-Timestamp"
+	// HeaderDelivery is the unique ID of the delivery, the same for all attempts
+	HeaderDelivery = "X-Alloha-Delivery"
+)
+
+var (
+	EmptyEndpointURLError = errors.New("endpoint url is empty")
+	DispatcherClosedError = errors.New("dispatcher is closed")
+	InvalidSignatureError = errors.New("webhook signature is invalid")
+)
+
+// Event is a webhook event
+type Event struct {
+	// ID of the event, the same event emitted again has the same ID
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	// Kinopoisk ID of the title
+	IDKp int `json:"id_kp"`
+	// Category ID of the title
+	Category int `json:"category"`
+	// Translation ID (only for the episode events)
+	Translation int       `json:"translation,omitempty"`
+	OccurredAt  time.Time `json:"occurred_at"`
+	// Latest episode data (only for the episode events)
+	Series *alloha.SeriesData `json:"series,omitempty"`
+	// Video quality before the upgrade (only for the TypeQualityUpgrade events)
+	PreviousQuality string `json:"previous_quality,omitempty"`
+	// Title data before and after the change (only for the title events)
+	Old *alloha.MovieData `json:"old,omitempty"`
+	New *alloha.MovieData `json:"new,omitempty"`
+	// Changes of the title data (only for the TypeTitleUpdated events)
+	Changes []alloha.Change `json:"changes,omitempty"`
+}
+
+// Payload is the body of the webhook request
+type Payload struct {
+	Events []*Event `json:"events"`
+}
+
+// Filter selects the events delivered to the endpoint, the empty lists match all events. The events without
+// a translation, e.g. the title events, do not match the non-empty Translations list.
+type Filter struct {
+	Types        []string `json:"types"`
+	IDKp         []int    `json:"id_kp"`
+	Categories   []int    `json:"categories"`
+	Translations []int    `json:"translations"`
+}
+
+// Endpoint is a webhook receiver
+type Endpoint struct {
+	URL string `json:"url"`
+	// Secret of the HMAC signature, the requests are not signed if it is empty
+	Secret string `json:"secret"`
+	Filter Filter `json:"filter"`
+}
+
+// FromWatchEvent converts the watcher event to the webhook event
+func FromWatchEvent(event *watch.Event) *Event {
+	s := event.Series
+
+	return &Event{
+		ID: fmt.Sprintf("%s:%d:%d:%d:%d:%s",
+			event.Kind, s.IDKp, s.Season, s.Episode, s.Translation, strings.ToLower(s.Quality)),
+		Type:            string(event.Kind),
+		IDKp:            s.IDKp,
+		Category:        s.CategoryId,
+		Translation:     s.Translation,
+		OccurredAt:      event.DetectedAt,
+		Series:          s,
+		PreviousQuality: event.PreviousQuality,
+	}
+}
+
+// FromTitleChange converts the sync title change to the webhook event. The ID is built from the content of
+// the change, so the same change detected again gets the same ID.
+func FromTitleChange(change *allohasync.TitleChange, occurredAt time.Time) *Event {
+	event := &Event{
+		ID:         fmt.Sprintf("title_%s:%d:%s", change.Kind, change.IDKp, changeHash(change)),
+		Type:       "title_" + change.Kind,
+		IDKp:       change.IDKp,
+		OccurredAt: occurredAt,
+		Old:        change.Old,
+		New:        change.New,
+		Changes:    change.Changes,
+	}
+
+	switch {
+	case change.New != nil:
+		event.Category = change.New.Category
+	case change.Old != nil:
+		event.Category = change.Old.Category
+	}
+
+	return event
+}
+
+// Match reports whether the event passes the filter
+func (f *Filter) Match(event *Event) bool {
+	if len(f.Types) > 0 && !containsString(f.Types, event.Type) {
+		return false
+	}
+	if len(f.IDKp) > 0 && !containsInt(f.IDKp, event.IDKp) {
+		return false
+	}
+	if len(f.Categories) > 0 && !containsInt(f.Categories, event.Category) {
+		return false
+	}
+	if len(f.Translations) > 0 && (event.Translation == 0 || !containsInt(f.Translations, event.Translation)) {
+		return false
+	}
+
+	return true
+}
+
+// Sign returns the signature of the body sent at the Unix time
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature of the received webhook request. The receivers should also reject the requests with
+// an old timestamp to prevent the replay attacks.
+func Verify(secret string, header http.Header, body []byte) error {
+	timestamp, err := strconv.ParseInt(header.Get(HeaderTimestamp), 10, 64)
+	if err != nil {
+		return InvalidSignatureError
+	}
+
+	if !hmac.Equal([]byte(header.Get(HeaderSignature)), []byte(Sign(secret, timestamp, body))) {
+		return InvalidSignatureError
+	}
+
+	return nil
+}
+
+// changeHash returns the short hash of the changes and the title data after the change, or before it for the removed
+// titles
+func changeHash(change *allohasync.TitleChange) string {
+	movie := change.New
+	if movie == nil {
+		movie = change.Old
+	}
+
+	data, _ := json.Marshal(struct {
+		Changes []alloha.Change   `json:"changes"`
+		Movie   *alloha.MovieData `json:"movie"`
+	}{Changes: change.Changes, Movie: movie})
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:8])
+}
+
+// containsString reports whether the list contains the value
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// containsInt reports whether the list contains the value
+func containsInt(list []int, value int) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,98 @@
+package webhook
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/electromystyle/alloha-sdk-go/alloha"
+	allohasync "github.com/electromystyle/alloha-sdk-go/sync"
+	"github.com/electromystyle/alloha-sdk-go/watch"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilter_Match(t *testing.T) {
+	episode := &Event{Type: TypeNewEpisode, IDKp: 77044, Category: 2, Translation: 10}
+	title := &Event{Type: TypeTitleUpdated, IDKp: 77044, Category: 2}
+
+	tests := []struct {
+		name    string
+		filter  Filter
+		episode bool
+		title   bool
+	}{
+		{name: "empty", filter: Filter{}, episode: true, title: true},
+		{name: "types", filter: Filter{Types: []string{TypeTitleUpdated}}, episode: false, title: true},
+		{name: "kp ids", filter: Filter{IDKp: []int{1, 77044}}, episode: true, title: true},
+		{name: "other kp ids", filter: Filter{IDKp: []int{1}}, episode: false, title: false},
+		{name: "categories", filter: Filter{Categories: []int{1}}, episode: false, title: false},
+		{name: "translations", filter: Filter{Translations: []int{10}}, episode: true, title: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Проверяем результат
+			assert.Equal(t, tt.episode, tt.filter.Match(episode))
+			assert.Equal(t, tt.title, tt.filter.Match(title))
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"events":[]}`)
+	header := http.Header{}
+	header.Set(HeaderTimestamp, "1700000000")
+	header.Set(HeaderSignature, Sign("secret", 1700000000, body))
+
+	// Проверяем результат
+	assert.NoError(t, Verify("secret", header, body))
+	assert.ErrorIs(t, Verify("other", header, body), InvalidSignatureError)
+	assert.ErrorIs(t, Verify("secret", header, []byte(`{}`)), InvalidSignatureError)
+
+	header.Set(HeaderTimestamp, "1700000001")
+	assert.ErrorIs(t, Verify("secret", header, body), InvalidSignatureError)
+}
+
+func TestFromWatchEvent(t *testing.T) {
+	detectedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	event := FromWatchEvent(&watch.Event{
+		Kind:            watch.EventQualityUpgrade,
+		Series:          &alloha.SeriesData{IDKp: 77044, Season: 1, Episode: 2, Translation: 10, Quality: "WEB-DL", CategoryId: 2},
+		PreviousQuality: "HDTV",
+		DetectedAt:      detectedAt,
+	})
+
+	// Проверяем результат
+	assert.Equal(t, "quality_upgrade:77044:1:2:10:web-dl", event.ID)
+	assert.Equal(t, TypeQualityUpgrade, event.Type)
+	assert.Equal(t, 2, event.Category)
+	assert.Equal(t, 10, event.Translation)
+	assert.Equal(t, "HDTV", event.PreviousQuality)
+	assert.Equal(t, detectedAt, event.OccurredAt)
+}
+
+func TestFromTitleChange(t *testing.T) {
+	event := FromTitleChange(&allohasync.TitleChange{
+		Kind: allohasync.ChangeRemoved,
+		IDKp: 77044,
+		Old:  &alloha.MovieData{IDKp: 77044, Category: 2},
+	}, time.Unix(1700000000, 0))
+
+	// Проверяем результат
+	assert.Equal(t, TypeTitleRemoved, event.Type)
+	assert.Equal(t, 2, event.Category)
+	assert.Nil(t, event.New)
+	assert.Regexp(t, `^title_removed:77044:[0-9a-f]{16}$`, event.ID)
+
+	updated := &allohasync.TitleChange{
+		Kind:    allohasync.ChangeUpdated,
+		IDKp:    77044,
+		New:     &alloha.MovieData{IDKp: 77044, Name: "Бригада"},
+		Changes: []alloha.Change{{Kind: alloha.ChangeFieldChanged, Path: "name", Old: "", New: "Бригада"}},
+	}
+	first := FromTitleChange(updated, time.Unix(1700000000, 0))
+	assert.Equal(t, first.ID, FromTitleChange(updated, time.Unix(1700000060, 0)).ID)
+
+	updated.New = &alloha.MovieData{IDKp: 77044, Name: "Бригада 2"}
+	assert.NotEqual(t, first.ID, FromTitleChange(updated, time.Unix(1700000000, 0)).ID)
+}